@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	restclient "k8s.io/client-go/rest"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/cli/globalflag"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+	"k8s.io/component-base/term"
+
+	"github.com/clusterpedia-io/clusterpedia/cmd/agent/app/config"
+	"github.com/clusterpedia-io/clusterpedia/cmd/agent/app/options"
+	"github.com/clusterpedia-io/clusterpedia/pkg/agent"
+	clusterpediafeature "github.com/clusterpedia-io/clusterpedia/pkg/utils/feature"
+	"github.com/clusterpedia-io/clusterpedia/pkg/version/verflag"
+)
+
+func init() {
+	runtime.Must(logsapi.AddFeatureGates(clusterpediafeature.MutableFeatureGate))
+}
+
+func NewAgentCommand(ctx context.Context) *cobra.Command {
+	opts := options.NewAgentOptions()
+	cmd := &cobra.Command{
+		Use: "agent",
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			// silence client-go warnings.
+			// the agent generically watches APIs (including deprecated ones) like the clustersynchro-manager.
+			restclient.SetDefaultWarningHandler(restclient.NoWarnings{})
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			verflag.PrintAndExitIfRequested()
+
+			// Activate logging as soon as possible, after that
+			// show flags with the final logging configuration.
+			if err := logsapi.ValidateAndApply(opts.Logs, clusterpediafeature.MutableFeatureGate); err != nil {
+				return err
+			}
+			cliflag.PrintFlags(cmd.Flags())
+
+			config, err := opts.Config()
+			if err != nil {
+				return err
+			}
+
+			return Run(ctx, config)
+		},
+	}
+
+	namedFlagSets := opts.Flags()
+	verflag.AddFlags(namedFlagSets.FlagSet("global"))
+	globalflag.AddGlobalFlags(namedFlagSets.FlagSet("global"), cmd.Name(), logs.SkipLoggingConfigurationFlags())
+	clusterpediafeature.MutableFeatureGate.AddFlag(namedFlagSets.FlagSet("mutable feature gate"))
+
+	fs := cmd.Flags()
+	for _, f := range namedFlagSets.FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
+	cliflag.SetUsageAndHelpFunc(cmd, namedFlagSets, cols)
+	return cmd
+}
+
+func Run(ctx context.Context, c *config.Config) error {
+	agent, err := agent.New(c.ClusterName, c.Kubeconfig, c.Server, c.Token, c.TLSConfig)
+	if err != nil {
+		return err
+	}
+
+	agent.Run(ctx)
+	return nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import (
+	"crypto/tls"
+
+	restclient "k8s.io/client-go/rest"
+)
+
+type Config struct {
+	ClusterName string
+	Kubeconfig  *restclient.Config
+
+	Server    string
+	Token     string
+	TLSConfig *tls.Config
+}
@@ -0,0 +1,128 @@
+package options
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/logs"
+	logsapi "k8s.io/component-base/logs/api/v1"
+
+	"github.com/clusterpedia-io/clusterpedia/cmd/agent/app/config"
+)
+
+const (
+	AgentUserAgent = "clusterpedia-agent"
+)
+
+type Options struct {
+	Logs *logs.Options
+
+	// ClusterName is the name of the PediaCluster synchronized by the agent
+	ClusterName string
+
+	// Server is the address of the ingestion endpoint of clusterpedia
+	Server                string
+	TokenFile             string
+	CertificateAuthority  string
+	InsecureSkipTLSVerify bool
+
+	Master     string
+	Kubeconfig string
+}
+
+func NewAgentOptions() *Options {
+	return &Options{
+		Logs: logs.NewOptions(),
+	}
+}
+
+func (o *Options) Flags() cliflag.NamedFlagSets {
+	var fss cliflag.NamedFlagSets
+
+	agentfs := fss.FlagSet("agent")
+	agentfs.StringVar(&o.ClusterName, "cluster-name", o.ClusterName, "The name of the PediaCluster synchronized by the agent.")
+	agentfs.StringVar(&o.Server, "server", o.Server, "The address of the ingestion endpoint of clusterpedia, such as https://clusterpedia-agent.example.com:8443.")
+	agentfs.StringVar(&o.TokenFile, "token-file", o.TokenFile, "File containing the token with which the agent connects to clusterpedia, it is the token in the secret referenced by the `spec.agentTokenSecretRef` of the PediaCluster.")
+	agentfs.StringVar(&o.CertificateAuthority, "certificate-authority", o.CertificateAuthority, "Path to a cert file for the certificate authority of the ingestion endpoint.")
+	agentfs.BoolVar(&o.InsecureSkipTLSVerify, "insecure-skip-tls-verify", o.InsecureSkipTLSVerify, "If true, the server's certificate will not be checked for validity.")
+
+	fs := fss.FlagSet("misc")
+	fs.StringVar(&o.Master, "master", o.Master, "The address of the Kubernetes API server of the member cluster (overrides any value in kubeconfig).")
+	fs.StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "Path to kubeconfig file of the member cluster, the in-cluster config is used if not specified.")
+
+	logsapi.AddFlags(o.Logs, fss.FlagSet("logs"))
+	return fss
+}
+
+func (o *Options) Validate() error {
+	var errs []error
+	if o.ClusterName == "" {
+		errs = append(errs, errors.New("--cluster-name is required"))
+	}
+	if o.Server == "" {
+		errs = append(errs, errors.New("--server is required"))
+	} else if u, err := url.Parse(o.Server); err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		errs = append(errs, fmt.Errorf("--server must be a http or https URL: %q", o.Server))
+	}
+	if o.TokenFile == "" {
+		errs = append(errs, errors.New("--token-file is required"))
+	}
+	if o.CertificateAuthority != "" && o.InsecureSkipTLSVerify {
+		errs = append(errs, errors.New("--certificate-authority and --insecure-skip-tls-verify cannot be specified together"))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (o *Options) Config() (*config.Config, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	token, err := os.ReadFile(o.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the token file: %w", err)
+	}
+	token = bytes.TrimSpace(token)
+	if len(token) == 0 {
+		return nil, errors.New("the token file is empty")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipTLSVerify, // #nosec G402 -- enabled only by the flag
+	}
+	if o.CertificateAuthority != "" {
+		ca, err := os.ReadFile(o.CertificateAuthority)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the certificate authority: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("no valid certificate is found in the certificate authority")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	kubeconfig, err := clientcmd.BuildConfigFromFlags(o.Master, o.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config.Config{
+		ClusterName: o.ClusterName,
+		Kubeconfig:  restclient.AddUserAgent(kubeconfig, AgentUserAgent),
+
+		Server:    o.Server,
+		Token:     string(token),
+		TLSConfig: tlsConfig,
+	}, nil
+}
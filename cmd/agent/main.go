@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+
+	apiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/component-base/cli"
+	_ "k8s.io/component-base/logs/json/register" // for JSON log format registration
+
+	"github.com/clusterpedia-io/clusterpedia/cmd/agent/app"
+)
+
+func main() {
+	ctx := apiserver.SetupSignalContext()
+	command := app.NewAgentCommand(ctx)
+	code := cli.Run(command)
+	os.Exit(code)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	componentbaseconfig "k8s.io/component-base/config"
@@ -11,12 +12,21 @@ import (
 
 type Config struct {
 	Kubeconfig    *restclient.Config
+	Kubeclient    kubernetes.Interface
 	CRDClient     *crdclientset.Clientset
 	EventRecorder record.EventRecorder
 
 	StorageFactory storage.StorageFactory
 	WorkerNumber   int
 
+	MetricsBindAddress string
+
+	AgentBindAddress string
+	AgentCertFile    string
+	AgentKeyFile     string
+
+	AgentTokenSecretNamespace string
+
 	LeaderElection   componentbaseconfig.LeaderElectionConfiguration
 	ClientConnection componentbaseconfig.ClientConnectionConfiguration
 }
@@ -38,6 +38,17 @@ type Options struct {
 	Storage      *storageoptions.StorageOptions
 	WorkerNumber int // WorkerNumber is the number of worker goroutines
 
+	// MetricsBindAddress is the address of the metrics endpoint, "0" disables the metrics endpoint
+	MetricsBindAddress string
+
+	// AgentBindAddress is the address of the ingestion endpoint of the agents, "0" disables the endpoint
+	AgentBindAddress string
+	AgentCertFile    string
+	AgentKeyFile     string
+
+	// AgentTokenSecretNamespace is the namespace of the secrets referenced by the `spec.agentTokenSecretRef` of the clusters
+	AgentTokenSecretNamespace string
+
 	Master     string
 	Kubeconfig string
 }
@@ -69,6 +80,9 @@ func NewClusterSynchroManagerOptions() (*Options, error) {
 	options.Logs = logs.NewOptions()
 	options.Storage = storageoptions.NewStorageOptions()
 	options.WorkerNumber = 5
+	options.MetricsBindAddress = "0"
+	options.AgentBindAddress = "0"
+	options.AgentTokenSecretNamespace = "clusterpedia-system"
 	return &options, nil
 }
 
@@ -87,6 +101,15 @@ func (o *Options) Flags() cliflag.NamedFlagSets {
 	fs.StringVar(&o.Master, "master", o.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig).")
 	fs.StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "Path to kubeconfig file with authorization and master location information.")
 
+	metricsfs := fss.FlagSet("metrics")
+	metricsfs.StringVar(&o.MetricsBindAddress, "metrics-bind-address", o.MetricsBindAddress, "The address the metrics endpoint binds to, such as \":8080\", the metrics endpoint is disabled by default or when it is \"0\".")
+
+	agentfs := fss.FlagSet("agent")
+	agentfs.StringVar(&o.AgentBindAddress, "agent-bind-address", o.AgentBindAddress, "The address the ingestion endpoint of the agents binds to, set to \"0\" to disable the endpoint.")
+	agentfs.StringVar(&o.AgentCertFile, "agent-tls-cert-file", o.AgentCertFile, "File containing the x509 certificate for serving the ingestion endpoint of the agents.")
+	agentfs.StringVar(&o.AgentKeyFile, "agent-tls-private-key-file", o.AgentKeyFile, "File containing the x509 private key matching --agent-tls-cert-file.")
+	agentfs.StringVar(&o.AgentTokenSecretNamespace, "agent-token-secret-namespace", o.AgentTokenSecretNamespace, "The namespace of the secrets of the agent tokens, only the secrets in this namespace are watched.")
+
 	logsapi.AddFlags(o.Logs, fss.FlagSet("logs"))
 
 	o.Storage.AddFlags(fss.FlagSet("storage"))
@@ -101,6 +124,9 @@ func (o *Options) Validate() error {
 	if o.WorkerNumber <= 0 {
 		errs = append(errs, fmt.Errorf("worker-number must be greater than 0"))
 	}
+	if (o.AgentCertFile == "") != (o.AgentKeyFile == "") {
+		errs = append(errs, fmt.Errorf("agent-tls-cert-file and agent-tls-private-key-file must be specified together"))
+	}
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -139,11 +165,19 @@ func (o *Options) Config() (*config.Config, error) {
 
 	return &config.Config{
 		CRDClient:      crdclient,
+		Kubeclient:     client,
 		Kubeconfig:     kubeconfig,
 		EventRecorder:  eventRecorder,
 		StorageFactory: storagefactory,
 		WorkerNumber:   o.WorkerNumber,
 
+		MetricsBindAddress: o.MetricsBindAddress,
+		AgentBindAddress:   o.AgentBindAddress,
+		AgentCertFile:      o.AgentCertFile,
+		AgentKeyFile:       o.AgentKeyFile,
+
+		AgentTokenSecretNamespace: o.AgentTokenSecretNamespace,
+
 		LeaderElection: o.LeaderElection,
 	}, nil
 }
@@ -2,8 +2,11 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -15,13 +18,16 @@ import (
 	"k8s.io/component-base/cli/globalflag"
 	"k8s.io/component-base/logs"
 	logsapi "k8s.io/component-base/logs/api/v1"
+	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/component-base/term"
 	"k8s.io/klog/v2"
 
 	"github.com/clusterpedia-io/clusterpedia/cmd/clustersynchro-manager/app/config"
 	"github.com/clusterpedia-io/clusterpedia/cmd/clustersynchro-manager/app/options"
+	"github.com/clusterpedia-io/clusterpedia/pkg/agent"
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
 	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager"
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/metrics"
 	clusterpediafeature "github.com/clusterpedia-io/clusterpedia/pkg/utils/feature"
 	"github.com/clusterpedia-io/clusterpedia/pkg/version/verflag"
 )
@@ -83,8 +89,21 @@ func NewClusterSynchroManagerCommand(ctx context.Context) *cobra.Command {
 }
 
 func Run(ctx context.Context, c *config.Config) error {
+	if c.MetricsBindAddress != "" && c.MetricsBindAddress != "0" {
+		metrics.Register()
+		go serveMetrics(ctx, c.MetricsBindAddress)
+	}
+
 	synchromanager := synchromanager.NewManager(c.CRDClient, c.StorageFactory)
+	agentsEnabled := c.AgentBindAddress != "" && c.AgentBindAddress != "0"
+	if agentsEnabled {
+		synchromanager.EnableAgents(c.Kubeclient, c.AgentTokenSecretNamespace)
+	}
+
 	if !c.LeaderElection.LeaderElect {
+		if agentsEnabled {
+			go serveAgents(ctx, c, synchromanager.AgentServer())
+		}
 		synchromanager.Run(c.WorkerNumber, ctx.Done())
 		return nil
 	}
@@ -124,6 +143,11 @@ func Run(ctx context.Context, c *config.Config) error {
 				done = make(chan struct{})
 				defer close(done)
 
+				// only the leader accepts the connections of the agents
+				if agentsEnabled {
+					go serveAgents(ctx, c, synchromanager.AgentServer())
+				}
+
 				stopCh := ctx.Done()
 				synchromanager.Run(c.WorkerNumber, stopCh)
 			},
@@ -137,3 +161,52 @@ func Run(ctx context.Context, c *config.Config) error {
 	})
 	return nil
 }
+
+// serveMetrics serves the metrics of the clustersynchro manager on `/metrics`,
+// the metrics are served by all replicas regardless of the leader election.
+func serveMetrics(ctx context.Context, address string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", legacyregistry.Handler())
+	server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	klog.InfoS("Serving metrics", "address", address)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.ErrorS(err, "Failed to serve metrics", "address", address)
+	}
+}
+
+// serveAgents serves the ingestion endpoint of the agents until the ctx is done.
+func serveAgents(ctx context.Context, c *config.Config, agents *agent.Server) {
+	mux := http.NewServeMux()
+	mux.Handle(agent.ConnectPath, agents)
+	server := &http.Server{Addr: c.AgentBindAddress, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+
+		// the hijacked connections are not closed by the shutdown of the server
+		agents.DisconnectAll()
+	}()
+
+	var err error
+	klog.InfoS("Serving the ingestion endpoint of the agents", "address", c.AgentBindAddress)
+	if c.AgentCertFile != "" {
+		err = server.ListenAndServeTLS(c.AgentCertFile, c.AgentKeyFile)
+	} else {
+		klog.Warning("The ingestion endpoint of the agents is served without TLS, the tokens of the agents are sent in plain text")
+		err = server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.ErrorS(err, "Failed to serve the ingestion endpoint of the agents", "address", c.AgentBindAddress)
+	}
+}
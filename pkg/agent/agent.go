@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/clustersynchro"
+)
+
+// Agent runs in the member cluster, it synchronizes the resources with the cluster synchro
+// and pushes them to the ingestion endpoint of clusterpedia.
+type Agent struct {
+	name       string
+	restConfig *rest.Config
+
+	server   string
+	location string
+	header   http.Header
+	dialer   *websocket.Dialer
+}
+
+// New creates the agent of the cluster, the `server` is the address of the ingestion endpoint,
+// such as `https://clusterpedia-agent.example.com:8443`.
+func New(name string, restConfig *rest.Config, server string, token string, tlsConfig *tls.Config) (*Agent, error) {
+	origin, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server address: %w", err)
+	}
+
+	location := *origin
+	switch origin.Scheme {
+	case "https":
+		location.Scheme = "wss"
+	case "http":
+		location.Scheme = "ws"
+	default:
+		return nil, fmt.Errorf("invalid server address: unsupported scheme %q", origin.Scheme)
+	}
+	location.Path = ConnectPath
+	location.RawQuery = url.Values{"cluster": []string{name}}.Encode()
+
+	return &Agent{
+		name:       name,
+		restConfig: restConfig,
+		server:     server,
+		location:   location.String(),
+		header:     http.Header{"Authorization": []string{"Bearer " + token}},
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			TLSClientConfig:  tlsConfig,
+			HandshakeTimeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// Run connects to the server and synchronizes the resources until the ctx is done,
+// the agent reconnects to the server with backoff when it's disconnected.
+func (agent *Agent) Run(ctx context.Context) {
+	backoff := wait.NewExponentialBackoffManager(time.Second, time.Minute, 2*time.Minute, 2.0, 1.0, clock.RealClock{})
+	wait.BackoffUntil(func() {
+		if err := agent.run(ctx); err != nil {
+			klog.ErrorS(err, "Agent is disconnected from the server", "cluster", agent.name, "server", agent.server)
+		}
+	}, backoff, true, ctx.Done())
+}
+
+func (agent *Agent) run(ctx context.Context) error {
+	ws, resp, err := agent.dialer.DialContext(ctx, agent.location, agent.header)
+	if err != nil {
+		if errors.Is(err, websocket.ErrBadHandshake) && resp != nil {
+			return fmt.Errorf("failed to connect to the server: %s", resp.Status)
+		}
+		return err
+	}
+	client := newClient(ws)
+	defer client.close(nil)
+
+	// the server pushes the config as soon as the agent is connected
+	var config *Config
+	select {
+	case config = <-client.configs:
+	case <-client.closed:
+		return client.err
+	case <-ctx.Done():
+		return nil
+	}
+	klog.InfoS("Agent is connected to the server", "cluster", agent.name, "server", agent.server)
+
+	synchro, err := clustersynchro.New(agent.name, agent.restConfig, &storageFactory{client: client}, &statusUpdater{client: client})
+	if err != nil {
+		return fmt.Errorf("failed to create cluster synchro: %w", err)
+	}
+	synchro.SetResources(config.SyncResources, config.SyncAllCustomResources)
+
+	shutdown, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		synchro.Run(shutdown)
+	}()
+	defer func() {
+		select {
+		case <-client.closed:
+			// the status can't be updated after the connection is closed
+			synchro.Shutdown(false)
+		default:
+			close(shutdown)
+		}
+		<-done
+	}()
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-client.closed:
+			return client.err
+		case config := <-client.configs:
+			synchro.SetResources(config.SyncResources, config.SyncAllCustomResources)
+		case <-ticker.C:
+			heartbeatCtx, cancel := context.WithTimeout(ctx, HeartbeatTimeout)
+			_, err := client.do(heartbeatCtx, &Request{Type: HeartbeatRequest})
+			cancel()
+			if err != nil {
+				klog.ErrorS(err, "Failed to send the heartbeat", "cluster", agent.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+)
+
+// client sends the requests of the agent to the server,
+// and dispatches the responses and the configs received from the server.
+type client struct {
+	conn *connection
+
+	nextID  uint64
+	lock    sync.Mutex
+	pending map[uint64]chan *Response
+
+	configs chan *Config
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	err       error
+}
+
+func newClient(ws *websocket.Conn) *client {
+	c := &client{
+		conn:    newConnection(ws),
+		pending: make(map[uint64]chan *Response),
+		configs: make(chan *Config, 1),
+		closed:  make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+func (c *client) run() {
+	defer close(c.conn.done)
+
+	for {
+		var msg Message
+		if err := c.conn.receive(&msg); err != nil {
+			c.close(err)
+			return
+		}
+
+		switch {
+		case msg.Response != nil:
+			c.lock.Lock()
+			ch, ok := c.pending[msg.Response.ID]
+			c.lock.Unlock()
+			if ok {
+				ch <- msg.Response
+			}
+		case msg.Config != nil:
+			// only the latest config is kept
+			select {
+			case c.configs <- msg.Config:
+			default:
+				select {
+				case <-c.configs:
+				default:
+				}
+				c.configs <- msg.Config
+			}
+		}
+	}
+}
+
+func (c *client) do(ctx context.Context, req *Request) (*Response, error) {
+	req.ID = atomic.AddUint64(&c.nextID, 1)
+	ch := make(chan *Response, 1)
+	c.lock.Lock()
+	c.pending[req.ID] = ch
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, req.ID)
+		c.lock.Unlock()
+	}()
+
+	if err := c.conn.send(req); err != nil {
+		c.close(err)
+		return nil, storage.NewRecoverableException(err)
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error == "" {
+			return resp, nil
+		}
+
+		err := errors.New(resp.Error)
+		if resp.Recoverable {
+			err = storage.NewRecoverableException(err)
+		}
+		return resp, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, storage.NewRecoverableException(c.err)
+	}
+}
+
+func (c *client) close(err error) {
+	c.closeOnce.Do(func() {
+		if err == nil {
+			err = errors.New("the connection is closed")
+		}
+		c.err = err
+		close(c.closed)
+		c.conn.close()
+	})
+}
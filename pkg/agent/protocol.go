@@ -0,0 +1,121 @@
+package agent
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/scheme"
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/features"
+	clusterpediafeature "github.com/clusterpedia-io/clusterpedia/pkg/utils/feature"
+)
+
+// ConnectPath is the path of the ingestion endpoint the agents connect to,
+// the name of the cluster is passed by the `cluster` query parameter,
+// and the token is passed by the `Authorization: Bearer <token>` header.
+const ConnectPath = "/agent/connect"
+
+const (
+	// HeartbeatInterval is the interval at which the agent sends the heartbeat.
+	HeartbeatInterval = 10 * time.Second
+
+	// HeartbeatTimeout is the time after which the connection is considered lost
+	// if no message is received from the other side.
+	HeartbeatTimeout = 3 * HeartbeatInterval
+)
+
+type RequestType string
+
+const (
+	HeartbeatRequest           RequestType = "Heartbeat"
+	GetResourceVersionsRequest RequestType = "GetResourceVersions"
+	NewResourceStorageRequest  RequestType = "NewResourceStorage"
+	WriteRequest               RequestType = "Write"
+	CleanResourceRequest       RequestType = "CleanResource"
+	UpdateStatusRequest        RequestType = "UpdateStatus"
+)
+
+// Request is sent by the agent, each request is answered by a `Response` with the same ID.
+type Request struct {
+	ID   uint64      `json:"id"`
+	Type RequestType `json:"type"`
+
+	// Resource is the synchronized resource of the `NewResourceStorage` request,
+	// its version is the storage version.
+	// For the other requests, it is the storage resource.
+	Resource metav1.GroupVersionResource `json:"resource,omitempty"`
+
+	// StorageResource is the storage resource the agent expects for the `NewResourceStorage` request,
+	// it is used to detect the different storage configs between the agent and the server.
+	StorageResource metav1.GroupVersionResource `json:"storageResource,omitempty"`
+	Namespaced      bool                        `json:"namespaced,omitempty"`
+
+	Changes []ResourceChange `json:"changes,omitempty"`
+
+	Status *clusterv1alpha2.ClusterStatus `json:"status,omitempty"`
+}
+
+// ResourceChange is a change of the resource in the `Write` request.
+type ResourceChange struct {
+	// Deleted means the resource is deleted, and only the namespace and name are set.
+	Deleted   bool   `json:"deleted,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+
+	Object json.RawMessage `json:"object,omitempty"`
+}
+
+type Response struct {
+	ID    uint64 `json:"id"`
+	Error string `json:"error,omitempty"`
+
+	// Recoverable means the error is a recoverable exception of the storage,
+	// and the agent can retry the request.
+	Recoverable bool `json:"recoverable,omitempty"`
+
+	ResourceVersions []StorageResourceVersions `json:"resourceVersions,omitempty"`
+}
+
+type StorageResourceVersions struct {
+	Resource metav1.GroupVersionResource `json:"resource"`
+	Versions map[string]interface{}      `json:"versions"`
+}
+
+// Config is pushed by the server when the agent is connected and whenever the cluster is changed.
+type Config struct {
+	SyncResources          []clusterv1alpha2.ClusterGroupResources `json:"syncResources,omitempty"`
+	SyncAllCustomResources bool                                    `json:"syncAllCustomResources,omitempty"`
+}
+
+// allows returns whether the group resource is in the sync resources.
+// The server can't discover the resources of the cluster,
+// so the resources must be the plural names unless the `*` wildcard is used.
+func (c *Config) allows(gr schema.GroupResource) bool {
+	for _, groupResources := range c.SyncResources {
+		if groupResources.Group == "*" {
+			return true
+		}
+		if groupResources.Group != gr.Group {
+			continue
+		}
+		for _, resource := range groupResources.Resources {
+			if resource == "*" || strings.EqualFold(resource, gr.Resource) {
+				return true
+			}
+		}
+	}
+
+	// the groups not registered in the legacy scheme are treated as the custom resources
+	return c.SyncAllCustomResources && clusterpediafeature.FeatureGate.Enabled(features.AllowSyncAllCustomResources) &&
+		!scheme.LegacyResourceScheme.IsGroupRegistered(gr.Group)
+}
+
+// Message is sent by the server, only one of the fields is set.
+type Message struct {
+	Response *Response `json:"response,omitempty"`
+	Config   *Config   `json:"config,omitempty"`
+}
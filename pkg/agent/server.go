@@ -0,0 +1,581 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/scheme"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/clustersynchro"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+// Server is the ingestion endpoint of the agents, it writes the resources pushed by the agents
+// to the storage, and the status of the clusters is driven by the heartbeats of the agents.
+type Server struct {
+	storage        storage.StorageFactory
+	updater        clustersynchro.ClusterStatusUpdater
+	storageConfigs *storageconfig.StorageConfigFactory
+	upgrader       websocket.Upgrader
+
+	lock     sync.Mutex
+	clusters map[string]*agentCluster
+}
+
+type agentCluster struct {
+	token  []byte
+	config Config
+
+	// conn is nil if the agent is not connected
+	conn *connection
+}
+
+func NewServer(storage storage.StorageFactory, updater clustersynchro.ClusterStatusUpdater) *Server {
+	return &Server{
+		storage:        storage,
+		updater:        updater,
+		storageConfigs: storageconfig.NewStorageConfigFactory(),
+		clusters:       make(map[string]*agentCluster),
+	}
+}
+
+// SetCluster registers the cluster synchronized by the agent and pushes the config to the connected agent,
+// if the token is changed, the connected agent is disconnected.
+func (s *Server) SetCluster(name string, token []byte, config Config) {
+	s.lock.Lock()
+	cluster, ok := s.clusters[name]
+	if !ok {
+		cluster = &agentCluster{}
+		s.clusters[name] = cluster
+	}
+
+	var closed *connection
+	if cluster.conn != nil && !bytes.Equal(cluster.token, token) {
+		closed, cluster.conn = cluster.conn, nil
+	}
+	cluster.token, cluster.config = token, config
+	conn := cluster.conn
+	s.lock.Unlock()
+
+	if closed != nil {
+		klog.InfoS("The token of the agent is changed, disconnect the agent", "cluster", name)
+		closed.close()
+	}
+	if conn != nil {
+		if err := conn.send(&Message{Config: &config}); err != nil {
+			klog.ErrorS(err, "Failed to push the config to the agent", "cluster", name)
+		}
+	}
+}
+
+// RemoveCluster unregisters the cluster and disconnects the agent,
+// it returns after the in-flight requests of the agent are finished.
+func (s *Server) RemoveCluster(name string) {
+	s.lock.Lock()
+	cluster, ok := s.clusters[name]
+	delete(s.clusters, name)
+
+	var conn *connection
+	if ok {
+		conn = cluster.conn
+	}
+	s.lock.Unlock()
+
+	if conn != nil {
+		conn.close()
+		<-conn.done
+	}
+}
+
+// DisconnectAll disconnects all the connected agents, the registered clusters are kept.
+func (s *Server) DisconnectAll() {
+	s.lock.Lock()
+	conns := make([]*connection, 0, len(s.clusters))
+	for _, cluster := range s.clusters {
+		if cluster.conn != nil {
+			conns = append(conns, cluster.conn)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, conn := range conns {
+		conn.close()
+		<-conn.done
+	}
+}
+
+// Connected returns whether the agent of the cluster is connected.
+func (s *Server) Connected(name string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	cluster, ok := s.clusters[name]
+	return ok && cluster.conn != nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("cluster")
+	if name == "" || !s.authenticate(name, req.Header.Get("Authorization")) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// the upgrader replies to the agent if the upgrade fails
+	ws, err := s.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		klog.ErrorS(err, "Failed to upgrade the connection of the agent", "cluster", name, "address", req.RemoteAddr)
+		return
+	}
+	s.serve(name, ws)
+}
+
+func (s *Server) authenticate(name string, authorization string) bool {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(authorization, prefix) {
+		return false
+	}
+	token := []byte(strings.TrimPrefix(authorization, prefix))
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cluster, ok := s.clusters[name]
+	return ok && len(cluster.token) != 0 && subtle.ConstantTimeCompare(cluster.token, token) == 1
+}
+
+func (s *Server) serve(name string, ws *websocket.Conn) {
+	conn := newConnection(ws)
+	s.lock.Lock()
+	cluster, ok := s.clusters[name]
+	if !ok {
+		s.lock.Unlock()
+		return
+	}
+	last := cluster.conn
+	cluster.conn = conn
+	config := cluster.config
+	s.lock.Unlock()
+
+	// the new connection of the agent replaces the old one,
+	// wait for the in-flight requests of the old connection to avoid the out-of-order writes.
+	if last != nil {
+		last.close()
+		<-last.done
+	}
+
+	klog.InfoS("Agent is connected", "cluster", name, "address", ws.RemoteAddr())
+	session := &session{
+		server:   s,
+		cluster:  name,
+		conn:     conn,
+		storages: make(map[schema.GroupVersionResource]*sessionStorage),
+	}
+	err := session.run(&config)
+
+	s.lock.Lock()
+	cluster, ok = s.clusters[name]
+	disconnected := ok && (cluster.conn == conn || cluster.conn == nil)
+	if disconnected {
+		cluster.conn = nil
+	}
+	s.lock.Unlock()
+
+	if !disconnected {
+		klog.InfoS("Agent connection is closed", "cluster", name, "address", ws.RemoteAddr())
+		return
+	}
+
+	message := "the agent is disconnected"
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		message = "the heartbeat of the agent is lost"
+	case err != nil && !errors.Is(err, io.EOF) && !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway):
+		message = fmt.Sprintf("the agent is disconnected: %v", err)
+	}
+	klog.InfoS("Agent is disconnected", "cluster", name, "reason", message)
+
+	status := &clusterv1alpha2.ClusterStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:    clusterv1alpha2.SynchroRunningCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  clusterv1alpha2.SynchroShutdownReason,
+				Message: message,
+			},
+			{
+				Type:    clusterv1alpha2.ClusterHealthyCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  clusterv1alpha2.ClusterNotReachableReason,
+				Message: message,
+			},
+		},
+	}
+	if err := s.updater.UpdateClusterStatus(context.TODO(), name, status); err != nil {
+		klog.ErrorS(err, "Failed to update the status of the cluster whose agent is disconnected", "cluster", name)
+	}
+}
+
+type connection struct {
+	ws *websocket.Conn
+
+	sendLock  sync.Mutex
+	closeOnce sync.Once
+
+	// done is closed after the connection is closed and the in-flight requests are finished
+	done chan struct{}
+}
+
+func newConnection(ws *websocket.Conn) *connection {
+	return &connection{ws: ws, done: make(chan struct{})}
+}
+
+func (c *connection) send(msg interface{}) error {
+	c.sendLock.Lock()
+	defer c.sendLock.Unlock()
+
+	_ = c.ws.SetWriteDeadline(time.Now().Add(HeartbeatTimeout))
+	return c.ws.WriteJSON(msg)
+}
+
+func (c *connection) receive(msg interface{}) error {
+	// the agent sends the heartbeat periodically,
+	// if nothing is received within the timeout, the connection is considered lost.
+	_ = c.ws.SetReadDeadline(time.Now().Add(HeartbeatTimeout))
+	return c.ws.ReadJSON(msg)
+}
+
+// close sends the close message to the other side and closes the connection,
+// the control messages can be written concurrently with the other messages.
+func (c *connection) close() {
+	c.closeOnce.Do(func() {
+		message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = c.ws.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+		_ = c.ws.Close()
+	})
+}
+
+// session handles the requests of a connected agent
+type session struct {
+	server  *Server
+	cluster string
+	conn    *connection
+
+	lock     sync.RWMutex
+	storages map[schema.GroupVersionResource]*sessionStorage
+}
+
+type sessionStorage struct {
+	storage.ResourceStorage
+
+	// syncResource is the group resource synchronized by the agent,
+	// it's checked against the sync resources of the cluster on every write.
+	syncResource schema.GroupResource
+
+	// the written objects must be the kind of the storage resource in the storage version,
+	// and the kind of the custom resource is pinned by the first written object.
+	storageVersion schema.GroupVersion
+	namespaced     bool
+	kind           string
+}
+
+// legacyResourceKind returns the kind of the resource in the legacy scheme,
+// it is empty if the group of the resource is not in the legacy scheme.
+func legacyResourceKind(gvr schema.GroupVersionResource) (kind string, legacy bool) {
+	if !scheme.LegacyResourceScheme.IsGroupRegistered(gvr.Group) {
+		return "", false
+	}
+	for kind := range scheme.LegacyResourceScheme.KnownTypes(gvr.GroupVersion()) {
+		if resource, _ := meta.UnsafeGuessKindToResource(gvr.GroupVersion().WithKind(kind)); resource == gvr {
+			return kind, true
+		}
+	}
+	return "", true
+}
+
+// run pushes the config to the agent and handles the requests until the connection is closed.
+func (s *session) run(config *Config) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	var handlers sync.WaitGroup
+	defer func() {
+		s.conn.close()
+		cancel()
+		handlers.Wait()
+		close(s.conn.done)
+	}()
+
+	if err := s.conn.send(&Message{Config: config}); err != nil {
+		return err
+	}
+	s.updateHeartbeatStatus(ctx)
+
+	for {
+		var req Request
+		if err := s.conn.receive(&req); err != nil {
+			return err
+		}
+
+		// the requests are handled concurrently, the agent waits for the response
+		// before sending the next request of the same resource, so the writes are in order.
+		handlers.Add(1)
+		go func() {
+			defer handlers.Done()
+
+			resp := s.handle(ctx, &req)
+			resp.ID = req.ID
+			if err := s.conn.send(&Message{Response: resp}); err != nil {
+				klog.ErrorS(err, "Failed to send the response to the agent", "cluster", s.cluster, "request", req.Type)
+			}
+		}()
+	}
+}
+
+func (s *session) handle(ctx context.Context, req *Request) *Response {
+	var resp Response
+	var err error
+	switch req.Type {
+	case HeartbeatRequest:
+		s.updateHeartbeatStatus(ctx)
+	case GetResourceVersionsRequest:
+		resp.ResourceVersions, err = s.getResourceVersions(ctx)
+	case NewResourceStorageRequest:
+		err = s.newResourceStorage(req)
+	case WriteRequest:
+		err = s.write(ctx, req)
+	case CleanResourceRequest:
+		err = s.cleanResource(ctx, req)
+	case UpdateStatusRequest:
+		// the conditions of the cluster are driven by the heartbeats and the connection,
+		// only the version and the sync status of the resources are accepted from the agent.
+		if req.Status != nil {
+			status := &clusterv1alpha2.ClusterStatus{Version: req.Status.Version, SyncResources: req.Status.SyncResources}
+			err = s.server.updater.UpdateClusterStatus(ctx, s.cluster, status)
+		}
+	default:
+		err = fmt.Errorf("unknown request type: %q", req.Type)
+	}
+
+	if err != nil {
+		klog.ErrorS(err, "Failed to handle the request of the agent", "cluster", s.cluster, "request", req.Type, "resource", req.Resource)
+		resp.Error = err.Error()
+		resp.Recoverable = storage.IsRecoverableException(err)
+	}
+	return &resp
+}
+
+// updateHeartbeatStatus marks the cluster healthy and running when a heartbeat is received,
+// the status isn't updated by the manager if the conditions are not changed.
+func (s *session) updateHeartbeatStatus(ctx context.Context) {
+	status := &clusterv1alpha2.ClusterStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:   clusterv1alpha2.SynchroRunningCondition,
+				Status: metav1.ConditionTrue,
+				Reason: clusterv1alpha2.SynchroRunningReason,
+			},
+			{
+				Type:   clusterv1alpha2.ClusterHealthyCondition,
+				Status: metav1.ConditionTrue,
+				Reason: clusterv1alpha2.ClusterHealthyReason,
+			},
+		},
+	}
+	if err := s.server.updater.UpdateClusterStatus(ctx, s.cluster, status); err != nil {
+		klog.ErrorS(err, "Failed to update the status of the cluster by the heartbeat", "cluster", s.cluster)
+	}
+}
+
+// allowed returns whether the group resource is in the sync resources pushed to the agent.
+func (s *session) allowed(gr schema.GroupResource) bool {
+	s.server.lock.Lock()
+	cluster, ok := s.server.clusters[s.cluster]
+	var config Config
+	if ok {
+		config = cluster.config
+	}
+	s.server.lock.Unlock()
+	if !ok {
+		return false
+	}
+	return config.allows(gr)
+}
+
+func (s *session) getResourceVersions(ctx context.Context) ([]StorageResourceVersions, error) {
+	resourceVersions, err := s.server.storage.GetResourceVersions(ctx, s.cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]StorageResourceVersions, 0, len(resourceVersions))
+	for gvr, rvs := range resourceVersions {
+		versions = append(versions, StorageResourceVersions{Resource: metav1.GroupVersionResource(gvr), Versions: rvs})
+	}
+	return versions, nil
+}
+
+func (s *session) newResourceStorage(req *Request) error {
+	syncGR := schema.GroupVersionResource(req.Resource).GroupResource()
+	if !s.allowed(syncGR) {
+		return fmt.Errorf("the resource %s is not in the sync resources of the cluster", syncGR)
+	}
+
+	config, err := s.server.storageConfigs.NewConfig(schema.GroupVersionResource(req.Resource), req.Namespaced)
+	if err != nil {
+		return err
+	}
+
+	storageGVR := config.StorageGroupResource.WithVersion(config.StorageVersion.Version)
+	if expected := schema.GroupVersionResource(req.StorageResource); storageGVR != expected {
+		return fmt.Errorf("the storage resource %s is different from %s of the agent", storageGVR, expected)
+	}
+
+	kind, legacy := legacyResourceKind(storageGVR)
+	if legacy && kind == "" {
+		return fmt.Errorf("the kind of the storage resource %s is unknown", storageGVR)
+	}
+
+	resourceStorage, err := s.server.storage.NewResourceStorage(config)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	s.storages[storageGVR] = &sessionStorage{
+		ResourceStorage: resourceStorage,
+		syncResource:    syncGR,
+		storageVersion:  config.StorageVersion,
+		namespaced:      config.Namespaced,
+		kind:            kind,
+	}
+	s.lock.Unlock()
+	return nil
+}
+
+// getStorage returns the resource storage created in the session,
+// and the resource must still be in the sync resources of the cluster.
+func (s *session) getStorage(gvr schema.GroupVersionResource) (*sessionStorage, error) {
+	s.lock.RLock()
+	resourceStorage, ok := s.storages[gvr]
+	s.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("the resource storage of %s is not created", gvr)
+	}
+	if !s.allowed(resourceStorage.syncResource) {
+		return nil, fmt.Errorf("the resource %s is not in the sync resources of the cluster", resourceStorage.syncResource)
+	}
+	return resourceStorage, nil
+}
+
+// checkObject checks that the object is the kind of the storage, and returns false
+// if the namespace of the object doesn't fit the scope of the resource.
+func (s *session) checkObject(resourceStorage *sessionStorage, obj runtime.Object) (bool, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.GroupVersion() != resourceStorage.storageVersion {
+		return false, fmt.Errorf("the version of %s is not the storage version %s", gvk, resourceStorage.storageVersion)
+	}
+
+	s.lock.Lock()
+	if resourceStorage.kind == "" {
+		resourceStorage.kind = gvk.Kind
+	}
+	kind := resourceStorage.kind
+	s.lock.Unlock()
+	if gvk.Kind != kind {
+		return false, fmt.Errorf("the kind %s is not the kind %s of the storage", gvk.Kind, kind)
+	}
+
+	metaobj, err := meta.Accessor(obj)
+	if err != nil {
+		return false, err
+	}
+	return s.inScope(resourceStorage, metaobj.GetNamespace()), nil
+}
+
+func (s *session) inScope(resourceStorage *sessionStorage, namespace string) bool {
+	return resourceStorage.namespaced == (namespace != "")
+}
+
+func (s *session) write(ctx context.Context, req *Request) error {
+	gvr := schema.GroupVersionResource(req.Resource)
+	resourceStorage, err := s.getStorage(gvr)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]storage.ResourceChange, 0, len(req.Changes))
+	for _, change := range req.Changes {
+		if change.Deleted {
+			if !s.inScope(resourceStorage, change.Namespace) {
+				klog.InfoS("Skip the deleted resource out of the scope of the storage", "cluster", s.cluster, "resource", gvr, "namespace", change.Namespace, "name", change.Name)
+				continue
+			}
+			obj := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: change.Namespace, Name: change.Name}}
+			changes = append(changes, storage.ResourceChange{Deleted: true, Object: obj})
+			continue
+		}
+
+		obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, change.Object)
+		if err != nil {
+			return fmt.Errorf("failed to decode the resource: %w", err)
+		}
+		inScope, err := s.checkObject(resourceStorage, obj)
+		if err != nil {
+			return fmt.Errorf("the resource is rejected by the storage of %s: %w", gvr, err)
+		}
+		if !inScope {
+			klog.InfoS("Skip the resource out of the scope of the storage", "cluster", s.cluster, "resource", gvr, "namespace", change.Namespace, "name", change.Name)
+			continue
+		}
+
+		// the cluster name is always the name of the authenticated agent
+		utils.InjectClusterName(obj, s.cluster)
+		changes = append(changes, storage.ResourceChange{Object: obj})
+	}
+
+	if writer, ok := resourceStorage.ResourceStorage.(storage.ResourceBatchWriter); ok && len(changes) > 1 {
+		return writer.BatchWrite(ctx, s.cluster, changes)
+	}
+	for _, change := range changes {
+		var err error
+		if change.Deleted {
+			err = resourceStorage.Delete(ctx, s.cluster, change.Object)
+		} else {
+			err = resourceStorage.Upsert(ctx, s.cluster, change.Object)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *session) cleanResource(ctx context.Context, req *Request) error {
+	gvr := schema.GroupVersionResource(req.Resource)
+	if _, err := s.getStorage(gvr); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	delete(s.storages, gvr)
+	s.lock.Unlock()
+
+	return s.server.storage.CleanClusterResource(ctx, s.cluster, gvr)
+}
@@ -0,0 +1,283 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+)
+
+type fakeStorageFactory struct {
+	storage.StorageFactory
+
+	lock    sync.Mutex
+	changes []string
+}
+
+func (f *fakeStorageFactory) GetResourceVersions(ctx context.Context, cluster string) (map[schema.GroupVersionResource]map[string]interface{}, error) {
+	return map[schema.GroupVersionResource]map[string]interface{}{
+		appsv1.SchemeGroupVersion.WithResource("deployments"): {"default/deploy-1": "10"},
+	}, nil
+}
+
+func (f *fakeStorageFactory) NewResourceStorage(config *storage.ResourceStorageConfig) (storage.ResourceStorage, error) {
+	return &fakeResourceStorage{factory: f}, nil
+}
+
+func (f *fakeStorageFactory) record(cluster, action string, obj runtime.Object) error {
+	metaobj, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.changes = append(f.changes, strings.Join([]string{cluster, action, metaobj.GetNamespace(), metaobj.GetName(), metaobj.GetResourceVersion()}, " "))
+	return nil
+}
+
+func (f *fakeStorageFactory) recorded() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string(nil), f.changes...)
+}
+
+type fakeResourceStorage struct {
+	storage.ResourceStorage
+
+	factory *fakeStorageFactory
+}
+
+func (s *fakeResourceStorage) Upsert(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.factory.record(cluster, "upsert", obj)
+}
+
+func (s *fakeResourceStorage) Delete(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.factory.record(cluster, "delete", obj)
+}
+
+type fakeStatusUpdater struct {
+	statuses chan *clusterv1alpha2.ClusterStatus
+}
+
+func (u *fakeStatusUpdater) UpdateClusterStatus(ctx context.Context, name string, status *clusterv1alpha2.ClusterStatus) error {
+	u.statuses <- status
+	return nil
+}
+
+func dialServer(t *testing.T, url, cluster, token string) (*client, error) {
+	t.Helper()
+
+	header := http.Header{"Authorization": []string{"Bearer " + token}}
+	ws, _, err := websocket.DefaultDialer.Dial(strings.Replace(url, "http", "ws", 1)+ConnectPath+"?cluster="+cluster, header)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(ws), nil
+}
+
+func receiveConfig(t *testing.T, c *client) *Config {
+	t.Helper()
+
+	select {
+	case config := <-c.configs:
+		return config
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the config")
+		return nil
+	}
+}
+
+func receiveStatus(t *testing.T, updater *fakeStatusUpdater) *clusterv1alpha2.ClusterStatus {
+	t.Helper()
+
+	select {
+	case status := <-updater.statuses:
+		return status
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the status update")
+		return nil
+	}
+}
+
+func expectHealthy(t *testing.T, status *clusterv1alpha2.ClusterStatus, healthy bool) {
+	t.Helper()
+
+	expected := metav1.ConditionFalse
+	if healthy {
+		expected = metav1.ConditionTrue
+	}
+	for _, condType := range []string{clusterv1alpha2.SynchroRunningCondition, clusterv1alpha2.ClusterHealthyCondition} {
+		if condition := meta.FindStatusCondition(status.Conditions, condType); condition == nil || condition.Status != expected {
+			t.Fatalf("expected the %s condition is %s, got %+v", condType, expected, condition)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	factory := &fakeStorageFactory{}
+	updater := &fakeStatusUpdater{statuses: make(chan *clusterv1alpha2.ClusterStatus, 10)}
+	server := NewServer(factory, updater)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	syncResources := []clusterv1alpha2.ClusterGroupResources{{Group: "apps", Resources: []string{"deployments"}}}
+	server.SetCluster("cluster-1", []byte("token-1"), Config{SyncResources: syncResources})
+
+	if _, err := dialServer(t, httpServer.URL, "cluster-1", "invalid-token"); err == nil {
+		t.Fatal("expected the agent with the invalid token to be rejected")
+	}
+	if _, err := dialServer(t, httpServer.URL, "cluster-2", "token-1"); err == nil {
+		t.Fatal("expected the agent of the unregistered cluster to be rejected")
+	}
+
+	c, err := dialServer(t, httpServer.URL, "cluster-1", "token-1")
+	if err != nil {
+		t.Fatalf("failed to connect to the server: %v", err)
+	}
+	if config := receiveConfig(t, c); len(config.SyncResources) != 1 || config.SyncResources[0].Group != "apps" {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	expectHealthy(t, receiveStatus(t, updater), true)
+
+	// the config is pushed when the cluster is changed
+	server.SetCluster("cluster-1", []byte("token-1"), Config{SyncResources: syncResources, SyncAllCustomResources: true})
+	if config := receiveConfig(t, c); !config.SyncAllCustomResources {
+		t.Fatalf("unexpected config: %+v", config)
+	}
+	if !server.Connected("cluster-1") {
+		t.Fatal("expected the agent to be connected")
+	}
+
+	ctx := context.Background()
+	if _, err := c.do(ctx, &Request{Type: HeartbeatRequest}); err != nil {
+		t.Fatalf("failed to send the heartbeat: %v", err)
+	}
+	expectHealthy(t, receiveStatus(t, updater), true)
+
+	// the conditions reported by the agent are ignored
+	reported := &clusterv1alpha2.ClusterStatus{
+		Version:    "v1.25.0",
+		Conditions: []metav1.Condition{{Type: clusterv1alpha2.ClusterHealthyCondition, Status: metav1.ConditionFalse}},
+	}
+	if err := (&statusUpdater{client: c}).UpdateClusterStatus(ctx, "cluster-1", reported); err != nil {
+		t.Fatalf("failed to update the status: %v", err)
+	}
+	if status := receiveStatus(t, updater); status.Version != "v1.25.0" || len(status.Conditions) != 0 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	remote := &storageFactory{client: c}
+	versions, err := remote.GetResourceVersions(ctx, "cluster-1")
+	if err != nil {
+		t.Fatalf("failed to get resource versions: %v", err)
+	}
+	if rvs := versions[appsv1.SchemeGroupVersion.WithResource("deployments")]; rvs["default/deploy-1"] != "10" {
+		t.Fatalf("unexpected resource versions: %v", versions)
+	}
+
+	config, err := storageconfig.NewStorageConfigFactory().NewConfig(appsv1.SchemeGroupVersion.WithResource("deployments"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := remote.NewResourceStorage(config)
+	if err != nil {
+		t.Fatalf("failed to create resource storage: %v", err)
+	}
+
+	podConfig, err := storageconfig.NewStorageConfigFactory().NewConfig(corev1.SchemeGroupVersion.WithResource("pods"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := remote.NewResourceStorage(podConfig); err == nil {
+		t.Fatal("expected the resource storage of the resource not in the sync resources to be rejected")
+	}
+	pods := &resourceStorage{client: c, config: podConfig, storageGVR: corev1.SchemeGroupVersion.WithResource("pods")}
+	if err := pods.Upsert(ctx, "cluster-1", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}); err == nil {
+		t.Fatal("expected the write of the resource not in the sync resources to be rejected")
+	}
+
+	deploy := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy-1", ResourceVersion: "11"},
+	}
+	if err := rs.Upsert(ctx, "cluster-1", deploy); err != nil {
+		t.Fatalf("failed to upsert resource: %v", err)
+	}
+	deleted := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy-2"}}
+	if err := rs.(storage.ResourceBatchWriter).BatchWrite(ctx, "cluster-1", []storage.ResourceChange{{Deleted: true, Object: deleted}}); err != nil {
+		t.Fatalf("failed to write resources: %v", err)
+	}
+
+	// the other kinds are rejected, and the resources out of the scope of the storage are skipped
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "secret-1"},
+	}
+	if err := rs.Upsert(ctx, "cluster-1", secret); err == nil {
+		t.Fatal("expected the write of the other kind to be rejected")
+	}
+	clusterScoped := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy-3"},
+	}
+	if err := rs.Upsert(ctx, "cluster-1", clusterScoped); err != nil {
+		t.Fatalf("failed to upsert resource: %v", err)
+	}
+
+	// only the resource storages created in the session can be cleaned
+	if err := remote.CleanClusterResource(ctx, "cluster-1", corev1.SchemeGroupVersion.WithResource("pods")); err == nil {
+		t.Fatal("expected the clean of the resource storage not created to be rejected")
+	}
+
+	expected := []string{"cluster-1 upsert default deploy-1 11", "cluster-1 delete default deploy-2 "}
+	if changes := factory.recorded(); strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected changes %q, got %q", expected, changes)
+	}
+
+	// the writes are rejected after the resource is removed from the sync resources
+	server.SetCluster("cluster-1", []byte("token-1"), Config{SyncResources: []clusterv1alpha2.ClusterGroupResources{{Group: "", Resources: []string{"pods"}}}})
+	receiveConfig(t, c)
+	if err := rs.Upsert(ctx, "cluster-1", deploy); err == nil {
+		t.Fatal("expected the write of the removed sync resource to be rejected")
+	}
+
+	// the status of the cluster is updated by the server when the agent is disconnected
+	c.close(nil)
+	status := receiveStatus(t, updater)
+	expectHealthy(t, status, false)
+	if condition := meta.FindStatusCondition(status.Conditions, clusterv1alpha2.ClusterHealthyCondition); condition.Reason != clusterv1alpha2.ClusterNotReachableReason {
+		t.Fatalf("unexpected healthy condition: %+v", condition)
+	}
+	if server.Connected("cluster-1") {
+		t.Fatal("expected the agent to be disconnected")
+	}
+
+	// the agent is disconnected when the cluster is removed
+	c, err = dialServer(t, httpServer.URL, "cluster-1", "token-1")
+	if err != nil {
+		t.Fatalf("failed to reconnect to the server: %v", err)
+	}
+	receiveConfig(t, c)
+	server.RemoveCluster("cluster-1")
+	select {
+	case <-c.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the connection to be closed")
+	}
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+)
+
+var errNotSupported = errors.New("not supported by the agent")
+
+// storageFactory is used by the cluster synchro of the agent,
+// it forwards the writes of the resources to the server.
+type storageFactory struct {
+	client *client
+}
+
+var _ storage.StorageFactory = &storageFactory{}
+
+func (f *storageFactory) GetSupportedRequestVerbs() []string {
+	return nil
+}
+
+// PrepareCluster is called by the server when the cluster is registered.
+func (f *storageFactory) PrepareCluster(cluster string) error {
+	return nil
+}
+
+func (f *storageFactory) GetResourceVersions(ctx context.Context, cluster string) (map[schema.GroupVersionResource]map[string]interface{}, error) {
+	resp, err := f.client.do(ctx, &Request{Type: GetResourceVersionsRequest})
+	if err != nil {
+		return nil, err
+	}
+
+	resourceVersions := make(map[schema.GroupVersionResource]map[string]interface{}, len(resp.ResourceVersions))
+	for _, versions := range resp.ResourceVersions {
+		resourceVersions[schema.GroupVersionResource(versions.Resource)] = versions.Versions
+	}
+	return resourceVersions, nil
+}
+
+func (f *storageFactory) GetCollectionResources(ctx context.Context) ([]*internal.CollectionResource, error) {
+	return nil, errNotSupported
+}
+
+func (f *storageFactory) NewResourceStorage(config *storage.ResourceStorageConfig) (storage.ResourceStorage, error) {
+	storageGVR := config.StorageGroupResource.WithVersion(config.StorageVersion.Version)
+	req := &Request{
+		Type:            NewResourceStorageRequest,
+		Resource:        metav1.GroupVersionResource(config.GroupResource.WithVersion(config.StorageVersion.Version)),
+		StorageResource: metav1.GroupVersionResource(storageGVR),
+		Namespaced:      config.Namespaced,
+	}
+	if _, err := f.client.do(context.TODO(), req); err != nil {
+		return nil, err
+	}
+	return &resourceStorage{client: f.client, config: config, storageGVR: storageGVR}, nil
+}
+
+func (f *storageFactory) NewCollectionResourceStorage(cr *internal.CollectionResource) (storage.CollectionResourceStorage, error) {
+	return nil, errNotSupported
+}
+
+// CleanCluster is called by the server when the cluster is removed.
+func (f *storageFactory) CleanCluster(ctx context.Context, cluster string) error {
+	return errNotSupported
+}
+
+func (f *storageFactory) CleanClusterResource(ctx context.Context, cluster string, gvr schema.GroupVersionResource) error {
+	_, err := f.client.do(ctx, &Request{Type: CleanResourceRequest, Resource: metav1.GroupVersionResource(gvr)})
+	return err
+}
+
+// resourceStorage only supports writing the resources.
+type resourceStorage struct {
+	client     *client
+	config     *storage.ResourceStorageConfig
+	storageGVR schema.GroupVersionResource
+}
+
+var _ storage.ResourceBatchWriter = &resourceStorage{}
+
+func (s *resourceStorage) GetStorageConfig() *storage.ResourceStorageConfig {
+	return s.config
+}
+
+func (s *resourceStorage) Get(ctx context.Context, cluster, namespace, name string, obj runtime.Object) error {
+	return errNotSupported
+}
+
+func (s *resourceStorage) List(ctx context.Context, listObj runtime.Object, opts *internal.ListOptions) error {
+	return errNotSupported
+}
+
+func (s *resourceStorage) Watch(ctx context.Context, options *internal.ListOptions) (watch.Interface, error) {
+	return nil, errNotSupported
+}
+
+func (s *resourceStorage) Create(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.Upsert(ctx, cluster, obj)
+}
+
+func (s *resourceStorage) Update(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.Upsert(ctx, cluster, obj)
+}
+
+func (s *resourceStorage) Upsert(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.BatchWrite(ctx, cluster, []storage.ResourceChange{{Object: obj}})
+}
+
+func (s *resourceStorage) Delete(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.BatchWrite(ctx, cluster, []storage.ResourceChange{{Deleted: true, Object: obj}})
+}
+
+func (s *resourceStorage) BatchWrite(ctx context.Context, cluster string, changes []storage.ResourceChange) error {
+	req := &Request{
+		Type:     WriteRequest,
+		Resource: metav1.GroupVersionResource(s.storageGVR),
+		Changes:  make([]ResourceChange, 0, len(changes)),
+	}
+	for _, change := range changes {
+		if change.Deleted {
+			metaobj, err := meta.Accessor(change.Object)
+			if err != nil {
+				return err
+			}
+			req.Changes = append(req.Changes, ResourceChange{Deleted: true, Namespace: metaobj.GetNamespace(), Name: metaobj.GetName()})
+			continue
+		}
+
+		data, err := json.Marshal(change.Object)
+		if err != nil {
+			return err
+		}
+		req.Changes = append(req.Changes, ResourceChange{Object: data})
+	}
+
+	_, err := s.client.do(ctx, req)
+	return err
+}
+
+// statusUpdater forwards the status of the cluster to the server.
+type statusUpdater struct {
+	client *client
+}
+
+func (u *statusUpdater) UpdateClusterStatus(ctx context.Context, name string, status *clusterv1alpha2.ClusterStatus) error {
+	_, err := u.client.do(ctx, &Request{Type: UpdateStatusRequest, Status: status})
+	return err
+}
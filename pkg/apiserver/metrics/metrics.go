@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	namespace = "clusterpedia"
+	subsystem = "apiserver"
+)
+
+var requestLabels = []string{"group", "version", "resource", "verb", "storage"}
+
+var (
+	// RequestDuration is the latency of the resource requests served by the apiserver,
+	// including the storage queries and the encoding of the responses.
+	RequestDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "request_duration_seconds",
+			Help:           "Latency of the resource requests, partitioned by group, version, resource, verb and storage layer.",
+			Buckets:        []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		requestLabels,
+	)
+
+	// StorageDuration is the latency of getting or listing the resources from the storage layer.
+	StorageDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "storage_duration_seconds",
+			Help:           "Latency of getting or listing the resources from the storage layer, partitioned by group, version, resource, verb and storage layer.",
+			Buckets:        []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		requestLabels,
+	)
+
+	// ReturnedObjects is the number of the objects returned by the storage layer for each list.
+	ReturnedObjects = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "returned_objects",
+			Help:           "Number of the objects returned by the storage layer, partitioned by group, version, resource, verb and storage layer.",
+			Buckets:        compbasemetrics.ExponentialBuckets(1, 4, 9),
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		requestLabels,
+	)
+)
+
+var (
+	registerMetrics sync.Once
+
+	// storageLayer is the name of the storage layer used by the apiserver,
+	// it is the same for all requests, and is set by `Register`.
+	storageLayer string
+)
+
+// Register registers the metrics of the apiserver to the legacy registry,
+// which is served by the `/metrics` of the generic apiserver.
+func Register(storage string) {
+	registerMetrics.Do(func() {
+		storageLayer = storage
+
+		legacyregistry.MustRegister(RequestDuration)
+		legacyregistry.MustRegister(StorageDuration)
+		legacyregistry.MustRegister(ReturnedObjects)
+	})
+}
+
+func labelValues(gvr schema.GroupVersionResource, verb string) []string {
+	return []string{gvr.Group, gvr.Version, gvr.Resource, verb, storageLayer}
+}
+
+// ObserveRequest records the latency of the resource request since `start`.
+func ObserveRequest(gvr schema.GroupVersionResource, verb string, start time.Time) {
+	RequestDuration.WithLabelValues(labelValues(gvr, verb)...).Observe(time.Since(start).Seconds())
+}
+
+// ObserveStorage records the latency of the storage layer since `start`.
+func ObserveStorage(gvr schema.GroupVersionResource, verb string, start time.Time) {
+	StorageDuration.WithLabelValues(labelValues(gvr, verb)...).Observe(time.Since(start).Seconds())
+}
+
+// ObserveReturnedObjects records the number of the objects returned by the storage layer.
+func ObserveReturnedObjects(gvr schema.GroupVersionResource, verb string, count int) {
+	ReturnedObjects.WithLabelValues(labelValues(gvr, verb)...).Observe(float64(count))
+}
@@ -3,6 +3,7 @@ package collectionresources
 import (
 	"context"
 	"strings"
+	"sync"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -12,14 +13,20 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
 	genericfeatures "k8s.io/apiserver/pkg/features"
 	"k8s.io/apiserver/pkg/registry/rest"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
 	internal "github.com/clusterpedia-io/api/clusterpedia"
 	"github.com/clusterpedia-io/api/clusterpedia/scheme"
 	"github.com/clusterpedia-io/api/clusterpedia/v1beta1"
+	"github.com/clusterpedia-io/clusterpedia/pkg/apiserver/metrics"
+	clusterinformer "github.com/clusterpedia-io/clusterpedia/pkg/generated/informers/externalversions/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/kubeapiserver/printers"
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
 	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
 	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
@@ -29,9 +36,15 @@ import (
 
 type REST struct {
 	serializer runtime.NegotiatedSerializer
+	factory    storage.StorageFactory
 
+	lock     sync.RWMutex
 	list     *internal.CollectionResourceList
 	storages map[string]storage.CollectionResourceStorage
+
+	// builtins are the collection resources provided by the storage layer,
+	// they can't be overridden by the custom collection resources.
+	builtins sets.String
 }
 
 var _ rest.Lister = &REST{}
@@ -39,41 +52,135 @@ var _ rest.Scoper = &REST{}
 var _ rest.Getter = &REST{}
 var _ rest.Storage = &REST{}
 
-func NewREST(serializer runtime.NegotiatedSerializer, factory storage.StorageFactory) *REST {
+func NewREST(serializer runtime.NegotiatedSerializer, factory storage.StorageFactory, informer clusterinformer.CustomCollectionResourceInformer) *REST {
 	crs, err := factory.GetCollectionResources(context.TODO())
 	if err != nil {
 		klog.Fatal(err)
 	}
 
+	r := &REST{
+		serializer: serializer,
+		factory:    factory,
+		list:       &internal.CollectionResourceList{},
+		storages:   make(map[string]storage.CollectionResourceStorage, len(crs)),
+		builtins:   sets.NewString(),
+	}
+	for _, cr := range crs {
+		r.builtins.Insert(cr.Name)
+		_ = r.addCollectionResource(cr)
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			r.updateCustomCollectionResource(obj.(*clusterv1alpha2.CustomCollectionResource))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			r.updateCustomCollectionResource(obj.(*clusterv1alpha2.CustomCollectionResource))
+		},
+		DeleteFunc: func(obj interface{}) {
+			name, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				return
+			}
+			r.removeCustomCollectionResource(name)
+		},
+	})
+	return r
+}
+
+func (s *REST) updateCustomCollectionResource(ccr *clusterv1alpha2.CustomCollectionResource) {
+	if s.builtins.Has(ccr.Name) {
+		klog.InfoS("Custom collection resource conflicts with the built-in collection resource, skip it", "name", ccr.Name)
+		return
+	}
+
+	cr := &internal.CollectionResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ccr.Name,
+		},
+		ResourceTypes: make([]internal.CollectionResourceType, 0, len(ccr.Spec.ResourceTypes)),
+	}
+	for _, rt := range ccr.Spec.ResourceTypes {
+		cr.ResourceTypes = append(cr.ResourceTypes, internal.CollectionResourceType{
+			Group:    rt.Group,
+			Version:  rt.Version,
+			Resource: rt.Resource,
+		})
+	}
+
+	if err := s.addCollectionResource(cr); err != nil {
+		klog.ErrorS(err, "Failed to register custom collection resource", "name", ccr.Name)
+		s.removeCustomCollectionResource(ccr.Name)
+		return
+	}
+	klog.V(2).InfoS("Register custom collection resource", "name", ccr.Name)
+}
+
+func (s *REST) removeCustomCollectionResource(name string) {
+	if s.builtins.Has(name) {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.storages[name]; !ok {
+		return
+	}
+
+	delete(s.storages, name)
 	list := &internal.CollectionResourceList{}
-	storages := make(map[string]storage.CollectionResourceStorage, len(crs))
+	for _, item := range s.list.Items {
+		if item.Name != name {
+			list.Items = append(list.Items, item)
+		}
+	}
+	s.list = list
+	klog.V(2).InfoS("Unregister custom collection resource", "name", name)
+}
+
+// addCollectionResource adds or replaces the collection resource with the same name.
+func (s *REST) addCollectionResource(cr *internal.CollectionResource) error {
 	configFactory := storageconfig.NewStorageConfigFactory()
-	for _, cr := range crs {
-		for irt := range cr.ResourceTypes {
-			rt := &cr.ResourceTypes[irt]
-			if rt.Resource != "" {
-				config, err := configFactory.NewConfig(rt.GroupResource().WithVersion(""), false)
-				if err != nil {
-					continue
-				}
+	for irt := range cr.ResourceTypes {
+		rt := &cr.ResourceTypes[irt]
+		if rt.Resource != "" {
+			config, err := configFactory.NewConfig(rt.GroupResource().WithVersion(""), false)
+			if err != nil {
+				continue
+			}
 
-				*rt = internal.CollectionResourceType{
-					Group:    config.StorageGroupResource.Group,
-					Version:  config.StorageVersion.Version,
-					Resource: config.StorageGroupResource.Resource,
-				}
+			*rt = internal.CollectionResourceType{
+				Group:    config.StorageGroupResource.Group,
+				Version:  config.StorageVersion.Version,
+				Resource: config.StorageGroupResource.Resource,
 			}
 		}
+	}
+
+	storage, err := s.factory.NewCollectionResourceStorage(cr)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.storages[cr.Name] = storage
 
-		storage, err := factory.NewCollectionResourceStorage(cr)
-		if err != nil {
-			continue
+	// The list is replaced rather than modified in place,
+	// because the old list may still be being encoded by the List requests.
+	list := &internal.CollectionResourceList{Items: make([]internal.CollectionResource, 0, len(s.list.Items)+1)}
+	var replaced bool
+	for _, item := range s.list.Items {
+		if item.Name == cr.Name {
+			item, replaced = *cr, true
 		}
-		storages[cr.Name] = storage
+		list.Items = append(list.Items, item)
+	}
+	if !replaced {
 		list.Items = append(list.Items, *cr)
 	}
-
-	return &REST{serializer, list, storages}
+	s.list = list
+	return nil
 }
 
 func (s *REST) New() runtime.Object {
@@ -92,6 +199,8 @@ func (s *REST) NamespaceScoped() bool {
 }
 
 func (s *REST) List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	return s.list, nil
 }
 
@@ -104,7 +213,8 @@ func (s *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runt
 
 	if accept := request.AcceptHeaderFrom(ctx); accept != "" {
 		if mediaType, ok := negotiation.NegotiateMediaTypeOptions(accept, s.serializer.SupportedMediaTypes(), negotiation.TableEndpointRestrictions); ok {
-			if target := mediaType.Convert; target != nil && target.Kind == "Table" {
+			// the custom columns may require the fields other than the metadata
+			if target := mediaType.Convert; target != nil && target.Kind == "Table" && len(opts.Columns) == 0 {
 				opts.OnlyMetadata = true
 			}
 		}
@@ -116,17 +226,42 @@ func (s *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runt
 		}
 	}
 
+	s.lock.RLock()
 	storage, ok := s.storages[name]
+	s.lock.RUnlock()
 	if !ok {
 		return nil, apierrors.NewNotFound(
 			schema.GroupResource{Group: internal.GroupName, Resource: "collectionresources"},
 			name,
 		)
 	}
-	return storage.Get(ctx, &opts)
+
+	gvr := v1beta1.SchemeGroupVersion.WithResource("collectionresources")
+	defer metrics.ObserveStorage(gvr, "get", time.Now())
+
+	collection, err := storage.Get(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ObserveReturnedObjects(gvr, "get", len(collection.Items))
+	return collection, nil
 }
 
 func (s *REST) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	if collection, ok := object.(*internal.CollectionResource); ok {
+		var opts internal.ListOptions
+		if err := scheme.ParameterCodec.DecodeParameters(request.RequestQueryFrom(ctx), v1beta1.SchemeGroupVersion, &opts); err != nil {
+			return nil, apierrors.NewBadRequest(err.Error())
+		}
+		if len(opts.Columns) != 0 {
+			convertor, err := printers.NewCustomColumnsTableConvertor(opts.Columns)
+			if err != nil {
+				return nil, apierrors.NewBadRequest(err.Error())
+			}
+			return convertor.ConvertToTable(ctx, collection, tableOptions)
+		}
+	}
+
 	resourceColumnDefinition := []metav1.TableColumnDefinition{
 		{Name: "Cluster", Type: "string"},
 		{Name: "Group", Type: "string"},
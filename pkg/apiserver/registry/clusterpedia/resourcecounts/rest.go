@@ -0,0 +1,108 @@
+package resourcecounts
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/scheme"
+	"github.com/clusterpedia-io/api/clusterpedia/v1beta1"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils/request"
+)
+
+// REST counts the resources by the `search.clusterpedia.io/group-by`,
+// the name is the resource in the format of `<resource>[.<version>][.<group>]`,
+// such as `pods`, `deployments.apps` or `foos.v1.example.com`.
+type REST struct {
+	factory       storage.StorageFactory
+	configFactory *storageconfig.StorageConfigFactory
+}
+
+var _ rest.Scoper = &REST{}
+var _ rest.Getter = &REST{}
+var _ rest.Storage = &REST{}
+
+func NewREST(factory storage.StorageFactory) *REST {
+	return &REST{
+		factory:       factory,
+		configFactory: storageconfig.NewStorageConfigFactory(),
+	}
+}
+
+func (s *REST) New() runtime.Object {
+	return &internal.ResourceCounts{}
+}
+
+func (s *REST) Destroy() {
+}
+
+func (s *REST) NamespaceScoped() bool {
+	return false
+}
+
+func (s *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	var opts internal.ListOptions
+	query := request.RequestQueryFrom(ctx)
+	if err := scheme.ParameterCodec.DecodeParameters(query, v1beta1.SchemeGroupVersion, &opts); err != nil {
+		return nil, err
+	}
+
+	gvr := storageconfig.ParseResourceName(name)
+	config, err := s.configFactory.NewConfig(gvr, false)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource %q: %v", name, err))
+	}
+	if config.StorageVersion.Version == "" {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource %q: the version is required, such as `<resource>.<version>.<group>`", name))
+	}
+
+	resourceStorage, err := s.factory.NewResourceStorage(config)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	counter, ok := resourceStorage.(storage.ResourceCounter)
+	if !ok {
+		return nil, apierrors.NewMethodNotSupported(schema.GroupResource{Group: internal.GroupName, Resource: "resourcecounts"}, "get")
+	}
+
+	counts, err := counter.Count(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+	counts.Name = name
+	return counts, nil
+}
+
+func (s *REST) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	counts, ok := object.(*internal.ResourceCounts)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", object)
+	}
+
+	table := &metav1.Table{}
+	for _, groupBy := range counts.GroupBy {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{Name: groupBy, Type: "string"})
+	}
+	table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{Name: "Count", Type: "integer"})
+
+	for _, item := range counts.Items {
+		cells := make([]interface{}, 0, len(counts.GroupBy)+1)
+		for _, groupBy := range counts.GroupBy {
+			value, ok := item.Values[groupBy]
+			if !ok {
+				value = "<none>"
+			}
+			cells = append(cells, value)
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{Cells: append(cells, item.Count)})
+	}
+	return table, nil
+}
@@ -0,0 +1,116 @@
+package resourcerevisions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/scheme"
+	"github.com/clusterpedia-io/api/clusterpedia/v1beta1"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils/request"
+)
+
+// REST lists the recorded versions of a resource object, the name is the resource
+// in the format of `<resource>[.<version>][.<group>]`, and the object is specified
+// by the `clusters`, `namespaces` and `names` of the url query.
+type REST struct {
+	factory       storage.StorageFactory
+	configFactory *storageconfig.StorageConfigFactory
+}
+
+var _ rest.Scoper = &REST{}
+var _ rest.Getter = &REST{}
+var _ rest.Storage = &REST{}
+
+func NewREST(factory storage.StorageFactory) *REST {
+	return &REST{
+		factory:       factory,
+		configFactory: storageconfig.NewStorageConfigFactory(),
+	}
+}
+
+func (s *REST) New() runtime.Object {
+	return &internal.ResourceRevisions{}
+}
+
+func (s *REST) Destroy() {
+}
+
+func (s *REST) NamespaceScoped() bool {
+	return false
+}
+
+func (s *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	var opts internal.ListOptions
+	query := request.RequestQueryFrom(ctx)
+	if err := scheme.ParameterCodec.DecodeParameters(query, v1beta1.SchemeGroupVersion, &opts); err != nil {
+		return nil, err
+	}
+	if len(opts.ClusterNames) != 1 || len(opts.Names) != 1 || len(opts.Namespaces) > 1 {
+		return nil, apierrors.NewBadRequest("a single cluster and name are required, and the namespace is required for the namespaced resource")
+	}
+	var namespace string
+	if len(opts.Namespaces) == 1 {
+		namespace = opts.Namespaces[0]
+	}
+
+	gvr := storageconfig.ParseResourceName(name)
+	config, err := s.configFactory.NewConfig(gvr, namespace != "")
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource %q: %v", name, err))
+	}
+	if config.StorageVersion.Version == "" {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource %q: the version is required, such as `<resource>.<version>.<group>`", name))
+	}
+
+	resourceStorage, err := s.factory.NewResourceStorage(config)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	history, ok := resourceStorage.(storage.ResourceHistory)
+	if !ok {
+		return nil, apierrors.NewMethodNotSupported(schema.GroupResource{Group: internal.GroupName, Resource: "resourcerevisions"}, "get")
+	}
+
+	revisions, err := history.ListRevisions(ctx, opts.ClusterNames[0], namespace, opts.Names[0])
+	if err != nil {
+		return nil, err
+	}
+	revisions.Name = name
+	return revisions, nil
+}
+
+func (s *REST) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	revisions, ok := object.(*internal.ResourceRevisions)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", object)
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Revision", Type: "integer"},
+			{Name: "Resource Version", Type: "string"},
+			{Name: "Valid From", Type: "string", Format: "date-time"},
+			{Name: "Valid To", Type: "string", Format: "date-time"},
+		},
+	}
+	for _, revision := range revisions.Items {
+		validTo := "<current>"
+		if revision.ValidTo != nil {
+			validTo = revision.ValidTo.UTC().Format(time.RFC3339)
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{revision.Revision, revision.ResourceVersion, revision.ValidFrom.UTC().Format(time.RFC3339), validTo},
+		})
+	}
+	return table, nil
+}
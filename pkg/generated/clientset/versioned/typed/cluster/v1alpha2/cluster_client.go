@@ -13,6 +13,7 @@ import (
 type ClusterV1alpha2Interface interface {
 	RESTClient() rest.Interface
 	ClusterSyncResourcesGetter
+	CustomCollectionResourcesGetter
 	PediaClustersGetter
 }
 
@@ -25,6 +26,10 @@ func (c *ClusterV1alpha2Client) ClusterSyncResources() ClusterSyncResourcesInter
 	return newClusterSyncResources(c)
 }
 
+func (c *ClusterV1alpha2Client) CustomCollectionResources() CustomCollectionResourceInterface {
+	return newCustomCollectionResources(c)
+}
+
 func (c *ClusterV1alpha2Client) PediaClusters() PediaClusterInterface {
 	return newPediaClusters(c)
 }
@@ -16,6 +16,10 @@ func (c *FakeClusterV1alpha2) ClusterSyncResources() v1alpha2.ClusterSyncResourc
 	return &FakeClusterSyncResources{c}
 }
 
+func (c *FakeClusterV1alpha2) CustomCollectionResources() v1alpha2.CustomCollectionResourceInterface {
+	return &FakeCustomCollectionResources{c}
+}
+
 func (c *FakeClusterV1alpha2) PediaClusters() v1alpha2.PediaClusterInterface {
 	return &FakePediaClusters{c}
 }
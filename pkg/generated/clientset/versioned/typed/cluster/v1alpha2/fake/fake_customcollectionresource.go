@@ -0,0 +1,106 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeCustomCollectionResources implements CustomCollectionResourceInterface
+type FakeCustomCollectionResources struct {
+	Fake *FakeClusterV1alpha2
+}
+
+var customcollectionresourcesResource = schema.GroupVersionResource{Group: "cluster.clusterpedia.io", Version: "v1alpha2", Resource: "customcollectionresources"}
+
+var customcollectionresourcesKind = schema.GroupVersionKind{Group: "cluster.clusterpedia.io", Version: "v1alpha2", Kind: "CustomCollectionResource"}
+
+// Get takes name of the customCollectionResource, and returns the corresponding customCollectionResource object, and an error if there is any.
+func (c *FakeCustomCollectionResources) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha2.CustomCollectionResource, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(customcollectionresourcesResource, name), &v1alpha2.CustomCollectionResource{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.CustomCollectionResource), err
+}
+
+// List takes label and field selectors, and returns the list of CustomCollectionResources that match those selectors.
+func (c *FakeCustomCollectionResources) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha2.CustomCollectionResourceList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(customcollectionresourcesResource, customcollectionresourcesKind, opts), &v1alpha2.CustomCollectionResourceList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha2.CustomCollectionResourceList{ListMeta: obj.(*v1alpha2.CustomCollectionResourceList).ListMeta}
+	for _, item := range obj.(*v1alpha2.CustomCollectionResourceList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested customCollectionResources.
+func (c *FakeCustomCollectionResources) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(customcollectionresourcesResource, opts))
+}
+
+// Create takes the representation of a customCollectionResource and creates it.  Returns the server's representation of the customCollectionResource, and an error, if there is any.
+func (c *FakeCustomCollectionResources) Create(ctx context.Context, customCollectionResource *v1alpha2.CustomCollectionResource, opts v1.CreateOptions) (result *v1alpha2.CustomCollectionResource, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(customcollectionresourcesResource, customCollectionResource), &v1alpha2.CustomCollectionResource{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.CustomCollectionResource), err
+}
+
+// Update takes the representation of a customCollectionResource and updates it. Returns the server's representation of the customCollectionResource, and an error, if there is any.
+func (c *FakeCustomCollectionResources) Update(ctx context.Context, customCollectionResource *v1alpha2.CustomCollectionResource, opts v1.UpdateOptions) (result *v1alpha2.CustomCollectionResource, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(customcollectionresourcesResource, customCollectionResource), &v1alpha2.CustomCollectionResource{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.CustomCollectionResource), err
+}
+
+// Delete takes name of the customCollectionResource and deletes it. Returns an error if one occurs.
+func (c *FakeCustomCollectionResources) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteActionWithOptions(customcollectionresourcesResource, name, opts), &v1alpha2.CustomCollectionResource{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeCustomCollectionResources) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(customcollectionresourcesResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha2.CustomCollectionResourceList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched customCollectionResource.
+func (c *FakeCustomCollectionResources) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.CustomCollectionResource, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(customcollectionresourcesResource, name, pt, data, subresources...), &v1alpha2.CustomCollectionResource{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.CustomCollectionResource), err
+}
@@ -4,4 +4,6 @@ package v1alpha2
 
 type ClusterSyncResourcesExpansion interface{}
 
+type CustomCollectionResourceExpansion interface{}
+
 type PediaClusterExpansion interface{}
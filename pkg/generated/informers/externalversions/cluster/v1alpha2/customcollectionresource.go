@@ -0,0 +1,73 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"context"
+	time "time"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	versioned "github.com/clusterpedia-io/clusterpedia/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/clusterpedia-io/clusterpedia/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha2 "github.com/clusterpedia-io/clusterpedia/pkg/generated/listers/cluster/v1alpha2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// CustomCollectionResourceInformer provides access to a shared informer and lister for
+// CustomCollectionResources.
+type CustomCollectionResourceInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha2.CustomCollectionResourceLister
+}
+
+type customCollectionResourceInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewCustomCollectionResourceInformer constructs a new informer for CustomCollectionResource type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewCustomCollectionResourceInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredCustomCollectionResourceInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredCustomCollectionResourceInformer constructs a new informer for CustomCollectionResource type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredCustomCollectionResourceInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ClusterV1alpha2().CustomCollectionResources().List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ClusterV1alpha2().CustomCollectionResources().Watch(context.TODO(), options)
+			},
+		},
+		&clusterv1alpha2.CustomCollectionResource{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *customCollectionResourceInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredCustomCollectionResourceInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *customCollectionResourceInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&clusterv1alpha2.CustomCollectionResource{}, f.defaultInformer)
+}
+
+func (f *customCollectionResourceInformer) Lister() v1alpha2.CustomCollectionResourceLister {
+	return v1alpha2.NewCustomCollectionResourceLister(f.Informer().GetIndexer())
+}
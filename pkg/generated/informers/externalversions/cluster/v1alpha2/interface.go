@@ -10,6 +10,8 @@ import (
 type Interface interface {
 	// ClusterSyncResources returns a ClusterSyncResourcesInformer.
 	ClusterSyncResources() ClusterSyncResourcesInformer
+	// CustomCollectionResources returns a CustomCollectionResourceInformer.
+	CustomCollectionResources() CustomCollectionResourceInformer
 	// PediaClusters returns a PediaClusterInformer.
 	PediaClusters() PediaClusterInformer
 }
@@ -30,6 +32,11 @@ func (v *version) ClusterSyncResources() ClusterSyncResourcesInformer {
 	return &clusterSyncResourcesInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// CustomCollectionResources returns a CustomCollectionResourceInformer.
+func (v *version) CustomCollectionResources() CustomCollectionResourceInformer {
+	return &customCollectionResourceInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // PediaClusters returns a PediaClusterInformer.
 func (v *version) PediaClusters() PediaClusterInformer {
 	return &pediaClusterInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
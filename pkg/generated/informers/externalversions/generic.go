@@ -40,6 +40,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=cluster.clusterpedia.io, Version=v1alpha2
 	case v1alpha2.SchemeGroupVersion.WithResource("clustersyncresources"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cluster().V1alpha2().ClusterSyncResources().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("customcollectionresources"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cluster().V1alpha2().CustomCollectionResources().Informer()}, nil
 	case v1alpha2.SchemeGroupVersion.WithResource("pediaclusters"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cluster().V1alpha2().PediaClusters().Informer()}, nil
 
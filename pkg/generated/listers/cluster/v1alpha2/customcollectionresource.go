@@ -0,0 +1,52 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	v1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CustomCollectionResourceLister helps list CustomCollectionResources.
+// All objects returned here must be treated as read-only.
+type CustomCollectionResourceLister interface {
+	// List lists all CustomCollectionResources in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha2.CustomCollectionResource, err error)
+	// Get retrieves the CustomCollectionResource from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha2.CustomCollectionResource, error)
+	CustomCollectionResourceListerExpansion
+}
+
+// customCollectionResourceLister implements the CustomCollectionResourceLister interface.
+type customCollectionResourceLister struct {
+	indexer cache.Indexer
+}
+
+// NewCustomCollectionResourceLister returns a new CustomCollectionResourceLister.
+func NewCustomCollectionResourceLister(indexer cache.Indexer) CustomCollectionResourceLister {
+	return &customCollectionResourceLister{indexer: indexer}
+}
+
+// List lists all CustomCollectionResources in the indexer.
+func (s *customCollectionResourceLister) List(selector labels.Selector) (ret []*v1alpha2.CustomCollectionResource, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha2.CustomCollectionResource))
+	})
+	return ret, err
+}
+
+// Get retrieves the CustomCollectionResource from the index for a given name.
+func (s *customCollectionResourceLister) Get(name string) (*v1alpha2.CustomCollectionResource, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha2.Resource("customcollectionresource"), name)
+	}
+	return obj.(*v1alpha2.CustomCollectionResource), nil
+}
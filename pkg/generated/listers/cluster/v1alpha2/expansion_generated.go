@@ -6,6 +6,10 @@ package v1alpha2
 // ClusterSyncResourcesLister.
 type ClusterSyncResourcesListerExpansion interface{}
 
+// CustomCollectionResourceListerExpansion allows custom methods to be added to
+// CustomCollectionResourceLister.
+type CustomCollectionResourceListerExpansion interface{}
+
 // PediaClusterListerExpansion allows custom methods to be added to
 // PediaClusterLister.
 type PediaClusterListerExpansion interface{}
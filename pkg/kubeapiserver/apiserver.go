@@ -134,6 +134,12 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 	genericserver.Handler.NonGoRestfulMux.HandlePrefix("/apis/", resourceHandler)
 
 	_ = NewClusterResourceController(restManager, discoveryManager, c.ExtraConfig.InformerFactory.Cluster().V1alpha2().PediaClusters())
+
+	printerColumnsController := NewPrinterColumnsController(restManager, c.ExtraConfig.InformerFactory.Cluster().V1alpha2().PediaClusters())
+	genericserver.AddPostStartHookOrDie("start-printer-columns-controller", func(context genericapiserver.PostStartHookContext) error {
+		go printerColumnsController.Run(context.StopCh)
+		return nil
+	})
 	return genericserver, nil
 }
 
@@ -0,0 +1,201 @@
+package kubeapiserver
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientdiscovery "k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/discovery/controller"
+	clusterinformer "github.com/clusterpedia-io/clusterpedia/pkg/generated/informers/externalversions/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+const crdVersionRetryInterval = 30 * time.Second
+
+// PrinterColumnsController watches the CRDs of the clusters with the CRD controller used by the cluster synchro,
+// and sets the additional printer columns of the custom resources to the RESTManager,
+// so the custom resources are displayed with their columns without synchronizing the CRDs.
+//
+// The clusters in the `Agent` mode are not reachable by clusterpedia, their CRDs are not watched.
+type PrinterColumnsController struct {
+	informer    clusterinformer.PediaClusterInformer
+	restManager *RESTManager
+
+	lock     sync.Mutex
+	stopCh   <-chan struct{}
+	watchers map[string]*crdWatcher
+}
+
+type crdWatcher struct {
+	connection clusterv1alpha2.ClusterSpec
+	stopCh     chan struct{}
+}
+
+func NewPrinterColumnsController(restManager *RESTManager, informer clusterinformer.PediaClusterInformer) *PrinterColumnsController {
+	return &PrinterColumnsController{
+		informer:    informer,
+		restManager: restManager,
+		watchers:    make(map[string]*crdWatcher),
+	}
+}
+
+func (c *PrinterColumnsController) Run(stopCh <-chan struct{}) {
+	c.lock.Lock()
+	c.stopCh = stopCh
+	c.lock.Unlock()
+
+	c.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.updateCluster(obj.(*clusterv1alpha2.PediaCluster))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.updateCluster(obj.(*clusterv1alpha2.PediaCluster))
+		},
+		DeleteFunc: func(obj interface{}) {
+			clusterName, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				return
+			}
+
+			c.lock.Lock()
+			defer c.lock.Unlock()
+			c.removeClusterLocked(clusterName)
+		},
+	})
+
+	<-stopCh
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for name := range c.watchers {
+		c.removeClusterLocked(name)
+	}
+}
+
+func (c *PrinterColumnsController) updateCluster(cluster *clusterv1alpha2.PediaCluster) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if cluster.Spec.Mode == clusterv1alpha2.ClusterSyncModeAgent || !cluster.DeletionTimestamp.IsZero() {
+		c.removeClusterLocked(cluster.Name)
+		return
+	}
+
+	select {
+	case <-c.stopCh:
+		return
+	default:
+	}
+
+	connection := clusterConnection(cluster)
+	if watcher, ok := c.watchers[cluster.Name]; ok && reflect.DeepEqual(watcher.connection, connection) {
+		return
+	}
+	c.removeClusterLocked(cluster.Name)
+
+	config, err := utils.BuildClusterConfig(cluster)
+	if err != nil {
+		klog.ErrorS(err, "Failed to build cluster config", "cluster", cluster.Name)
+		return
+	}
+
+	watcher := &crdWatcher{connection: connection, stopCh: make(chan struct{})}
+	c.watchers[cluster.Name] = watcher
+	go c.watch(cluster.Name, config, watcher.stopCh)
+}
+
+func (c *PrinterColumnsController) removeClusterLocked(name string) {
+	watcher, ok := c.watchers[name]
+	if !ok {
+		return
+	}
+
+	close(watcher.stopCh)
+	delete(c.watchers, name)
+	c.restManager.RemoveClusterPrinterColumns(name)
+}
+
+func (c *PrinterColumnsController) watch(cluster string, config *rest.Config, stopCh <-chan struct{}) {
+	var version string
+	_ = wait.PollImmediateUntil(crdVersionRetryInterval, func() (bool, error) {
+		var err error
+		if version, err = getCRDVersion(config); err != nil {
+			klog.ErrorS(err, "Failed to get the CRD version", "cluster", cluster)
+			return false, nil
+		}
+		return true, nil
+	}, stopCh)
+	if version == "" {
+		return
+	}
+
+	crdController, err := controller.NewCRDController(cluster, config, version)
+	if err != nil {
+		klog.ErrorS(err, "Failed to create the CRD controller", "cluster", cluster)
+		return
+	}
+	crdController.AddEventHandler(controller.CRDEventHandlerFuncs{
+		AddFunc: func(crd *apiextensionsv1.CustomResourceDefinition) {
+			c.handleCRD(stopCh, func() { c.restManager.SetCustomResourcePrinterColumns(cluster, crd) })
+		},
+		UpdateFuncOnlyNewer: func(crd *apiextensionsv1.CustomResourceDefinition) {
+			c.handleCRD(stopCh, func() { c.restManager.SetCustomResourcePrinterColumns(cluster, crd) })
+		},
+		DeleteFunc: func(crd *apiextensionsv1.CustomResourceDefinition) {
+			c.handleCRD(stopCh, func() { c.restManager.RemoveCustomResourcePrinterColumns(cluster, crd) })
+		},
+	})
+	<-crdController.Start(stopCh)
+}
+
+// handleCRD handles the CRD event unless the watcher is stopped,
+// so the columns of the removed cluster are not set again by the late events.
+func (c *PrinterColumnsController) handleCRD(stopCh <-chan struct{}, handle func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	select {
+	case <-stopCh:
+		return
+	default:
+	}
+	handle()
+}
+
+func clusterConnection(cluster *clusterv1alpha2.PediaCluster) clusterv1alpha2.ClusterSpec {
+	return clusterv1alpha2.ClusterSpec{
+		Kubeconfig: cluster.Spec.Kubeconfig,
+		APIServer:  cluster.Spec.APIServer,
+		TokenData:  cluster.Spec.TokenData,
+		CAData:     cluster.Spec.CAData,
+		CertData:   cluster.Spec.CertData,
+		KeyData:    cluster.Spec.KeyData,
+	}
+}
+
+func getCRDVersion(config *rest.Config) (string, error) {
+	client, err := clientdiscovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+	for _, group := range groups.Groups {
+		if group.Name == apiextensionsv1.GroupName {
+			return group.PreferredVersion.Version, nil
+		}
+	}
+	return "", fmt.Errorf("not match crd version")
+}
@@ -0,0 +1,140 @@
+package printers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/util/jsonpath"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/scheme"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+// CustomColumnsTableConvertor converts the resources to the Table with the custom columns of the request,
+// the cells are evaluated by the JSONPaths on the versioned resources, like `kubectl get -o custom-columns`.
+type CustomColumnsTableConvertor struct {
+	columns []internal.TableColumn
+	paths   []*jsonpath.JSONPath
+}
+
+func NewCustomColumnsTableConvertor(columns []internal.TableColumn) (*CustomColumnsTableConvertor, error) {
+	c := &CustomColumnsTableConvertor{columns: columns}
+	for _, column := range columns {
+		path := jsonpath.New(column.Name).AllowMissingKeys(true)
+		if err := path.Parse(relaxedJSONPathExpression(column.JSONPath)); err != nil {
+			return nil, fmt.Errorf("invalid JSONPath of the column %s: %w", column.Name, err)
+		}
+		c.paths = append(c.paths, path)
+	}
+	return c, nil
+}
+
+// relaxedJSONPathExpression allows the JSONPath without the braces and the leading dot,
+// such as `metadata.name`, `.metadata.name` and `{.metadata.name}`.
+func relaxedJSONPathExpression(path string) string {
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		return path
+	}
+	if !strings.HasPrefix(path, ".") && !strings.HasPrefix(path, "[") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+func (c *CustomColumnsTableConvertor) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	// the legacy resources are the internal versions in memory,
+	// they need to be converted to the requested version to evaluate the JSONPaths.
+	var gv schema.GroupVersion
+	if info, ok := genericapirequest.RequestInfoFrom(ctx); ok && info.IsResourceRequest {
+		gv = schema.GroupVersion{Group: info.APIGroup, Version: info.APIVersion}
+	}
+
+	objs := []runtime.Object{object}
+	if meta.IsListType(object) {
+		var err error
+		if objs, err = meta.ExtractList(object); err != nil {
+			return nil, err
+		}
+	}
+
+	var table metav1.Table
+	buf := &bytes.Buffer{}
+	for _, obj := range objs {
+		content, err := c.unstructuredContent(obj, gv)
+		if err != nil {
+			return nil, err
+		}
+
+		cells := make([]interface{}, 0, len(c.paths)+1)
+		cells = append(cells, utils.ExtractClusterName(obj))
+		for _, path := range c.paths {
+			cells = append(cells, printJSONPathResults(buf, path, content))
+		}
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  cells,
+			Object: runtime.RawExtension{Object: obj},
+		})
+	}
+
+	if m, err := meta.ListAccessor(object); err == nil {
+		table.ResourceVersion = m.GetResourceVersion()
+		table.Continue = m.GetContinue()
+		table.RemainingItemCount = m.GetRemainingItemCount()
+	} else {
+		if m, err := meta.CommonAccessor(object); err == nil {
+			table.ResourceVersion = m.GetResourceVersion()
+		}
+	}
+	if opt, ok := tableOptions.(*metav1.TableOptions); !ok || !opt.NoHeaders {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{
+			Name: "Cluster", Type: "string", Format: "", Description: "The Cluster of resource",
+		})
+		for _, column := range c.columns {
+			table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{
+				Name: column.Name, Type: "string", Description: fmt.Sprintf("Custom column (in JSONPath format): %s", column.JSONPath),
+			})
+		}
+	}
+	return &table, nil
+}
+
+func (c *CustomColumnsTableConvertor) unstructuredContent(obj runtime.Object, gv schema.GroupVersion) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+
+	if !gv.Empty() && scheme.LegacyResourceScheme.IsGroupRegistered(gv.Group) {
+		versioned, err := scheme.LegacyResourceScheme.ConvertToVersion(obj, gv)
+		if err != nil {
+			return nil, err
+		}
+		obj = versioned
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+func printJSONPathResults(buf *bytes.Buffer, path *jsonpath.JSONPath, content map[string]interface{}) string {
+	results, err := path.FindResults(content)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return "<none>"
+	}
+
+	defer buf.Reset()
+	for i, result := range results {
+		if i != 0 {
+			buf.WriteString(",")
+		}
+		if err := path.PrintResults(buf, result); err != nil {
+			return "<none>"
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,82 @@
+package printers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	apicore "k8s.io/kubernetes/pkg/apis/core"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+func TestCustomColumnsTableConvertor(t *testing.T) {
+	columns := []internal.TableColumn{
+		{Name: "NAME", JSONPath: ".metadata.name"},
+		{Name: "PHASE", JSONPath: "status.phase"},
+		{Name: "NODE", JSONPath: "{.spec.nodeName}"},
+		{Name: "CONTAINERS", JSONPath: ".spec.containers[*].name"},
+	}
+	convertor, err := NewCustomColumnsTableConvertor(columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &apicore.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"},
+		Spec: apicore.PodSpec{
+			Containers: []apicore.Container{{Name: "app"}, {Name: "sidecar"}},
+		},
+		Status: apicore.PodStatus{Phase: apicore.PodRunning},
+	}
+	utils.InjectClusterName(pod, "cluster-1")
+	list := &apicore.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "10"}, Items: []apicore.Pod{*pod}}
+
+	ctx := genericapirequest.WithRequestInfo(context.TODO(), &genericapirequest.RequestInfo{
+		IsResourceRequest: true, APIVersion: "v1", Resource: "pods",
+	})
+	table, err := convertor.ConvertToTable(ctx, list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, column := range table.ColumnDefinitions {
+		names = append(names, column.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Cluster", "NAME", "PHASE", "NODE", "CONTAINERS"}) {
+		t.Errorf("unexpected columns: %v", names)
+	}
+	if table.ResourceVersion != "10" || len(table.Rows) != 1 {
+		t.Fatalf("unexpected table: %#v", table)
+	}
+	if cells := table.Rows[0].Cells; !reflect.DeepEqual(cells, []interface{}{"cluster-1", "pod-1", "Running", "<none>", "app sidecar"}) {
+		t.Errorf("unexpected cells: %#v", cells)
+	}
+
+	// the unstructured resources of the collection resource
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "deploy-1"},
+		"status":     map[string]interface{}{"phase": "Unknown"},
+	}}
+	table, err = convertor.ConvertToTable(context.TODO(), obj, &metav1.TableOptions{NoHeaders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 0 || len(table.Rows) != 1 {
+		t.Fatalf("unexpected table: %#v", table)
+	}
+	if cells := table.Rows[0].Cells; !reflect.DeepEqual(cells, []interface{}{"", "deploy-1", "Unknown", "<none>", "<none>"}) {
+		t.Errorf("unexpected cells: %#v", cells)
+	}
+
+	if _, err := NewCustomColumnsTableConvertor([]internal.TableColumn{{Name: "INVALID", JSONPath: ".spec[invalid"}}); err == nil {
+		t.Error("expected the invalid JSONPath to be rejected")
+	}
+}
@@ -0,0 +1,89 @@
+package printers
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/registry/customresource/tableconvertor"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+// CustomResourceTableConvertor converts the custom resources to the table
+// with the additional printer columns of the CRD, the columns are set by `SetPrinterColumns`
+// when the CRDs are changed.
+//
+// If there are no printer columns, the default table convertor is used.
+type CustomResourceTableConvertor struct {
+	defaultConvertor rest.TableConvertor
+
+	current atomic.Value // customResourceColumns
+}
+
+type customResourceColumns struct {
+	columns   []apiextensionsv1.CustomResourceColumnDefinition
+	convertor rest.TableConvertor
+}
+
+func NewCustomResourceTableConvertor(defaultQualifiedResource schema.GroupResource) *CustomResourceTableConvertor {
+	c := &CustomResourceTableConvertor{
+		defaultConvertor: NewDefaultTableConvertor(defaultQualifiedResource),
+	}
+	c.current.Store(customResourceColumns{})
+	return c
+}
+
+// SetPrinterColumns updates the printer columns, if the columns are invalid,
+// the default table convertor is used and the error is returned.
+func (c *CustomResourceTableConvertor) SetPrinterColumns(columns []apiextensionsv1.CustomResourceColumnDefinition) error {
+	current := c.current.Load().(customResourceColumns)
+	if reflect.DeepEqual(current.columns, columns) {
+		return nil
+	}
+
+	if len(columns) == 0 {
+		c.current.Store(customResourceColumns{})
+		return nil
+	}
+
+	convertor, err := tableconvertor.New(columns)
+	if err != nil {
+		c.current.Store(customResourceColumns{columns: columns})
+		return err
+	}
+	c.current.Store(customResourceColumns{columns: columns, convertor: convertor})
+	return nil
+}
+
+func (c *CustomResourceTableConvertor) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	convertor := c.current.Load().(customResourceColumns).convertor
+	if convertor == nil {
+		return c.defaultConvertor.ConvertToTable(ctx, object, tableOptions)
+	}
+
+	table, err := convertor.ConvertToTable(ctx, object, tableOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the Cluster column like the ClusterTableGenerator
+	if len(table.ColumnDefinitions) != 0 {
+		table.ColumnDefinitions = append([]metav1.TableColumnDefinition{
+			{Name: "Cluster", Type: "string", Format: "", Description: "The Cluster of resource"},
+		}, table.ColumnDefinitions...)
+	}
+	for i, row := range table.Rows {
+		var cluster string
+		if row.Object.Object != nil {
+			cluster = utils.ExtractClusterName(row.Object.Object)
+		}
+		table.Rows[i].Cells = append([]interface{}{cluster}, row.Cells...)
+	}
+	return table, nil
+}
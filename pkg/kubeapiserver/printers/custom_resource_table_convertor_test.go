@@ -0,0 +1,77 @@
+package printers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+func TestCustomResourceTableConvertor(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.io/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]interface{}{"name": "foo-1"},
+		"spec":       map[string]interface{}{"replicas": int64(3), "image": "nginx"},
+	}}
+	utils.InjectClusterName(obj, "cluster-1")
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*obj}}
+
+	convertor := NewCustomResourceTableConvertor(schema.GroupResource{Group: "example.io", Resource: "foos"})
+	columnNames := func(table *metav1.Table) []string {
+		var names []string
+		for _, column := range table.ColumnDefinitions {
+			names = append(names, column.Name)
+		}
+		return names
+	}
+
+	// without printer columns
+	table, err := convertor.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := columnNames(table); !reflect.DeepEqual(names, []string{"Cluster", "Name", "Created At"}) {
+		t.Errorf("unexpected columns: %v", names)
+	}
+
+	if err := convertor.SetPrinterColumns([]apiextensionsv1.CustomResourceColumnDefinition{
+		{Name: "Replicas", Type: "integer", JSONPath: ".spec.replicas"},
+		{Name: "Image", Type: "string", JSONPath: ".spec.image", Priority: 1},
+		{Name: "Missing", Type: "string", JSONPath: ".status.missing"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	table, err = convertor.ConvertToTable(context.TODO(), list, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := columnNames(table); !reflect.DeepEqual(names, []string{"Cluster", "Name", "Replicas", "Image", "Missing"}) {
+		t.Errorf("unexpected columns: %v", names)
+	}
+	if len(table.Rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(table.Rows))
+	}
+	if cells := table.Rows[0].Cells; !reflect.DeepEqual(cells, []interface{}{"cluster-1", "foo-1", int64(3), "nginx", nil}) {
+		t.Errorf("unexpected cells: %#v", cells)
+	}
+
+	if err := convertor.SetPrinterColumns([]apiextensionsv1.CustomResourceColumnDefinition{
+		{Name: "Invalid", Type: "string", JSONPath: ".spec[invalid"},
+	}); err == nil {
+		t.Error("expected the invalid printer columns to be rejected")
+	}
+	table, err = convertor.ConvertToTable(context.TODO(), obj, &metav1.TableOptions{NoHeaders: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.ColumnDefinitions) != 0 || len(table.Rows) != 1 || len(table.Rows[0].Cells) != 3 {
+		t.Errorf("expected the default table convertor to be used, got %#v", table)
+	}
+}
@@ -3,11 +3,14 @@ package kubeapiserver
 import (
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/endpoints/handlers"
 	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
@@ -16,6 +19,10 @@ import (
 	"k8s.io/klog/v2"
 
 	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/scheme"
+	"github.com/clusterpedia-io/api/clusterpedia/v1beta1"
+	"github.com/clusterpedia-io/clusterpedia/pkg/apiserver/metrics"
 	clusterlister "github.com/clusterpedia-io/clusterpedia/pkg/generated/listers/cluster/v1alpha2"
 	"github.com/clusterpedia-io/clusterpedia/pkg/kubeapiserver/discovery"
 	"github.com/clusterpedia-io/clusterpedia/pkg/utils/request"
@@ -103,18 +110,54 @@ func (r *ResourceHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		case healthyCondition.Status != metav1.ConditionTrue:
 			msg = fmt.Sprintf("%s is not ready and the resources obtained may be inaccurate, reason: %s", clusterName, healthyCondition.Reason)
 		}
-		/*
-			TODO(scyda): Determine the synchronization status of a specific resource
-
-			for _, resource := range c.Status.Resources {
-			}
-		*/
 
 		if msg != "" {
 			warning.AddWarning(req.Context(), "", msg)
 		}
 	}
 
+	// Check the synchronization status of the resource in the requested clusters
+	var options internal.ListOptions
+	if err := scheme.ParameterCodec.DecodeParameters(request.RequestQueryFrom(req.Context()), v1beta1.SchemeGroupVersion, &options); err != nil {
+		responsewriters.ErrorNegotiated(
+			apierrors.NewBadRequest(err.Error()),
+			Codecs, gvr.GroupVersion(), w, req,
+		)
+		return
+	}
+	unsynced, err := r.unsyncedClusters(cluster, options.ClusterNames, gvr)
+	if err != nil {
+		klog.ErrorS(err, "Failed to handle resource request, not list clusters from cache", "resource", gvr)
+		responsewriters.ErrorNegotiated(
+			apierrors.NewInternalError(err),
+			Codecs, gvr.GroupVersion(), w, req,
+		)
+		return
+	}
+	if len(unsynced) != 0 {
+		names := make([]string, 0, len(unsynced))
+		for _, c := range unsynced {
+			names = append(names, c.cluster)
+		}
+
+		if options.RequireSynced {
+			responsewriters.ErrorNegotiated(
+				apierrors.NewServiceUnavailable(fmt.Sprintf("%s is not synchronized in the clusters: %s", gvr.GroupResource(), strings.Join(names, ", "))),
+				Codecs, gvr.GroupVersion(), w, req,
+			)
+			return
+		}
+
+		w.Header().Set(UnsyncedClustersHeader, strings.Join(names, ","))
+		for _, c := range unsynced {
+			msg := fmt.Sprintf("%s in %s is %s and the resources obtained may be incomplete", gvr.GroupResource(), c.cluster, c.condition.Status)
+			if c.condition.Reason != "" {
+				msg += ", reason: " + c.condition.Reason
+			}
+			warning.AddWarning(req.Context(), "", msg)
+		}
+	}
+
 	var handler http.Handler
 	switch requestInfo.Verb {
 	case "get":
@@ -139,6 +182,82 @@ func (r *ResourceHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 
 	if handler != nil {
+		// the watch is long-running, its latency is not recorded
+		if requestInfo.Verb != "watch" {
+			defer metrics.ObserveRequest(gvr, requestInfo.Verb, time.Now())
+		}
 		handler.ServeHTTP(w, req)
 	}
 }
+
+// UnsyncedClustersHeader is the response header that lists the clusters
+// in which the requested resource is not synchronized normally.
+const UnsyncedClustersHeader = "X-Clusterpedia-Unsynced-Clusters"
+
+type unsyncedCluster struct {
+	cluster   string
+	condition *clusterv1alpha2.ClusterResourceSyncCondition
+}
+
+// unsyncedClusters returns the clusters in which the synchronization of the resource is pending, stopped or failed.
+// If the cluster is not specified by the path, the clusters are filtered by the `clusterNames`,
+// and all clusters are checked when the `clusterNames` is empty.
+func (r *ResourceHandler) unsyncedClusters(cluster *clusterv1alpha2.PediaCluster, clusterNames []string, gvr schema.GroupVersionResource) ([]unsyncedCluster, error) {
+	var clusters []*clusterv1alpha2.PediaCluster
+	switch {
+	case cluster != nil:
+		clusters = []*clusterv1alpha2.PediaCluster{cluster}
+	case len(clusterNames) != 0:
+		for _, name := range clusterNames {
+			cluster, err := r.clusterLister.Get(name)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			clusters = append(clusters, cluster)
+		}
+	default:
+		var err error
+		if clusters, err = r.clusterLister.List(labels.Everything()); err != nil {
+			return nil, err
+		}
+	}
+
+	var unsynced []unsyncedCluster
+	for _, cluster := range clusters {
+		condition := findResourceSyncCondition(cluster.Status.SyncResources, gvr)
+		if condition == nil {
+			continue
+		}
+
+		switch condition.Status {
+		case clusterv1alpha2.ResourceSyncStatusPending, clusterv1alpha2.ResourceSyncStatusError, clusterv1alpha2.ResourceSyncStatusStop:
+			unsynced = append(unsynced, unsyncedCluster{cluster: cluster.Name, condition: condition})
+		}
+	}
+	sort.Slice(unsynced, func(i, j int) bool {
+		return unsynced[i].cluster < unsynced[j].cluster
+	})
+	return unsynced, nil
+}
+
+func findResourceSyncCondition(groups []clusterv1alpha2.ClusterGroupResourcesStatus, gvr schema.GroupVersionResource) *clusterv1alpha2.ClusterResourceSyncCondition {
+	for _, group := range groups {
+		if group.Group != gvr.Group {
+			continue
+		}
+		for _, resource := range group.Resources {
+			if resource.Name != gvr.Resource {
+				continue
+			}
+			for i := range resource.SyncConditions {
+				if resource.SyncConditions[i].Version == gvr.Version {
+					return &resource.SyncConditions[i]
+				}
+			}
+		}
+	}
+	return nil
+}
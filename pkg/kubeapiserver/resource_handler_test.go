@@ -0,0 +1,92 @@
+package kubeapiserver
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	clusterlister "github.com/clusterpedia-io/clusterpedia/pkg/generated/listers/cluster/v1alpha2"
+)
+
+func newCluster(name string, status string) *clusterv1alpha2.PediaCluster {
+	return &clusterv1alpha2.PediaCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: clusterv1alpha2.ClusterStatus{
+			SyncResources: []clusterv1alpha2.ClusterGroupResourcesStatus{{
+				Group: "apps",
+				Resources: []clusterv1alpha2.ClusterResourceStatus{{
+					Name: "deployments",
+					SyncConditions: []clusterv1alpha2.ClusterResourceSyncCondition{
+						{Version: "v1", Status: status, Reason: "Test"},
+					},
+				}},
+			}},
+		},
+	}
+}
+
+func TestUnsyncedClusters(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, cluster := range []*clusterv1alpha2.PediaCluster{
+		newCluster("cluster-1", clusterv1alpha2.ResourceSyncStatusSyncing),
+		newCluster("cluster-2", clusterv1alpha2.ResourceSyncStatusError),
+		newCluster("cluster-3", clusterv1alpha2.ResourceSyncStatusPending),
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster-4"}},
+	} {
+		if err := indexer.Add(cluster); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := &ResourceHandler{clusterLister: clusterlister.NewPediaClusterLister(indexer)}
+
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	tests := []struct {
+		name         string
+		cluster      *clusterv1alpha2.PediaCluster
+		clusterNames []string
+		gvr          schema.GroupVersionResource
+		expected     []string
+	}{
+		{
+			name:     "all clusters",
+			gvr:      deployments,
+			expected: []string{"cluster-2", "cluster-3"},
+		},
+		{
+			name:         "filter by cluster names",
+			clusterNames: []string{"cluster-1", "cluster-3", "cluster-5"},
+			gvr:          deployments,
+			expected:     []string{"cluster-3"},
+		},
+		{
+			name:     "cluster of the path",
+			cluster:  newCluster("cluster-6", clusterv1alpha2.ResourceSyncStatusStop),
+			gvr:      deployments,
+			expected: []string{"cluster-6"},
+		},
+		{
+			name: "other version",
+			gvr:  deployments.GroupResource().WithVersion("v1beta2"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unsynced, err := handler.unsyncedClusters(test.cluster, test.clusterNames, test.gvr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var clusters []string
+			for _, c := range unsynced {
+				clusters = append(clusters, c.cluster)
+			}
+			if !reflect.DeepEqual(clusters, test.expected) {
+				t.Errorf("expected unsynced clusters %v, got %v", test.expected, clusters)
+			}
+		})
+	}
+}
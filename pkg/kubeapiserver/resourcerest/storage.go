@@ -3,8 +3,10 @@ package resourcerest
 import (
 	"context"
 	"errors"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,6 +21,7 @@ import (
 	internal "github.com/clusterpedia-io/api/clusterpedia"
 	"github.com/clusterpedia-io/api/clusterpedia/scheme"
 	"github.com/clusterpedia-io/api/clusterpedia/v1beta1"
+	"github.com/clusterpedia-io/clusterpedia/pkg/apiserver/metrics"
 	"github.com/clusterpedia-io/clusterpedia/pkg/kubeapiserver/printers"
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
 	"github.com/clusterpedia-io/clusterpedia/pkg/utils/negotiation"
@@ -60,13 +63,55 @@ func (s *RESTStorage) Get(ctx context.Context, name string, _ *metav1.GetOptions
 		return nil, errors.New("missing RequestInfo")
 	}
 
+	var options internal.ListOptions
+	if err := scheme.ParameterCodec.DecodeParameters(request.RequestQueryFrom(ctx), v1beta1.SchemeGroupVersion, &options); err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	gvr := s.DefaultQualifiedResource.WithVersion(requestInfo.APIVersion)
+	defer metrics.ObserveStorage(gvr, "get", time.Now())
+
+	if options.AsOf != nil && len(options.RelatedResources) != 0 {
+		return nil, apierrors.NewBadRequest("with-related is not supported with as-of")
+	}
+
 	obj := s.New()
+	if options.AsOf != nil {
+		history, ok := s.Storage.(storage.ResourceHistory)
+		if !ok {
+			return nil, apierrors.NewBadRequest("as-of is not supported by the storage layer")
+		}
+		if err := history.GetAsOf(ctx, clusterName, requestInfo.Namespace, name, options.AsOf.Time, obj); err != nil {
+			if apierrors.IsMethodNotSupported(err) {
+				return nil, apierrors.NewBadRequest("as-of is not supported, the history of the resources is not enabled")
+			}
+			return nil, storeerr.InterpretGetError(err, s.DefaultQualifiedResource, name)
+		}
+		return obj, nil
+	}
+
 	if err := s.Storage.Get(ctx, clusterName, requestInfo.Namespace, name, obj); err != nil {
 		return nil, storeerr.InterpretGetError(err, s.DefaultQualifiedResource, name)
 	}
+	if err := s.resolveRelatedResources(ctx, obj, &options); err != nil {
+		return nil, storeerr.InterpretGetError(err, s.DefaultQualifiedResource, name)
+	}
 	return obj, nil
 }
 
+// resolveRelatedResources attaches the related resources of the options to the object or list
+func (s *RESTStorage) resolveRelatedResources(ctx context.Context, obj runtime.Object, options *internal.ListOptions) error {
+	if len(options.RelatedResources) == 0 {
+		return nil
+	}
+
+	resolver, ok := s.Storage.(storage.ResourceRelatedResolver)
+	if !ok {
+		return apierrors.NewBadRequest("with-related is not supported by the storage layer")
+	}
+	return resolver.ResolveRelatedResources(ctx, obj, options)
+}
+
 func (s *RESTStorage) resolveListOptions(ctx context.Context) (*internal.ListOptions, error) {
 	options := &internal.ListOptions{}
 	query := request.RequestQueryFrom(ctx)
@@ -87,10 +132,6 @@ func (s *RESTStorage) resolveListOptions(ctx context.Context) (*internal.ListOpt
 		options.ClusterNames = []string{cluster}
 	}
 
-	if (options.OwnerUID != "" || options.OwnerName != "") && len(options.ClusterNames) != 1 {
-		return nil, apierrors.NewBadRequest("If searching by owner uid or name, then the cluster must be specified")
-	}
-
 	if options.WithRemainingCount == nil {
 		if enabled := utilfeature.DefaultFeatureGate.Enabled(genericfeatures.RemainingItemCount); enabled {
 			options.WithRemainingCount = &enabled
@@ -113,10 +154,28 @@ func (s *RESTStorage) List(ctx context.Context, _ *metainternalversion.ListOptio
 		return nil, err
 	}
 
+	if _, ok := s.Storage.(storage.ResourceHistory); options.AsOf != nil && !ok {
+		return nil, apierrors.NewBadRequest("as-of is not supported by the storage layer")
+	}
+	if options.AsOf != nil && len(options.RelatedResources) != 0 {
+		return nil, apierrors.NewBadRequest("with-related is not supported with as-of")
+	}
+
+	gvr := s.DefaultQualifiedResource.WithVersion("")
+	if requestInfo, ok := genericrequest.RequestInfoFrom(ctx); ok {
+		gvr.Version = requestInfo.APIVersion
+	}
+
+	defer metrics.ObserveStorage(gvr, "list", time.Now())
+
 	objs := s.NewList()
 	if err := s.Storage.List(ctx, objs, options); err != nil {
 		return nil, storeerr.InterpretListError(err, s.DefaultQualifiedResource)
 	}
+	if err := s.resolveRelatedResources(ctx, objs, options); err != nil {
+		return nil, storeerr.InterpretListError(err, s.DefaultQualifiedResource)
+	}
+	metrics.ObserveReturnedObjects(gvr, "list", meta.LenList(objs))
 	return objs, nil
 }
 
@@ -127,13 +186,28 @@ func (s *RESTStorage) Watch(ctx context.Context, _ *metainternalversion.ListOpti
 	}
 
 	inter, err := s.Storage.Watch(ctx, options)
-	if apierrors.IsMethodNotSupported(err) {
-		return nil, apierrors.NewMethodNotSupported(s.DefaultQualifiedResource, "watch")
+	if err != nil {
+		if apierrors.IsMethodNotSupported(err) {
+			return nil, apierrors.NewMethodNotSupported(s.DefaultQualifiedResource, "watch")
+		}
+		return nil, storeerr.InterpretWatchError(err, s.DefaultQualifiedResource, "")
 	}
 	return inter, nil
 }
 
 func (s *RESTStorage) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	var options internal.ListOptions
+	if err := scheme.ParameterCodec.DecodeParameters(request.RequestQueryFrom(ctx), v1beta1.SchemeGroupVersion, &options); err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	if len(options.Columns) != 0 {
+		convertor, err := printers.NewCustomColumnsTableConvertor(options.Columns)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(err.Error())
+		}
+		return convertor.ConvertToTable(ctx, object, tableOptions)
+	}
+
 	if s.TableConvertor != nil {
 		return s.TableConvertor.ConvertToTable(ctx, object, tableOptions)
 	}
@@ -1,10 +1,12 @@
 package kubeapiserver
 
 import (
+	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -44,6 +46,10 @@ type RESTManager struct {
 
 	restResourceInfos atomic.Value // map[schema.GroupVersionResource]RESTResourceInfo
 
+	// the additional printer columns of the served custom resource versions in the clusters,
+	// cluster -> group resource -> version -> columns, it is guarded by the lock
+	printerColumns map[string]map[schema.GroupResource]map[string][]apiextensionsv1.CustomResourceColumnDefinition
+
 	requestVerbs metav1.Verbs
 }
 
@@ -87,6 +93,7 @@ func NewRESTManager(serializer runtime.NegotiatedSerializer, storageMediaType st
 		storageFactory:             storageFactory,
 		resourcetSorageConfig:      storageconfig.NewStorageConfigFactory(),
 		equivalentResourceRegistry: runtime.NewEquivalentResourceRegistry(),
+		printerColumns:             make(map[string]map[schema.GroupResource]map[string][]apiextensionsv1.CustomResourceColumnDefinition),
 		requestVerbs:               requestVerbs,
 	}
 
@@ -184,6 +191,96 @@ func (m *RESTManager) LoadResources(infos ResourceInfoMap) map[schema.GroupResou
 	return apis
 }
 
+// SetCustomResourcePrinterColumns records the additional printer columns of the CRD in the cluster,
+// and updates the table convertors of the custom resource.
+func (m *RESTManager) SetCustomResourcePrinterColumns(cluster string, crd *apiextensionsv1.CustomResourceDefinition) {
+	gr := schema.GroupResource{Group: crd.Spec.Group, Resource: crd.Spec.Names.Plural}
+	versions := make(map[string][]apiextensionsv1.CustomResourceColumnDefinition)
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			versions[version.Name] = version.AdditionalPrinterColumns
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	resources := m.printerColumns[cluster]
+	if resources == nil {
+		resources = make(map[schema.GroupResource]map[string][]apiextensionsv1.CustomResourceColumnDefinition)
+		m.printerColumns[cluster] = resources
+	}
+	if reflect.DeepEqual(resources[gr], versions) {
+		return
+	}
+	resources[gr] = versions
+	m.updatePrinterColumnsLocked(gr)
+}
+
+// RemoveCustomResourcePrinterColumns removes the additional printer columns of the CRD in the cluster.
+func (m *RESTManager) RemoveCustomResourcePrinterColumns(cluster string, crd *apiextensionsv1.CustomResourceDefinition) {
+	gr := schema.GroupResource{Group: crd.Spec.Group, Resource: crd.Spec.Names.Plural}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.printerColumns[cluster][gr]; !ok {
+		return
+	}
+	delete(m.printerColumns[cluster], gr)
+	m.updatePrinterColumnsLocked(gr)
+}
+
+// RemoveClusterPrinterColumns removes the additional printer columns of all CRDs in the cluster.
+func (m *RESTManager) RemoveClusterPrinterColumns(cluster string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	resources, ok := m.printerColumns[cluster]
+	if !ok {
+		return
+	}
+	delete(m.printerColumns, cluster)
+	for gr := range resources {
+		m.updatePrinterColumnsLocked(gr)
+	}
+}
+
+// getPrinterColumnsLocked returns the additional printer columns of the custom resource version.
+// If the columns are different in the clusters serving the version,
+// no columns are returned and the default table convertor is used.
+func (m *RESTManager) getPrinterColumnsLocked(gvr schema.GroupVersionResource) []apiextensionsv1.CustomResourceColumnDefinition {
+	var columns []apiextensionsv1.CustomResourceColumnDefinition
+	var served bool
+	for _, resources := range m.printerColumns {
+		clusterColumns, ok := resources[gvr.GroupResource()][gvr.Version]
+		if !ok {
+			continue
+		}
+
+		if served && !reflect.DeepEqual(columns, clusterColumns) {
+			return nil
+		}
+		columns, served = clusterColumns, true
+	}
+	return columns
+}
+
+func (m *RESTManager) updatePrinterColumnsLocked(gr schema.GroupResource) {
+	restinfos := m.restResourceInfos.Load().(map[schema.GroupVersionResource]RESTResourceInfo)
+	for gvr, info := range restinfos {
+		if gvr.GroupResource() != gr || info.Storage == nil {
+			continue
+		}
+
+		if convertor, ok := info.Storage.TableConvertor.(*printers.CustomResourceTableConvertor); ok {
+			if err := convertor.SetPrinterColumns(m.getPrinterColumnsLocked(gvr)); err != nil {
+				klog.ErrorS(err, "Failed to set the printer columns", "gvr", gvr)
+			}
+		}
+	}
+}
+
 func (m *RESTManager) addAPIGroupsLocked(addedGroups map[string]metav1.APIGroup) {
 	apigroups := m.groups.Load().(map[string]metav1.APIGroup)
 
@@ -239,7 +336,15 @@ func (m *RESTManager) addRESTResourceInfosLocked(addedInfos map[schema.GroupVers
 			}
 
 			storage.DefaultQualifiedResource = gvr.GroupResource()
-			storage.TableConvertor = GetTableConvertor(gvr.GroupResource())
+			if scheme.LegacyResourceScheme.IsGroupRegistered(gvr.Group) {
+				storage.TableConvertor = GetTableConvertor(gvr.GroupResource())
+			} else {
+				convertor := printers.NewCustomResourceTableConvertor(gvr.GroupResource())
+				if err := convertor.SetPrinterColumns(m.getPrinterColumnsLocked(gvr)); err != nil {
+					klog.ErrorS(err, "Failed to set the printer columns", "gvr", gvr)
+				}
+				storage.TableConvertor = convertor
+			}
 			storage.Serializer = m.serializer
 			info.Storage = storage
 		}
@@ -0,0 +1,113 @@
+package kubeapiserver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+)
+
+type fakeStorageFactory struct {
+	storage.StorageFactory
+}
+
+func (f *fakeStorageFactory) GetSupportedRequestVerbs() []string {
+	return []string{"get", "list"}
+}
+
+func (f *fakeStorageFactory) NewResourceStorage(config *storage.ResourceStorageConfig) (storage.ResourceStorage, error) {
+	return nil, nil
+}
+
+func newTestCRD(served bool, columns ...string) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.io"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    "example.io",
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: "foos", Kind: "Foo"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{Name: "v1", Served: served}},
+		},
+	}
+	for _, column := range columns {
+		crd.Spec.Versions[0].AdditionalPrinterColumns = append(crd.Spec.Versions[0].AdditionalPrinterColumns,
+			apiextensionsv1.CustomResourceColumnDefinition{Name: column, Type: "string", JSONPath: ".spec." + column})
+	}
+	return crd
+}
+
+func TestCustomResourcePrinterColumns(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "foos"}
+	manager := NewRESTManager(nil, "", &fakeStorageFactory{}, nil)
+
+	// the columns set before the resource is loaded are used by the table convertor
+	manager.SetCustomResourcePrinterColumns("cluster-a", newTestCRD(true, "a"))
+	manager.LoadResources(ResourceInfoMap{gvr.GroupResource(): resourceInfo{Namespaced: true, Kind: "Foo", Versions: sets.NewString("v1")}})
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.io/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]interface{}{"name": "foo-1"},
+	}}
+	columnNames := func() []string {
+		table, err := manager.GetRESTResourceInfo(gvr).Storage.ConvertToTable(context.TODO(), obj, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, column := range table.ColumnDefinitions {
+			names = append(names, column.Name)
+		}
+		return names
+	}
+	defaultColumns := []string{"Cluster", "Name", "Created At"}
+
+	steps := []struct {
+		name     string
+		update   func()
+		expected []string
+	}{
+		{
+			"the columns of the cluster",
+			func() {},
+			[]string{"Cluster", "Name", "a"},
+		},
+		{
+			"the version not served is skipped",
+			func() { manager.SetCustomResourcePrinterColumns("cluster-b", newTestCRD(false, "b")) },
+			[]string{"Cluster", "Name", "a"},
+		},
+		{
+			"the same columns in clusters",
+			func() { manager.SetCustomResourcePrinterColumns("cluster-b", newTestCRD(true, "a")) },
+			[]string{"Cluster", "Name", "a"},
+		},
+		{
+			"the different columns in clusters",
+			func() { manager.SetCustomResourcePrinterColumns("cluster-b", newTestCRD(true, "b")) },
+			defaultColumns,
+		},
+		{
+			"the CRD is removed",
+			func() { manager.RemoveCustomResourcePrinterColumns("cluster-a", newTestCRD(true)) },
+			[]string{"Cluster", "Name", "b"},
+		},
+		{
+			"the cluster is removed",
+			func() { manager.RemoveClusterPrinterColumns("cluster-b") },
+			defaultColumns,
+		},
+	}
+	for _, step := range steps {
+		step.update()
+		if names := columnNames(); !reflect.DeepEqual(names, step.expected) {
+			t.Errorf("%s: expected columns %v, got %v", step.name, step.expected, names)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/scheme"
+)
+
+const (
+	CollectionResourceAny           = "any"
+	CollectionResourceWorkloads     = "workloads"
+	CollectionResourceKubeResources = "kuberesources"
+)
+
+const (
+	URLQueryGroups    = "groups"
+	URLQueryResources = "resources"
+)
+
+var collectionResources = []internal.CollectionResource{
+	{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: CollectionResourceAny,
+		},
+		ResourceTypes: []internal.CollectionResourceType{},
+	},
+	{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: CollectionResourceWorkloads,
+		},
+		ResourceTypes: []internal.CollectionResourceType{
+			{
+				Group:    "apps",
+				Resource: "deployments",
+			},
+			{
+				Group:    "apps",
+				Resource: "daemonsets",
+			},
+			{
+				Group:    "apps",
+				Resource: "statefulsets",
+			},
+		},
+	},
+	{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: CollectionResourceKubeResources,
+		},
+	},
+}
+
+func init() {
+	groups := sets.NewString()
+	for _, groupversion := range scheme.LegacyResourceScheme.PreferredVersionAllGroups() {
+		groups.Insert(groupversion.Group)
+	}
+
+	types := make([]internal.CollectionResourceType, 0, len(groups))
+	for _, group := range groups.List() {
+		types = append(types, internal.CollectionResourceType{
+			Group: group,
+		})
+	}
+
+	for i := range collectionResources {
+		if collectionResources[i].Name == CollectionResourceKubeResources {
+			collectionResources[i].ResourceTypes = types
+		}
+	}
+}
+
+// BuiltInCollectionResources returns the copies of the collection resources supported by the storage layers,
+// the *any* collection resource matches the resource types in the `groups` and `resources` url queries.
+func BuiltInCollectionResources() []*internal.CollectionResource {
+	crs := make([]*internal.CollectionResource, 0, len(collectionResources))
+	for _, cr := range collectionResources {
+		crs = append(crs, cr.DeepCopy())
+	}
+	return crs
+}
+
+func IsBuiltInCollectionResource(name string) bool {
+	for i := range collectionResources {
+		if collectionResources[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveGVRsFromURLQuery resolves the resource types from the `groups` and `resources` url queries,
+// all is true if the groups contains `*`.
+func ResolveGVRsFromURLQuery(query url.Values) (gvrs []schema.GroupVersionResource, all bool, err error) {
+	if query.Has(URLQueryGroups) {
+		for _, group := range strings.Split(query.Get(URLQueryGroups), ",") {
+			if group == "*" {
+				return nil, true, nil
+			}
+
+			gv, err := parseGroupVersion(group)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s query: %w", URLQueryGroups, err)
+			}
+
+			gvrs = append(gvrs, gv.WithResource(""))
+		}
+	}
+	if query.Has(URLQueryResources) {
+		for _, resource := range strings.Split(query.Get(URLQueryResources), ",") {
+			gvr, err := parseGroupVersionResource(resource)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s query: %w", URLQueryResources, err)
+			}
+
+			gvrs = append(gvrs, gvr)
+		}
+	}
+	return
+}
+
+func parseGroupVersion(gv string) (schema.GroupVersion, error) {
+	gv = strings.ReplaceAll(gv, " ", "")
+	if (len(gv) == 0) || (gv == "/") {
+		// match legacy group
+		return schema.GroupVersion{}, nil
+	}
+
+	strs := strings.Split(gv, "/")
+	switch len(strs) {
+	case 1:
+		/*
+			match:
+				* "group"
+		*/
+		return schema.GroupVersion{Group: strs[0]}, nil
+	case 2:
+		/*
+			match:
+				* "/"
+				* "group/version"
+				* "/version"
+				* "group/"
+		*/
+		return schema.GroupVersion{Group: strs[0], Version: strs[1]}, nil
+	}
+	return schema.GroupVersion{}, fmt.Errorf("unexpected GroupVersion string: %v, expect <group> or <group>/<version>", gv)
+}
+
+func parseGroupVersionResource(gvr string) (schema.GroupVersionResource, error) {
+	gvr = strings.ReplaceAll(gvr, " ", "")
+	if gvr == "" {
+		return schema.GroupVersionResource{}, fmt.Errorf("unexpected GroupVersionResource string: %v, expect <group>/<resource> or <group>/<version>/<resource>", gvr)
+	}
+
+	strs := strings.Split(gvr, "/")
+	switch len(strs) {
+	case 2:
+		/*
+			match:
+				* "group/resource"
+				* "/resource" in legacy group /api
+			not match:
+				* "/"
+				* "group/"
+		*/
+		if strs[1] != "" {
+			return schema.GroupVersionResource{Group: strs[0], Resource: strs[1]}, nil
+		}
+	case 3:
+		/*
+			match:
+				* "group/version/resource"
+				* "/version/resource"
+				* "//resource"
+				* "group//resource"
+			not match:
+				* "group/version/"
+				* "group//"
+		*/
+		if strs[2] != "" {
+			return schema.GroupVersionResource{Group: strs[0], Version: strs[1], Resource: strs[2]}, nil
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("unexpected GroupVersionResource string: %v, expect <group>/<resource> or <group>/<version>/<resource>", gvr)
+}
@@ -0,0 +1,485 @@
+package grpcstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/jinzhu/configor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+)
+
+const (
+	StorageName = "grpc"
+
+	defaultTimeout = 30 * time.Second
+)
+
+func init() {
+	storage.RegisterStorageFactoryFunc(StorageName, NewStorageFactory)
+}
+
+type Config struct {
+	// Address is the target of the storage plugin, such as `localhost:8080` or `unix:///run/plugin.sock`.
+	Address string `yaml:"address" required:"true"`
+
+	TLS *TLSConfig `yaml:"tls"`
+
+	// Timeout is the timeout of the unary requests, the watch requests are not limited.
+	Timeout time.Duration `yaml:"timeout" default:"30s"`
+}
+
+type TLSConfig struct {
+	CAFile     string `yaml:"caFile"`
+	ServerName string `yaml:"serverName"`
+}
+
+func NewStorageFactory(configPath string) (storage.StorageFactory, error) {
+	if configPath == "" {
+		return nil, fmt.Errorf("the config of the grpc storage is required")
+	}
+
+	cfg := &Config{}
+	if err := configor.Load(cfg, configPath); err != nil {
+		return nil, err
+	}
+	return NewStorageFactoryWithConfig(cfg)
+}
+
+// NewStorageFactoryWithConfig connects to the storage plugin, the protocol version is checked
+// and the supported request verbs are fetched on start, so the plugin must be available.
+func NewStorageFactoryWithConfig(cfg *Config) (storage.StorageFactory, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS != nil {
+		var err error
+		if creds, err = credentials.NewClientTLSFromFile(cfg.TLS.CAFile, cfg.TLS.ServerName); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := grpc.Dial(cfg.Address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	factory := &StorageFactory{client: &client{conn: conn, timeout: timeout}}
+
+	verbs, err := factory.client.handshake(context.TODO())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	factory.verbs = verbs
+	return factory, nil
+}
+
+type client struct {
+	conn    *grpc.ClientConn
+	timeout time.Duration
+}
+
+// call invokes the unary method, the storage error in the response is returned as the error,
+// and the unavailable plugin is a recoverable exception for the synchro.
+func (c *client) call(ctx context.Context, method string, req *Request) (*Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	resp := &Response{}
+	if err := c.conn.Invoke(ctx, fullMethod(method), req, resp); err != nil {
+		if status.Code(err) == codes.Unavailable {
+			return nil, storage.NewRecoverableException(err)
+		}
+		return nil, err
+	}
+	if err := resp.Error.Err(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// handshake checks that the plugin speaks the same protocol version, and returns the supported request verbs.
+func (c *client) handshake(ctx context.Context) ([]string, error) {
+	resp, err := c.call(ctx, MethodHandshake, &Request{ProtocolVersion: ProtocolVersion})
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, fmt.Errorf("the storage plugin does not implement the protocol %s: %w", ProtocolVersion, err)
+		}
+		return nil, fmt.Errorf("failed to handshake with the storage plugin: %w", err)
+	}
+	if resp.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("the storage plugin speaks the protocol version %q, expected %q", resp.ProtocolVersion, ProtocolVersion)
+	}
+	if len(resp.UnsupportedInterfaces) != 0 {
+		klog.InfoS("The optional interfaces of the resource storages are not supported by the storage plugin protocol", "interfaces", resp.UnsupportedInterfaces)
+	}
+	return resp.Verbs, nil
+}
+
+type StorageFactory struct {
+	client *client
+	verbs  []string
+}
+
+var _ storage.StorageFactory = &StorageFactory{}
+
+func (f *StorageFactory) GetSupportedRequestVerbs() []string {
+	return f.verbs
+}
+
+func (f *StorageFactory) PrepareCluster(cluster string) error {
+	_, err := f.client.call(context.TODO(), MethodPrepareCluster, &Request{Cluster: cluster})
+	return err
+}
+
+func (f *StorageFactory) GetResourceVersions(ctx context.Context, cluster string) (map[schema.GroupVersionResource]map[string]interface{}, error) {
+	resp, err := f.client.call(ctx, MethodGetResourceVersions, &Request{Cluster: cluster})
+	if err != nil {
+		return nil, err
+	}
+
+	resourceVersions := make(map[schema.GroupVersionResource]map[string]interface{}, len(resp.ResourceVersions))
+	for _, rvs := range resp.ResourceVersions {
+		versions := make(map[string]interface{}, len(rvs.Versions))
+		for key, rv := range rvs.Versions {
+			versions[key] = rv
+		}
+		resourceVersions[schema.GroupVersionResource(rvs.Resource)] = versions
+	}
+	return resourceVersions, nil
+}
+
+func (f *StorageFactory) GetCollectionResources(ctx context.Context) ([]*internal.CollectionResource, error) {
+	resp, err := f.client.call(ctx, MethodGetCollectionResources, &Request{})
+	if err != nil {
+		return nil, err
+	}
+
+	crs := make([]*internal.CollectionResource, 0, len(resp.CollectionResources))
+	for i := range resp.CollectionResources {
+		cr, err := toInternalCollectionResource(&resp.CollectionResources[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		crs = append(crs, cr)
+	}
+	return crs, nil
+}
+
+func (f *StorageFactory) NewResourceStorage(config *storage.ResourceStorageConfig) (storage.ResourceStorage, error) {
+	rs := &ResourceStorage{
+		client:   f.client,
+		config:   config,
+		resource: metav1.GroupVersionResource(config.StorageGroupResource.WithVersion(config.StorageVersion.Version)),
+	}
+	if err := rs.create(context.TODO()); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (f *StorageFactory) NewCollectionResourceStorage(cr *internal.CollectionResource) (storage.CollectionResourceStorage, error) {
+	collection, err := toCollectionResource(cr)
+	if err != nil {
+		return nil, err
+	}
+	crs := &CollectionResourceStorage{client: f.client, collectionResource: collection}
+	if err := crs.create(context.TODO()); err != nil {
+		return nil, err
+	}
+	return crs, nil
+}
+
+func (f *StorageFactory) CleanCluster(ctx context.Context, cluster string) error {
+	_, err := f.client.call(ctx, MethodCleanCluster, &Request{Cluster: cluster})
+	return err
+}
+
+func (f *StorageFactory) CleanClusterResource(ctx context.Context, cluster string, gvr schema.GroupVersionResource) error {
+	_, err := f.client.call(ctx, MethodCleanClusterResource, &Request{Cluster: cluster, Resource: metav1.GroupVersionResource(gvr)})
+	return err
+}
+
+type ResourceStorage struct {
+	client   *client
+	config   *storage.ResourceStorageConfig
+	resource metav1.GroupVersionResource
+}
+
+var _ storage.ResourceStorage = &ResourceStorage{}
+
+func (s *ResourceStorage) create(ctx context.Context) error {
+	_, err := s.client.call(ctx, MethodNewResourceStorage, &Request{
+		Config: &ResourceStorageConfig{
+			Namespaced:           s.config.Namespaced,
+			GroupResource:        metav1.GroupResource(s.config.GroupResource),
+			StorageGroupResource: metav1.GroupResource(s.config.StorageGroupResource),
+			MemoryVersion:        metav1.GroupVersion(s.config.MemoryVersion),
+			StorageVersion:       metav1.GroupVersion(s.config.StorageVersion),
+		},
+	})
+	return err
+}
+
+// call invokes the method of the resource storage, if the resource storage is not created in the plugin,
+// such as after the plugin is restarted, it is created again and the method is retried once.
+func (s *ResourceStorage) call(ctx context.Context, method string, req *Request) (*Response, error) {
+	req.Resource = s.resource
+	resp, err := s.client.call(ctx, method, req)
+	if status.Code(err) != codes.FailedPrecondition {
+		return resp, err
+	}
+
+	if err := s.create(ctx); err != nil {
+		return nil, err
+	}
+	return s.client.call(ctx, method, req)
+}
+
+func (s *ResourceStorage) GetStorageConfig() *storage.ResourceStorageConfig {
+	return s.config
+}
+
+func (s *ResourceStorage) Get(ctx context.Context, cluster, namespace, name string, into runtime.Object) error {
+	resp, err := s.call(ctx, MethodGet, &Request{Cluster: cluster, Namespace: namespace, Name: name})
+	if err != nil {
+		return err
+	}
+
+	obj, _, err := s.config.Codec.Decode(resp.Object, nil, into)
+	if err != nil {
+		return err
+	}
+	if obj != into {
+		return fmt.Errorf("Failed to decode resource, into is %T", into)
+	}
+	return nil
+}
+
+func (s *ResourceStorage) List(ctx context.Context, listObject runtime.Object, opts *internal.ListOptions) error {
+	values, err := encodeListOptions(opts)
+	if err != nil {
+		return err
+	}
+	resp, err := s.call(ctx, MethodList, &Request{ListOptions: values})
+	if err != nil {
+		return err
+	}
+	if resp.List == nil {
+		return fmt.Errorf("the list of %s is not returned by the storage plugin", s.resource)
+	}
+
+	list, err := meta.ListAccessor(listObject)
+	if err != nil {
+		return err
+	}
+	list.SetResourceVersion(resp.List.ResourceVersion)
+	list.SetContinue(resp.List.Continue)
+	list.SetRemainingItemCount(resp.List.RemainingItemCount)
+
+	listPtr, err := meta.GetItemsPtr(listObject)
+	if err != nil {
+		return err
+	}
+	v, err := conversion.EnforcePtr(listPtr)
+	if err != nil || v.Kind() != reflect.Slice {
+		return fmt.Errorf("need ptr to slice: %v", err)
+	}
+
+	expected := reflect.New(v.Type().Elem()).Interface().(runtime.Object)
+	slice := reflect.MakeSlice(v.Type(), len(resp.List.Items), len(resp.List.Items))
+	for i, data := range resp.List.Items {
+		obj, _, err := s.config.Codec.Decode(data, nil, expected.DeepCopyObject())
+		if err != nil {
+			return err
+		}
+		slice.Index(i).Set(reflect.ValueOf(obj).Elem())
+	}
+	v.Set(slice)
+	return nil
+}
+
+func (s *ResourceStorage) Watch(ctx context.Context, opts *internal.ListOptions) (watch.Interface, error) {
+	values, err := encodeListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := s.client.conn.NewStream(ctx, &serviceDesc.Streams[0], fullMethod(MethodWatch))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := stream.SendMsg(&Request{Resource: s.resource, ListOptions: values}); err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	w := &watcher{codec: s.config.Codec, stream: stream, cancel: cancel, result: make(chan watch.Event)}
+	go w.receive(ctx)
+	return w, nil
+}
+
+func (s *ResourceStorage) write(ctx context.Context, method string, cluster string, obj runtime.Object) error {
+	data, err := runtime.Encode(s.config.Codec, obj)
+	if err != nil {
+		return err
+	}
+	_, err = s.call(ctx, method, &Request{Cluster: cluster, Object: data})
+	return err
+}
+
+func (s *ResourceStorage) Create(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.write(ctx, MethodCreate, cluster, obj)
+}
+
+func (s *ResourceStorage) Update(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.write(ctx, MethodUpdate, cluster, obj)
+}
+
+func (s *ResourceStorage) Upsert(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.write(ctx, MethodUpsert, cluster, obj)
+}
+
+func (s *ResourceStorage) Delete(ctx context.Context, cluster string, obj runtime.Object) error {
+	return s.write(ctx, MethodDelete, cluster, obj)
+}
+
+type watcher struct {
+	codec  runtime.Codec
+	stream grpc.ClientStream
+	cancel context.CancelFunc
+	result chan watch.Event
+}
+
+func (w *watcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *watcher) Stop() {
+	w.cancel()
+}
+
+func (w *watcher) receive(ctx context.Context) {
+	defer close(w.result)
+	defer w.cancel()
+
+	for {
+		event := &WatchEvent{}
+		err := w.stream.RecvMsg(event)
+		if err == io.EOF || ctx.Err() != nil {
+			return
+		}
+
+		var result watch.Event
+		switch {
+		case err != nil:
+			result = watch.Event{Type: watch.Error, Object: statusObject(err)}
+		case event.Error != nil:
+			result = watch.Event{Type: watch.Error, Object: statusObject(event.Error.Err())}
+		case event.Type == watch.Error:
+			status := &metav1.Status{}
+			if err := json.Unmarshal(event.Object, status); err != nil {
+				status = statusObject(err)
+			}
+			result = watch.Event{Type: watch.Error, Object: status}
+		default:
+			obj, _, err := w.codec.Decode(event.Object, nil, nil)
+			if err != nil {
+				result = watch.Event{Type: watch.Error, Object: statusObject(err)}
+				break
+			}
+			result = watch.Event{Type: event.Type, Object: obj}
+		}
+
+		select {
+		case w.result <- result:
+		case <-ctx.Done():
+			return
+		}
+		if result.Type == watch.Error {
+			return
+		}
+	}
+}
+
+func statusObject(err error) *metav1.Status {
+	if status, ok := err.(apierrors.APIStatus); ok {
+		s := status.Status()
+		return &s
+	}
+	s := apierrors.NewInternalError(err).Status()
+	return &s
+}
+
+type CollectionResourceStorage struct {
+	client             *client
+	collectionResource *CollectionResource
+}
+
+var _ storage.CollectionResourceStorage = &CollectionResourceStorage{}
+
+func (s *CollectionResourceStorage) create(ctx context.Context) error {
+	_, err := s.client.call(ctx, MethodNewCollectionResourceStorage, &Request{CollectionResource: s.collectionResource})
+	return err
+}
+
+func (s *CollectionResourceStorage) Get(ctx context.Context, opts *internal.ListOptions) (*internal.CollectionResource, error) {
+	values, err := encodeListOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &Request{CollectionResource: &CollectionResource{ObjectMeta: metav1.ObjectMeta{Name: s.collectionResource.Name}}, ListOptions: values}
+	resp, err := s.client.call(ctx, MethodGetCollectionResource, req)
+	if status.Code(err) == codes.FailedPrecondition {
+		if err := s.create(ctx); err != nil {
+			return nil, err
+		}
+		resp, err = s.client.call(ctx, MethodGetCollectionResource, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.CollectionResource == nil {
+		return nil, fmt.Errorf("the collection resource %s is not returned by the storage plugin", s.collectionResource.Name)
+	}
+
+	// the items of the collection resource are always unstructured objects
+	return toInternalCollectionResource(resp.CollectionResource, func(data []byte) (runtime.Object, error) {
+		return runtime.Decode(unstructured.UnstructuredJSONScheme, data)
+	})
+}
@@ -0,0 +1,97 @@
+package grpcstorage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/conversion/queryparams"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/scheme"
+	"github.com/clusterpedia-io/api/clusterpedia/v1beta1"
+)
+
+// encodeListOptions encodes the list options as the url queries of the clusterpedia/v1beta1 list options,
+// the url queries which are not the list options are kept, such as the `groups` and `resources` queries.
+func encodeListOptions(opts *internal.ListOptions) (url.Values, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	out := &v1beta1.ListOptions{}
+	if err := v1beta1.Convert_clusterpedia_ListOptions_To_v1beta1_ListOptions(opts, out, nil); err != nil {
+		return nil, fmt.Errorf("failed to encode list options: %w", err)
+	}
+
+	// the queryparams doesn't encode the inline metav1.ListOptions
+	values, err := queryparams.Convert(&out.ListOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode list options: %w", err)
+	}
+	pediaValues, err := queryparams.Convert(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode list options: %w", err)
+	}
+	for key, value := range pediaValues {
+		values[key] = value
+	}
+	for key, value := range opts.URLQuery {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// decodeListOptions decodes the list options just like the apiserver decodes the url queries.
+func decodeListOptions(values url.Values) (*internal.ListOptions, error) {
+	opts := &internal.ListOptions{}
+	if err := scheme.ParameterCodec.DecodeParameters(values, v1beta1.SchemeGroupVersion, opts); err != nil {
+		return nil, fmt.Errorf("failed to decode list options: %w", err)
+	}
+	return opts, nil
+}
+
+func toCollectionResource(cr *internal.CollectionResource) (*CollectionResource, error) {
+	out := &CollectionResource{
+		ObjectMeta:         cr.ObjectMeta,
+		Continue:           cr.Continue,
+		RemainingItemCount: cr.RemainingItemCount,
+	}
+	for _, rt := range cr.ResourceTypes {
+		out.ResourceTypes = append(out.ResourceTypes, CollectionResourceType(rt))
+	}
+	for _, item := range cr.Items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		out.Items = append(out.Items, data)
+	}
+	return out, nil
+}
+
+// toInternalCollectionResource converts the collection resource, the items are decoded by decode.
+func toInternalCollectionResource(cr *CollectionResource, decode func(data []byte) (runtime.Object, error)) (*internal.CollectionResource, error) {
+	out := &internal.CollectionResource{
+		ObjectMeta:         cr.ObjectMeta,
+		Continue:           cr.Continue,
+		RemainingItemCount: cr.RemainingItemCount,
+	}
+	for _, rt := range cr.ResourceTypes {
+		out.ResourceTypes = append(out.ResourceTypes, internal.CollectionResourceType(rt))
+	}
+	if decode != nil {
+		out.Items = make([]runtime.Object, 0, len(cr.Items))
+		for _, data := range cr.Items {
+			obj, err := decode(data)
+			if err != nil {
+				return nil, err
+			}
+			out.Items = append(out.Items, obj)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,270 @@
+package grpcstorage
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage/memorystorage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+)
+
+func newTestStorageFactory(t *testing.T) storage.StorageFactory {
+	plugin, err := memorystorage.NewStorageFactory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(plugin)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	factory, err := NewStorageFactoryWithConfig(&Config{Address: listener.Addr().String(), Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verbs := factory.GetSupportedRequestVerbs(); len(verbs) == 0 {
+		t.Fatal("expected the supported request verbs of the plugin")
+	}
+	return factory
+}
+
+func newTestResourceStorage(t *testing.T, factory storage.StorageFactory, gvr schema.GroupVersionResource) storage.ResourceStorage {
+	config, err := storageconfig.NewStorageConfigFactory().NewConfig(gvr, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := factory.NewResourceStorage(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+func TestResourceStorage(t *testing.T) {
+	factory := newTestStorageFactory(t)
+	if err := factory.PrepareCluster("cluster-1"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = factory.CleanCluster(context.TODO(), "cluster-1") }()
+
+	gvr := appsv1.SchemeGroupVersion.WithResource("deployments")
+	rs := newTestResourceStorage(t, factory, gvr)
+
+	for i, name := range []string{"deploy-a", "deploy-b", "deploy-c"} {
+		deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: name, ResourceVersion: string(rune('1' + i)),
+			Labels: map[string]string{"app": name},
+		}}
+		if err := rs.Create(context.TODO(), "cluster-1", deploy); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deploy := &appsv1.Deployment{}
+	if err := rs.Get(context.TODO(), "cluster-1", "default", "deploy-b", deploy); err != nil {
+		t.Fatal(err)
+	}
+	if deploy.Name != "deploy-b" {
+		t.Errorf("expected deploy-b, got %s", deploy.Name)
+	}
+
+	err := rs.Get(context.TODO(), "cluster-1", "default", "deploy-d", &appsv1.Deployment{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the not found error, got %v", err)
+	}
+
+	withContinue := true
+	list := &appsv1.DeploymentList{}
+	opts := &internal.ListOptions{
+		ListOptions:  metainternal.ListOptions{Limit: 2},
+		WithContinue: &withContinue,
+	}
+	if err := rs.List(context.TODO(), list, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 2 || list.Items[0].Name != "deploy-a" || list.Continue != "2" {
+		t.Errorf("unexpected list, items: %d, continue: %q", len(list.Items), list.Continue)
+	}
+
+	selector, err := labels.Parse("app=deploy-c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ulist := &unstructured.UnstructuredList{}
+	if err := rs.List(context.TODO(), ulist, &internal.ListOptions{ListOptions: metainternal.ListOptions{LabelSelector: selector}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ulist.Items) != 1 || ulist.Items[0].GetName() != "deploy-c" {
+		t.Errorf("expected deploy-c by the label selector, got %d items", len(ulist.Items))
+	}
+
+	watcher, err := rs.Watch(context.TODO(), &internal.ListOptions{ListOptions: metainternal.ListOptions{LabelSelector: labels.Everything()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-watcher.ResultChan():
+		obj, err := meta.Accessor(event.Object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the initial events of the memory storage are not ordered
+		if name := obj.GetName(); event.Type != watch.Added || (name != "deploy-a" && name != "deploy-b" && name != "deploy-c") {
+			t.Errorf("unexpected watch event %s %s", event.Type, obj.GetName())
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the watch events")
+	}
+	watcher.Stop()
+
+	if err := rs.Delete(context.TODO(), "cluster-1", &list.Items[0]); err != nil {
+		t.Fatal(err)
+	}
+	rvs, err := factory.GetResourceVersions(context.TODO(), "cluster-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rvs[gvr]["default/deploy-a"]; ok {
+		t.Error("expected deploy-a is deleted")
+	}
+	if rv := rvs[gvr]["default/deploy-b"]; rv != "2" {
+		t.Errorf("expected the resource version of deploy-b is 2, got %v", rv)
+	}
+}
+
+func TestCollectionResourceStorage(t *testing.T) {
+	factory := newTestStorageFactory(t)
+	if err := factory.PrepareCluster("cluster-2"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = factory.CleanCluster(context.TODO(), "cluster-2") }()
+
+	rs := newTestResourceStorage(t, factory, corev1.SchemeGroupVersion.WithResource("configmaps"))
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm-a", ResourceVersion: "1"}}
+	if err := rs.Create(context.TODO(), "cluster-2", cm); err != nil {
+		t.Fatal(err)
+	}
+
+	crs, err := factory.GetCollectionResources(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kuberesources *internal.CollectionResource
+	for _, cr := range crs {
+		if cr.Name == storage.CollectionResourceKubeResources {
+			kuberesources = cr
+		}
+	}
+	if kuberesources == nil {
+		t.Fatalf("expected the %s collection resource", storage.CollectionResourceKubeResources)
+	}
+
+	collectionStorage, err := factory.NewCollectionResourceStorage(kuberesources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection, err := collectionStorage.Get(context.TODO(), &internal.ListOptions{
+		ListOptions: metainternal.ListOptions{LabelSelector: labels.Everything()},
+		Namespaces:  []string{"default"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.Items) != 1 || len(collection.ResourceTypes) != 1 || collection.ResourceTypes[0].Kind != "ConfigMap" {
+		t.Fatalf("unexpected collection resource, items: %d, resource types: %v", len(collection.Items), collection.ResourceTypes)
+	}
+	if obj := collection.Items[0].(*unstructured.Unstructured); obj.GetName() != "cm-a" {
+		t.Errorf("expected cm-a, got %s", obj.GetName())
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	plugin, err := memorystorage.NewStorageFactory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(plugin)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := &client{conn: conn, timeout: 10 * time.Second}
+
+	if _, err := c.call(context.TODO(), MethodHandshake, &Request{ProtocolVersion: "v0"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected the other protocol version to be refused, got %v", err)
+	}
+	resp, err := c.call(context.TODO(), MethodHandshake, &Request{ProtocolVersion: ProtocolVersion})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ProtocolVersion != ProtocolVersion || len(resp.Verbs) == 0 {
+		t.Errorf("unexpected handshake response, protocol version: %q, verbs: %v", resp.ProtocolVersion, resp.Verbs)
+	}
+	if len(resp.UnsupportedInterfaces) != len(UnsupportedInterfaces) {
+		t.Errorf("expected the unsupported interfaces %v, got %v", UnsupportedInterfaces, resp.UnsupportedInterfaces)
+	}
+
+	// the client storages don't implement the unsupported interfaces
+	var rs interface{} = &ResourceStorage{}
+	if _, ok := rs.(storage.ResourceBatchWriter); ok {
+		t.Error("expected the client storage not to implement the ResourceBatchWriter")
+	}
+	if _, ok := rs.(storage.ResourceCounter); ok {
+		t.Error("expected the client storage not to implement the ResourceCounter")
+	}
+	if _, ok := rs.(storage.ResourceHistory); ok {
+		t.Error("expected the client storage not to implement the ResourceHistory")
+	}
+	if _, ok := rs.(storage.ResourceRelatedResolver); ok {
+		t.Error("expected the client storage not to implement the ResourceRelatedResolver")
+	}
+}
+
+func TestHandshakeUnimplemented(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	if _, err := NewStorageFactoryWithConfig(&Config{Address: listener.Addr().String(), Timeout: 10 * time.Second}); err == nil || !strings.Contains(err.Error(), "does not implement") {
+		t.Errorf("expected the plugin without the handshake to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Package grpcstorage implements the storage layer over gRPC, so that the storage layers
+// can run in separate processes and be built and versioned independently of the server.
+//
+// The client adapter is registered as the `grpc` storage, and the plugin servers are
+// written with `NewServer` by wrapping a `storage.StorageFactory`.
+//
+// The protocol is published as the proto schema in `storage.proto`, the messages are encoded
+// with the proto3 JSON mapping of the schema, the objects are sent in their storage versions
+// and the list options are sent as the url queries of the clusterpedia/v1beta1 list options.
+//
+// The client calls the `Handshake` method on start, and refuses the plugin which speaks another protocol version.
+// The optional interfaces of the resource storages, such as `storage.ResourceBatchWriter`, have no methods
+// in the protocol, they are listed in the handshake response and the client storages don't implement them.
+package grpcstorage
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+
+	"google.golang.org/grpc/encoding"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+)
+
+// ServiceName is the name of the gRPC service implemented by the storage plugins.
+const ServiceName = "clusterpedia.storage.v1.Storage"
+
+// ProtocolVersion is the version of the protocol exchanged by the `Handshake` method,
+// it is bumped with the package of the schema on the incompatible changes.
+const ProtocolVersion = "v1"
+
+const (
+	MethodHandshake = "Handshake"
+
+	// StorageFactory
+	MethodGetSupportedRequestVerbs     = "GetSupportedRequestVerbs"
+	MethodPrepareCluster               = "PrepareCluster"
+	MethodGetResourceVersions          = "GetResourceVersions"
+	MethodGetCollectionResources       = "GetCollectionResources"
+	MethodNewResourceStorage           = "NewResourceStorage"
+	MethodNewCollectionResourceStorage = "NewCollectionResourceStorage"
+	MethodCleanCluster                 = "CleanCluster"
+	MethodCleanClusterResource         = "CleanClusterResource"
+
+	// ResourceStorage
+	MethodGet    = "Get"
+	MethodList   = "List"
+	MethodWatch  = "Watch"
+	MethodCreate = "Create"
+	MethodUpdate = "Update"
+	MethodUpsert = "Upsert"
+	MethodDelete = "Delete"
+
+	// CollectionResourceStorage
+	MethodGetCollectionResource = "GetCollectionResource"
+)
+
+// UnsupportedInterfaces are the optional interfaces of the resource storages which have no methods
+// in the protocol, they are replied by the `Handshake` method.
+var UnsupportedInterfaces = []string{"ResourceBatchWriter", "ResourceCounter", "ResourceHistory", "ResourceRelatedResolver"}
+
+// codecName is the content subtype of the gRPC messages, `application/grpc+json`.
+const codecName = "json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return codecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// The messages mirror the ones in `storage.proto`, the json tags are the proto3 JSON names of the fields,
+// and they are checked against the schema by the round trip through the proto3 JSON mapping in the tests.
+
+// Request is the request of all unary methods, only the fields used by the method are set.
+type Request struct {
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+
+	Cluster string `json:"cluster,omitempty"`
+
+	// Resource identifies the resource storage created by the `NewResourceStorage` method,
+	// it is the group resource with the storage version.
+	Resource metav1.GroupVersionResource `json:"resource,omitempty"`
+
+	Config             *ResourceStorageConfig `json:"config,omitempty"`
+	CollectionResource *CollectionResource    `json:"collectionResource,omitempty"`
+
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Object    json.RawMessage `json:"object,omitempty"`
+
+	// ListOptions are the url queries of the clusterpedia/v1beta1 list options.
+	ListOptions url.Values `json:"listOptions,omitempty"`
+}
+
+type Response struct {
+	Error *Error `json:"error,omitempty"`
+
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+
+	Verbs               []string                  `json:"verbs,omitempty"`
+	ResourceVersions    []StorageResourceVersions `json:"resourceVersions,omitempty"`
+	CollectionResources []CollectionResource      `json:"collectionResources,omitempty"`
+	CollectionResource  *CollectionResource       `json:"collectionResource,omitempty"`
+
+	Object json.RawMessage `json:"object,omitempty"`
+	List   *ObjectList     `json:"list,omitempty"`
+
+	UnsupportedInterfaces []string `json:"unsupportedInterfaces,omitempty"`
+}
+
+// WatchEvent is sent by the `Watch` stream, the object of the `ERROR` event is the metav1.Status.
+type WatchEvent struct {
+	Type   watch.EventType `json:"type,omitempty"`
+	Object json.RawMessage `json:"object,omitempty"`
+
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error is the error returned by the storage of the plugin.
+type Error struct {
+	Message string `json:"message"`
+
+	// Status is set if the error is an API status error, such as the NotFound or BadRequest error.
+	Status *metav1.Status `json:"status,omitempty"`
+
+	// Recoverable means the error is a recoverable exception of the storage,
+	// and the synchro can retry the write.
+	Recoverable bool `json:"recoverable,omitempty"`
+}
+
+func newError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	e := &Error{Message: err.Error(), Recoverable: storage.IsRecoverableException(err)}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		s := status.Status()
+		e.Status = &s
+	}
+	return e
+}
+
+func (e *Error) Err() error {
+	if e == nil {
+		return nil
+	}
+
+	var err error = errors.New(e.Message)
+	if e.Status != nil {
+		err = &apierrors.StatusError{ErrStatus: *e.Status}
+	}
+	if e.Recoverable {
+		err = storage.NewRecoverableException(err)
+	}
+	return err
+}
+
+type ResourceStorageConfig struct {
+	Namespaced bool `json:"namespaced,omitempty"`
+
+	GroupResource        metav1.GroupResource `json:"groupResource"`
+	StorageGroupResource metav1.GroupResource `json:"storageGroupResource"`
+
+	MemoryVersion  metav1.GroupVersion `json:"memoryVersion"`
+	StorageVersion metav1.GroupVersion `json:"storageVersion"`
+}
+
+type StorageResourceVersions struct {
+	Resource metav1.GroupVersionResource `json:"resource"`
+	Versions map[string]string           `json:"versions"`
+}
+
+type ObjectList struct {
+	ResourceVersion    string `json:"resourceVersion,omitempty"`
+	Continue           string `json:"continue,omitempty"`
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty,string"`
+
+	Items []json.RawMessage `json:"items"`
+}
+
+type CollectionResource struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	ResourceTypes []CollectionResourceType `json:"resourceTypes,omitempty"`
+	Items         []json.RawMessage        `json:"items,omitempty"`
+
+	Continue           string `json:"continue,omitempty"`
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty,string"`
+}
+
+type CollectionResourceType struct {
+	Group    string `json:"group"`
+	Version  string `json:"version,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
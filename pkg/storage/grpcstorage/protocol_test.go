@@ -0,0 +1,302 @@
+package grpcstorage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+var (
+	protoComment = regexp.MustCompile(`//.*`)
+	protoPackage = regexp.MustCompile(`package ([\w.]+);`)
+	protoService = regexp.MustCompile(`(?s)service (\w+) \{(.*?)\n\}`)
+	protoMethod  = regexp.MustCompile(`rpc (\w+)\((\w+)\) returns \((stream )?(\w+)\);`)
+	protoMessage = regexp.MustCompile(`(?s)message (\w+) \{(.*?)\n\}`)
+	protoField   = regexp.MustCompile(`^(optional |repeated )?(map<(\w+), (\w+)>|[\w.]+) (\w+) = (\d+);$`)
+)
+
+var protoScalarTypes = map[string]descriptorpb.FieldDescriptorProto_Type{
+	"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+	"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+	"int32":  descriptorpb.FieldDescriptorProto_TYPE_INT32,
+	"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+}
+
+// loadProtoFile builds the file descriptor of `storage.proto`, the schema only uses the flat messages,
+// the scalar, message and map fields and the unary or server streaming methods, so it is parsed
+// without protoc, and the unknown syntax fails the test.
+func loadProtoFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+
+	data, err := os.ReadFile("storage.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := protoComment.ReplaceAllString(string(data), "")
+
+	pkg := protoPackage.FindStringSubmatch(content)
+	if pkg == nil {
+		t.Fatal("the package of the schema is not found")
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("storage.proto"),
+		Package:    proto.String(pkg[1]),
+		Dependency: []string{"google/protobuf/struct.proto"},
+		Syntax:     proto.String("proto3"),
+	}
+
+	typeName := func(name string) string {
+		if strings.Contains(name, ".") {
+			return "." + name
+		}
+		return "." + pkg[1] + "." + name
+	}
+	newField := func(name string, number int32, typ string) *descriptorpb.FieldDescriptorProto {
+		field := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if scalar, ok := protoScalarTypes[typ]; ok {
+			field.Type = scalar.Enum()
+		} else {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			field.TypeName = proto.String(typeName(typ))
+		}
+		return field
+	}
+
+	for _, message := range protoMessage.FindAllStringSubmatch(content, -1) {
+		msg := &descriptorpb.DescriptorProto{Name: proto.String(message[1])}
+		for _, line := range strings.Split(message[2], "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			match := protoField.FindStringSubmatch(line)
+			if match == nil {
+				t.Fatalf("unsupported syntax in message %s: %q", message[1], line)
+			}
+			number, _ := strconv.Atoi(match[6])
+			name := match[5]
+
+			if match[3] != "" {
+				// the map field is the repeated field of the generated entry message
+				entryName := strings.ToUpper(name[:1]) + name[1:] + "Entry"
+				msg.NestedType = append(msg.NestedType, &descriptorpb.DescriptorProto{
+					Name:    proto.String(entryName),
+					Field:   []*descriptorpb.FieldDescriptorProto{newField("key", 1, match[3]), newField("value", 2, match[4])},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				})
+				field := newField(name, int32(number), message[1]+"."+entryName)
+				field.TypeName = proto.String(typeName(message[1]) + "." + entryName)
+				field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+				msg.Field = append(msg.Field, field)
+				continue
+			}
+
+			field := newField(name, int32(number), match[2])
+			switch match[1] {
+			case "repeated ":
+				field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			case "optional ":
+				// the proto3 optional field is in its synthetic oneof
+				field.Proto3Optional = proto.Bool(true)
+				field.OneofIndex = proto.Int32(int32(len(msg.OneofDecl)))
+				msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_" + name)})
+			}
+			msg.Field = append(msg.Field, field)
+		}
+		file.MessageType = append(file.MessageType, msg)
+	}
+
+	for _, service := range protoService.FindAllStringSubmatch(content, -1) {
+		svc := &descriptorpb.ServiceDescriptorProto{Name: proto.String(service[1])}
+		for _, line := range strings.Split(service[2], "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			match := protoMethod.FindStringSubmatch(line)
+			if match == nil {
+				t.Fatalf("unsupported syntax in service %s: %q", service[1], line)
+			}
+			svc.Method = append(svc.Method, &descriptorpb.MethodDescriptorProto{
+				Name:            proto.String(match[1]),
+				InputType:       proto.String(typeName(match[2])),
+				OutputType:      proto.String(typeName(match[4])),
+				ServerStreaming: proto.Bool(match[3] != ""),
+			})
+		}
+		file.Service = append(file.Service, svc)
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	return fd
+}
+
+func TestProtocolServiceConformance(t *testing.T) {
+	fd := loadProtoFile(t)
+	if fd.Services().Len() != 1 {
+		t.Fatalf("expected one service in the schema, got %d", fd.Services().Len())
+	}
+	service := fd.Services().Get(0)
+	if string(service.FullName()) != ServiceName {
+		t.Errorf("expected the service %s, got %s", ServiceName, service.FullName())
+	}
+
+	var methods []string
+	for i := 0; i < service.Methods().Len(); i++ {
+		method := service.Methods().Get(i)
+		methods = append(methods, string(method.Name()))
+
+		if streaming := method.Name() == MethodWatch; method.IsStreamingServer() != streaming {
+			t.Errorf("method %s: expected the server streaming to be %v", method.Name(), streaming)
+		}
+		if method.Name() != MethodWatch && method.Output().Name() != "Response" {
+			t.Errorf("method %s: expected the Response, got %s", method.Name(), method.Output().Name())
+		}
+	}
+
+	server := NewServer(nil)
+	var implemented []string
+	for _, method := range server.GetServiceInfo()[ServiceName].Methods {
+		implemented = append(implemented, method.Name)
+	}
+	for _, names := range [][]string{methods, implemented} {
+		sort.Strings(names)
+	}
+	if !reflect.DeepEqual(methods, implemented) {
+		t.Errorf("the methods of the schema %v are different from the server %v", methods, implemented)
+	}
+}
+
+// TestProtocolMessageConformance sends the messages through the proto3 JSON mapping of the schema,
+// the unknown fields or the values of the wrong types are rejected by protojson,
+// and the lost fields are found by comparing the messages after the round trip.
+func TestProtocolMessageConformance(t *testing.T) {
+	fd := loadProtoFile(t)
+
+	remaining := int64(10)
+	object := json.RawMessage(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod-1","namespace":"default","resourceVersion":"10"}}`)
+	status := metav1.Status{Status: metav1.StatusFailure, Message: "not found", Reason: metav1.StatusReasonNotFound, Code: 404}
+
+	tests := []struct {
+		name    string
+		message interface{}
+	}{
+		{"Request", &Request{
+			ProtocolVersion: ProtocolVersion,
+			Cluster:         "cluster-1",
+			Resource:        metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			Config: &ResourceStorageConfig{
+				Namespaced:           true,
+				GroupResource:        metav1.GroupResource{Group: "apps", Resource: "deployments"},
+				StorageGroupResource: metav1.GroupResource{Group: "apps", Resource: "deployments"},
+				MemoryVersion:        metav1.GroupVersion{Group: "apps", Version: "__internal"},
+				StorageVersion:       metav1.GroupVersion{Group: "apps", Version: "v1"},
+			},
+			CollectionResource: &CollectionResource{
+				ObjectMeta:    metav1.ObjectMeta{Name: "workloads"},
+				ResourceTypes: []CollectionResourceType{{Group: "apps", Resource: "deployments"}},
+			},
+			Namespace:   "default",
+			Name:        "pod-1",
+			Object:      object,
+			ListOptions: url.Values{"clusters": {"cluster-1", "cluster-2"}, "limit": {"10"}},
+		}},
+		{"Response", &Response{
+			Error:           &Error{Message: "not found", Status: &status, Recoverable: true},
+			ProtocolVersion: ProtocolVersion,
+			Verbs:           []string{"get", "list", "watch"},
+			ResourceVersions: []StorageResourceVersions{{
+				Resource: metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+				Versions: map[string]string{"default/pod-1": "10", "default/pod-2": "11"},
+			}},
+			CollectionResources: []CollectionResource{{
+				ObjectMeta:    metav1.ObjectMeta{Name: "workloads"},
+				ResourceTypes: []CollectionResourceType{{Group: "apps", Version: "v1", Kind: "Deployment", Resource: "deployments"}},
+			}},
+			CollectionResource: &CollectionResource{
+				ObjectMeta:         metav1.ObjectMeta{Name: "workloads"},
+				Items:              []json.RawMessage{object},
+				Continue:           "1",
+				RemainingItemCount: &remaining,
+			},
+			Object: object,
+			List: &ObjectList{
+				ResourceVersion:    "10",
+				Continue:           "1",
+				RemainingItemCount: &remaining,
+				Items:              []json.RawMessage{object, object},
+			},
+			UnsupportedInterfaces: UnsupportedInterfaces,
+		}},
+		{"WatchEvent", &WatchEvent{Type: watch.Modified, Object: object}},
+		{"WatchEvent", &WatchEvent{Type: watch.Error, Error: &Error{Message: "gone", Status: &status}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desc := fd.Messages().ByName(protoreflect.Name(test.name))
+			if desc == nil {
+				t.Fatalf("the message %s is not in the schema", test.name)
+			}
+
+			data, err := jsonCodec{}.Marshal(test.message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			message := dynamicpb.NewMessage(desc)
+			if err := protojson.Unmarshal(data, message); err != nil {
+				t.Fatalf("the message does not conform to the schema: %v\n%s", err, data)
+			}
+			data, err = protojson.Marshal(message)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			roundTrip := reflect.New(reflect.TypeOf(test.message).Elem()).Interface()
+			if err := (jsonCodec{}).Unmarshal(data, roundTrip); err != nil {
+				t.Fatalf("the message of the schema can not be decoded: %v\n%s", err, data)
+			}
+			if expected, got := canonicalJSON(t, test.message), canonicalJSON(t, roundTrip); expected != got {
+				t.Errorf("the message is changed by the round trip:\nexpected %s\ngot      %s", expected, got)
+			}
+		})
+	}
+}
+
+// canonicalJSON encodes the message as the json without the insignificant spaces in the raw messages.
+func canonicalJSON(t *testing.T, message interface{}) string {
+	t.Helper()
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,399 @@
+package grpcstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+)
+
+// NewServer returns the gRPC server of the storage plugin, the requests are served by the storage factory.
+//
+//	server := grpcstorage.NewServer(factory)
+//	listener, _ := net.Listen("tcp", ":8080")
+//	server.Serve(listener)
+func NewServer(factory storage.StorageFactory, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	RegisterStorageFactory(server, factory)
+	return server
+}
+
+// RegisterStorageFactory registers the storage service served by the storage factory to the gRPC server.
+func RegisterStorageFactory(registrar grpc.ServiceRegistrar, factory storage.StorageFactory) {
+	registrar.RegisterService(&serviceDesc, &storageServer{
+		factory:            factory,
+		storageConfigs:     storageconfig.NewStorageConfigFactory(),
+		storages:           make(map[schema.GroupVersionResource]storage.ResourceStorage),
+		collectionStorages: make(map[string]storage.CollectionResourceStorage),
+	})
+}
+
+type storageServer struct {
+	factory        storage.StorageFactory
+	storageConfigs *storageconfig.StorageConfigFactory
+
+	lock               sync.RWMutex
+	storages           map[schema.GroupVersionResource]storage.ResourceStorage
+	collectionStorages map[string]storage.CollectionResourceStorage
+}
+
+type unaryHandler func(s *storageServer, ctx context.Context, req *Request) (*Response, error)
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		newMethodDesc(MethodHandshake, (*storageServer).handshake),
+		newMethodDesc(MethodGetSupportedRequestVerbs, (*storageServer).getSupportedRequestVerbs),
+		newMethodDesc(MethodPrepareCluster, (*storageServer).prepareCluster),
+		newMethodDesc(MethodGetResourceVersions, (*storageServer).getResourceVersions),
+		newMethodDesc(MethodGetCollectionResources, (*storageServer).getCollectionResources),
+		newMethodDesc(MethodNewResourceStorage, (*storageServer).newResourceStorage),
+		newMethodDesc(MethodNewCollectionResourceStorage, (*storageServer).newCollectionResourceStorage),
+		newMethodDesc(MethodCleanCluster, (*storageServer).cleanCluster),
+		newMethodDesc(MethodCleanClusterResource, (*storageServer).cleanClusterResource),
+
+		newMethodDesc(MethodGet, (*storageServer).get),
+		newMethodDesc(MethodList, (*storageServer).list),
+		newMethodDesc(MethodCreate, (*storageServer).create),
+		newMethodDesc(MethodUpdate, (*storageServer).update),
+		newMethodDesc(MethodUpsert, (*storageServer).upsert),
+		newMethodDesc(MethodDelete, (*storageServer).delete),
+
+		newMethodDesc(MethodGetCollectionResource, (*storageServer).getCollectionResource),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    MethodWatch,
+			Handler:       watchHandler,
+			ServerStreams: true,
+		},
+	},
+}
+
+func newMethodDesc(name string, handler unaryHandler) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := &Request{}
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+
+			s := srv.(*storageServer)
+			if interceptor == nil {
+				return handler(s, ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod(name)}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return handler(s, ctx, req.(*Request))
+			})
+		},
+	}
+}
+
+func fullMethod(name string) string {
+	return "/" + ServiceName + "/" + name
+}
+
+// handshake replies with the protocol version of the server, the client of another protocol version is refused.
+func (s *storageServer) handshake(_ context.Context, req *Request) (*Response, error) {
+	if req.ProtocolVersion != ProtocolVersion {
+		return nil, status.Errorf(codes.FailedPrecondition, "unsupported protocol version %q, the storage plugin speaks %q", req.ProtocolVersion, ProtocolVersion)
+	}
+	return &Response{
+		ProtocolVersion:       ProtocolVersion,
+		Verbs:                 s.factory.GetSupportedRequestVerbs(),
+		UnsupportedInterfaces: UnsupportedInterfaces,
+	}, nil
+}
+
+func (s *storageServer) getSupportedRequestVerbs(_ context.Context, _ *Request) (*Response, error) {
+	return &Response{Verbs: s.factory.GetSupportedRequestVerbs()}, nil
+}
+
+func (s *storageServer) prepareCluster(_ context.Context, req *Request) (*Response, error) {
+	return &Response{Error: newError(s.factory.PrepareCluster(req.Cluster))}, nil
+}
+
+func (s *storageServer) getResourceVersions(ctx context.Context, req *Request) (*Response, error) {
+	resourceVersions, err := s.factory.GetResourceVersions(ctx, req.Cluster)
+	if err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+
+	versions := make([]StorageResourceVersions, 0, len(resourceVersions))
+	for gvr, rvs := range resourceVersions {
+		rvStrings := make(map[string]string, len(rvs))
+		for key, rv := range rvs {
+			rvStrings[key] = fmt.Sprint(rv)
+		}
+		versions = append(versions, StorageResourceVersions{Resource: metav1.GroupVersionResource(gvr), Versions: rvStrings})
+	}
+	return &Response{ResourceVersions: versions}, nil
+}
+
+func (s *storageServer) getCollectionResources(ctx context.Context, _ *Request) (*Response, error) {
+	crs, err := s.factory.GetCollectionResources(ctx)
+	if err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+
+	collections := make([]CollectionResource, 0, len(crs))
+	for _, cr := range crs {
+		collection, err := toCollectionResource(cr)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		collections = append(collections, *collection)
+	}
+	return &Response{CollectionResources: collections}, nil
+}
+
+// newResourceStorage creates the resource storage with the config built by the plugin,
+// the storage resource must be the same as the one of the client, otherwise the objects
+// are stored in different versions.
+func (s *storageServer) newResourceStorage(_ context.Context, req *Request) (*Response, error) {
+	if req.Config == nil {
+		return nil, status.Error(codes.InvalidArgument, "the resource storage config is required")
+	}
+
+	gvr := schema.GroupResource(req.Config.GroupResource).WithVersion(req.Config.MemoryVersion.Version)
+	config, err := s.storageConfigs.NewConfig(gvr, req.Config.Namespaced)
+	if err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+
+	storageGVR := config.StorageGroupResource.WithVersion(config.StorageVersion.Version)
+	expected := schema.GroupResource(req.Config.StorageGroupResource).WithVersion(req.Config.StorageVersion.Version)
+	if storageGVR != expected {
+		return &Response{Error: newError(fmt.Errorf("the storage resource %s is different from %s of the client", storageGVR, expected))}, nil
+	}
+
+	resourceStorage, err := s.factory.NewResourceStorage(config)
+	if err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+
+	s.lock.Lock()
+	s.storages[storageGVR] = resourceStorage
+	s.lock.Unlock()
+	return &Response{}, nil
+}
+
+func (s *storageServer) newCollectionResourceStorage(_ context.Context, req *Request) (*Response, error) {
+	if req.CollectionResource == nil {
+		return nil, status.Error(codes.InvalidArgument, "the collection resource is required")
+	}
+
+	cr, err := toInternalCollectionResource(req.CollectionResource, nil)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	collectionStorage, err := s.factory.NewCollectionResourceStorage(cr)
+	if err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+
+	s.lock.Lock()
+	s.collectionStorages[cr.Name] = collectionStorage
+	s.lock.Unlock()
+	return &Response{}, nil
+}
+
+func (s *storageServer) cleanCluster(ctx context.Context, req *Request) (*Response, error) {
+	return &Response{Error: newError(s.factory.CleanCluster(ctx, req.Cluster))}, nil
+}
+
+func (s *storageServer) cleanClusterResource(ctx context.Context, req *Request) (*Response, error) {
+	gvr := schema.GroupVersionResource(req.Resource)
+	return &Response{Error: newError(s.factory.CleanClusterResource(ctx, req.Cluster, gvr))}, nil
+}
+
+// resourceStorage returns the resource storage created by the `NewResourceStorage` method,
+// the `FailedPrecondition` code tells the client to create the resource storage again,
+// such as after the plugin is restarted.
+func (s *storageServer) resourceStorage(req *Request) (storage.ResourceStorage, error) {
+	gvr := schema.GroupVersionResource(req.Resource)
+	s.lock.RLock()
+	resourceStorage, ok := s.storages[gvr]
+	s.lock.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "the resource storage of %s is not created", gvr)
+	}
+	return resourceStorage, nil
+}
+
+func (s *storageServer) get(ctx context.Context, req *Request) (*Response, error) {
+	resourceStorage, err := s.resourceStorage(req)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := resourceStorage.Get(ctx, req.Cluster, req.Namespace, req.Name, obj); err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &Response{Object: data}, nil
+}
+
+func (s *storageServer) list(ctx context.Context, req *Request) (*Response, error) {
+	resourceStorage, err := s.resourceStorage(req)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := decodeListOptions(req.ListOptions)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	objs := &unstructured.UnstructuredList{}
+	if err := resourceStorage.List(ctx, objs, opts); err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+
+	list := &ObjectList{
+		ResourceVersion:    objs.GetResourceVersion(),
+		Continue:           objs.GetContinue(),
+		RemainingItemCount: objs.GetRemainingItemCount(),
+		Items:              make([]json.RawMessage, 0, len(objs.Items)),
+	}
+	for i := range objs.Items {
+		data, err := objs.Items[i].MarshalJSON()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		list.Items = append(list.Items, data)
+	}
+	return &Response{List: list}, nil
+}
+
+func (s *storageServer) create(ctx context.Context, req *Request) (*Response, error) {
+	return s.write(ctx, req, storage.ResourceStorage.Create)
+}
+
+func (s *storageServer) update(ctx context.Context, req *Request) (*Response, error) {
+	return s.write(ctx, req, storage.ResourceStorage.Update)
+}
+
+func (s *storageServer) upsert(ctx context.Context, req *Request) (*Response, error) {
+	return s.write(ctx, req, storage.ResourceStorage.Upsert)
+}
+
+func (s *storageServer) delete(ctx context.Context, req *Request) (*Response, error) {
+	return s.write(ctx, req, storage.ResourceStorage.Delete)
+}
+
+type writeFunc func(s storage.ResourceStorage, ctx context.Context, cluster string, obj runtime.Object) error
+
+func (s *storageServer) write(ctx context.Context, req *Request, write writeFunc) (*Response, error) {
+	resourceStorage, err := s.resourceStorage(req)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, req.Object)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to decode the resource: %v", err)
+	}
+	return &Response{Error: newError(write(resourceStorage, ctx, req.Cluster, obj))}, nil
+}
+
+func (s *storageServer) getCollectionResource(ctx context.Context, req *Request) (*Response, error) {
+	if req.CollectionResource == nil {
+		return nil, status.Error(codes.InvalidArgument, "the collection resource is required")
+	}
+
+	s.lock.RLock()
+	collectionStorage, ok := s.collectionStorages[req.CollectionResource.Name]
+	s.lock.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "the collection resource storage of %s is not created", req.CollectionResource.Name)
+	}
+
+	opts, err := decodeListOptions(req.ListOptions)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	cr, err := collectionStorage.Get(ctx, opts)
+	if err != nil {
+		return &Response{Error: newError(err)}, nil
+	}
+
+	collection, err := toCollectionResource(cr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &Response{CollectionResource: collection}, nil
+}
+
+func watchHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := &Request{}
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(*storageServer).watch(stream, req)
+}
+
+// watch sends the events of the resource storage until the client cancels the stream,
+// the objects are encoded to the storage version by the codec of the resource storage.
+func (s *storageServer) watch(stream grpc.ServerStream, req *Request) error {
+	resourceStorage, err := s.resourceStorage(req)
+	if err != nil {
+		return err
+	}
+	opts, err := decodeListOptions(req.ListOptions)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := stream.Context()
+	watcher, err := resourceStorage.Watch(ctx, opts)
+	if err != nil {
+		return stream.SendMsg(&WatchEvent{Error: newError(err)})
+	}
+	defer watcher.Stop()
+
+	codec := resourceStorage.GetStorageConfig().Codec
+	var buffer bytes.Buffer
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+
+			buffer.Reset()
+			if event.Type == watch.Error {
+				err = json.NewEncoder(&buffer).Encode(event.Object)
+			} else {
+				err = codec.Encode(event.Object, &buffer)
+			}
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			if err := stream.SendMsg(&WatchEvent{Type: event.Type, Object: buffer.Bytes()}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -61,6 +61,31 @@ func (jsonQuery *JSONQueryExpression) writeMysqlJSONKey(builder clause.Builder)
 	writeString(builder, ")")
 }
 
+// writeMysqlJSONValue writes the json value as the unquoted string for mysql.
+func (jsonQuery *JSONQueryExpression) writeMysqlJSONValue(builder clause.Builder) {
+	writeString(builder, "JSON_UNQUOTE(")
+	jsonQuery.writeMysqlJSONKey(builder)
+	writeString(builder, ")")
+}
+
+// writeSQLiteJSONKey writes the json key for sqlite, `JSON_EXTRACT` of sqlite returns the SQL values,
+// the json null is NULL, but the numbers and the booleans are not text.
+func (jsonQuery *JSONQueryExpression) writeSQLiteJSONKey(builder clause.Builder) {
+	jsonQuery.writeMysqlJSONKey(builder)
+}
+
+// writeSQLiteJSONValue writes the json value as the text for sqlite, so it is compared with the string values
+// like mysql and postgres, the booleans are extracted as 1 and 0, so they are written as `true` and `false`.
+func (jsonQuery *JSONQueryExpression) writeSQLiteJSONValue(builder clause.Builder) {
+	writeString(builder, "CASE JSON_TYPE(")
+	builder.WriteQuoted(jsonQuery.column)
+	writeString(builder, ",")
+	builder.AddVar(builder, fmt.Sprintf(`$."%s"`, strings.Join(jsonQuery.keys, `"."`)))
+	writeString(builder, ") WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(")
+	jsonQuery.writeSQLiteJSONKey(builder)
+	writeString(builder, " AS TEXT) END")
+}
+
 func (jsonQuery *JSONQueryExpression) writePostgresJSONKey(builder clause.Builder) {
 	builder.WriteQuoted(jsonQuery.column)
 	for _, key := range jsonQuery.keys[0 : len(jsonQuery.keys)-1] {
@@ -76,81 +101,165 @@ func (jsonQuery *JSONQueryExpression) Build(builder clause.Builder) {
 		return
 	}
 
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok {
+		return
+	}
+
+	var writeKey, writeValue func(builder clause.Builder)
+	switch stmt.Dialector.Name() {
+	case "mysql":
+		writeKey, writeValue = jsonQuery.writeMysqlJSONKey, jsonQuery.writeMysqlJSONValue
+	case "sqlite":
+		writeKey, writeValue = jsonQuery.writeSQLiteJSONKey, jsonQuery.writeSQLiteJSONValue
+	case "postgres":
+		writeKey, writeValue = jsonQuery.writePostgresJSONKey, jsonQuery.writePostgresJSONKey
+	default:
+		return
+	}
+
+	if jsonQuery.not && len(jsonQuery.values) != 0 {
+		writeString(builder, "(")
+		defer func() {
+			writeString(builder, ")")
+		}()
+
+		writeKey(builder)
+		writeString(builder, " IS NULL")
+		writeString(builder, " OR ")
+	}
+
+	writeValue(builder)
+	jsonQuery.writeComparison(builder)
+}
+
+// writeComparison writes the comparison of the json value with the values,
+// or checks if the json value exists when there are no values.
+func (jsonQuery *JSONQueryExpression) writeComparison(builder clause.Builder) {
+	switch len(jsonQuery.values) {
+	case 0:
+		if jsonQuery.not {
+			writeString(builder, " IS NULL")
+		} else {
+			writeString(builder, " IS NOT NULL")
+		}
+	case 1:
+		if jsonQuery.not {
+			writeString(builder, " != ")
+		} else {
+			writeString(builder, " = ")
+		}
+		builder.AddVar(builder, jsonQuery.values[0])
+	default:
+		if jsonQuery.not {
+			writeString(builder, " NOT IN ")
+		} else {
+			writeString(builder, " IN ")
+		}
+		builder.AddVar(builder, jsonQuery.values)
+	}
+}
+
+// JSONValueExpression extracts the json value as the string,
+// it can be used in `SELECT` and `GROUP BY`.
+type JSONValueExpression struct {
+	column string
+	keys   []string
+}
+
+func JSONValue(column string, keys ...string) *JSONValueExpression {
+	return &JSONValueExpression{column: column, keys: keys}
+}
+
+func (jsonValue *JSONValueExpression) Build(builder clause.Builder) {
+	if len(jsonValue.keys) == 0 {
+		return
+	}
+
+	jsonQuery := JSONQuery(jsonValue.column, jsonValue.keys...)
 	if stmt, ok := builder.(*gorm.Statement); ok {
 		switch stmt.Dialector.Name() {
-		case "mysql", "sqlite":
-			if jsonQuery.not && len(jsonQuery.values) != 0 {
-				writeString(builder, "(")
-				defer func() {
-					writeString(builder, ")")
-				}()
-
-				jsonQuery.writeMysqlJSONKey(builder)
-				writeString(builder, " IS NULL")
-				writeString(builder, " OR ")
-			}
+		case "mysql":
+			jsonQuery.writeMysqlJSONValue(builder)
+		case "sqlite":
+			jsonQuery.writeSQLiteJSONValue(builder)
+		case "postgres":
+			jsonQuery.writePostgresJSONKey(builder)
+		}
+	}
+}
 
-			writeString(builder, "JSON_UNQUOTE(")
-			jsonQuery.writeMysqlJSONKey(builder)
-			writeString(builder, ")")
+// FullTextQueryExpression matches the resources by the full-text search,
+// it uses the full-text index created by `migrateFullTextIndex`.
+//
+// mysql searches in the boolean mode, each word of the query is a required phrase.
+//
+// sqlite does not have the full-text index of the resources,
+// each word of the query is matched by `LIKE`.
+type FullTextQueryExpression struct {
+	query string
+}
 
-			switch len(jsonQuery.values) {
-			case 0:
-				if jsonQuery.not {
-					writeString(builder, " IS NULL")
-				} else {
-					writeString(builder, " IS NOT NULL")
-				}
-			case 1:
-				if jsonQuery.not {
-					writeString(builder, " != ")
-				} else {
-					writeString(builder, " = ")
-				}
-				builder.AddVar(builder, jsonQuery.values[0])
-			default:
-				if jsonQuery.not {
-					writeString(builder, " NOT IN ")
-				} else {
-					writeString(builder, " IN ")
+func FullTextQuery(query string) *FullTextQueryExpression {
+	return &FullTextQueryExpression{query: query}
+}
+
+func (fullText *FullTextQueryExpression) Build(builder clause.Builder) {
+	if fullText.query == "" {
+		return
+	}
+
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		switch stmt.Dialector.Name() {
+		case "mysql":
+			writeString(builder, "MATCH (")
+			builder.WriteQuoted(fullTextColumn)
+			writeString(builder, ") AGAINST (")
+			builder.AddVar(builder, mysqlBooleanQuery(fullText.query))
+			writeString(builder, " IN BOOLEAN MODE)")
+		case "sqlite":
+			for i, word := range strings.Fields(fullText.query) {
+				if i != 0 {
+					writeString(builder, " AND ")
 				}
-				builder.AddVar(builder, jsonQuery.values)
+				builder.WriteQuoted("object")
+				writeString(builder, " LIKE ")
+				builder.AddVar(builder, fmt.Sprintf(`%%%s%%`, word))
 			}
 		case "postgres":
-			if jsonQuery.not && len(jsonQuery.values) != 0 {
-				writeString(builder, "(")
-				defer func() {
-					writeString(builder, ")")
-				}()
-
-				jsonQuery.writePostgresJSONKey(builder)
-				writeString(builder, " IS NULL")
-				writeString(builder, " OR ")
-			}
+			writeString(builder, fullTextPostgresVector)
+			writeString(builder, " @@ plainto_tsquery('simple', ")
+			builder.AddVar(builder, fullText.query)
+			writeString(builder, ")")
+		}
+	}
+}
 
-			jsonQuery.writePostgresJSONKey(builder)
-			switch len(jsonQuery.values) {
-			case 0:
-				if jsonQuery.not {
-					writeString(builder, " IS NULL")
-				} else {
-					writeString(builder, " IS NOT NULL")
-				}
-			case 1:
-				if jsonQuery.not {
-					writeString(builder, " != ")
-				} else {
-					writeString(builder, " = ")
-				}
-				builder.AddVar(builder, jsonQuery.values[0])
-			default:
-				if jsonQuery.not {
-					writeString(builder, " NOT IN ")
-				} else {
-					writeString(builder, " IN ")
-				}
-				builder.AddVar(builder, jsonQuery.values)
-			}
+// FullTextRankExpression is the relevance of the full-text search,
+// it is used to sort the results of the full-text search.
+type FullTextRankExpression struct {
+	query string
+}
+
+func FullTextRank(query string) *FullTextRankExpression {
+	return &FullTextRankExpression{query: query}
+}
+
+func (rank *FullTextRankExpression) Build(builder clause.Builder) {
+	if rank.query == "" {
+		return
+	}
+
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		switch stmt.Dialector.Name() {
+		case "mysql":
+			FullTextQuery(rank.query).Build(builder)
+		case "postgres":
+			writeString(builder, "ts_rank(")
+			writeString(builder, fullTextPostgresVector)
+			writeString(builder, ", plainto_tsquery('simple', ")
+			builder.AddVar(builder, rank.query)
+			writeString(builder, "))")
 		}
 	}
 }
@@ -159,24 +268,26 @@ func writeString(builder clause.Writer, str string) {
 	_, _ = builder.WriteString(str)
 }
 
-func buildOwnerQueryByUID(db *gorm.DB, cluster, uid string, seniority int) interface{} {
+// buildOwnerQueryByUID builds the query of the owners, if the owners are in multiple clusters,
+// the query selects the cluster and uid of the owners to correlate the resources with their owners on the cluster.
+func buildOwnerQueryByUID(db *gorm.DB, clusters []string, uid string, seniority int) interface{} {
 	if seniority == 0 {
 		return uid
 	}
 
-	parentOwner := buildOwnerQueryByUID(db, cluster, uid, seniority-1)
-	ownerQuery := db.Model(Resource{}).Select("uid").Where(map[string]interface{}{"cluster": cluster})
+	parentOwner := buildOwnerQueryByUID(db, clusters, uid, seniority-1)
+	ownerQuery := newOwnerQuery(db, clusters)
 	if _, ok := parentOwner.(string); ok {
 		return ownerQuery.Where("owner_uid = ?", parentOwner)
 	}
-	return ownerQuery.Where("owner_uid IN (?)", parentOwner)
+	return whereOwnerIn(ownerQuery, clusters, parentOwner)
 }
 
-func buildOwnerQueryByName(db *gorm.DB, cluster string, namespaces []string, groupResource schema.GroupResource, name string, seniority int) interface{} {
-	ownerQuery := db.Model(Resource{}).Select("uid").Where(map[string]interface{}{"cluster": cluster})
+func buildOwnerQueryByName(db *gorm.DB, clusters []string, namespaces []string, groupResource schema.GroupResource, name string, seniority int) interface{} {
+	ownerQuery := newOwnerQuery(db, clusters)
 	if seniority != 0 {
-		parentOwner := buildOwnerQueryByName(db, cluster, namespaces, groupResource, name, seniority-1)
-		return ownerQuery.Where("owner_uid IN (?)", parentOwner)
+		parentOwner := buildOwnerQueryByName(db, clusters, namespaces, groupResource, name, seniority-1)
+		return whereOwnerIn(ownerQuery, clusters, parentOwner)
 	}
 
 	if !groupResource.Empty() {
@@ -191,3 +302,25 @@ func buildOwnerQueryByName(db *gorm.DB, cluster string, namespaces []string, gro
 	}
 	return ownerQuery.Where("name = ?", name)
 }
+
+// newOwnerQuery selects the live owners, the tombstones of the owners are not matched.
+func newOwnerQuery(db *gorm.DB, clusters []string) *gorm.DB {
+	query := db.Model(Resource{}).Where("removed_at IS NULL")
+	switch len(clusters) {
+	case 0:
+		return query.Select("cluster", "uid")
+	case 1:
+		return query.Select("uid").Where(map[string]interface{}{"cluster": clusters[0]})
+	default:
+		return query.Select("cluster", "uid").Where("cluster IN (?)", clusters)
+	}
+}
+
+// whereOwnerIn filters the resources owned by the owners of the ownerQuery,
+// the owners in multiple clusters are matched by both the cluster and the uid.
+func whereOwnerIn(query *gorm.DB, clusters []string, ownerQuery interface{}) *gorm.DB {
+	if len(clusters) == 1 {
+		return query.Where("owner_uid IN (?)", ownerQuery)
+	}
+	return query.Where("(cluster, owner_uid) IN (?)", ownerQuery)
+}
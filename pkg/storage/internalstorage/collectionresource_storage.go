@@ -2,10 +2,7 @@ package internalstorage
 
 import (
 	"context"
-	"fmt"
-	"net/url"
 	"strconv"
-	"strings"
 
 	"gorm.io/gorm"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -17,8 +14,8 @@ import (
 )
 
 const (
-	URLQueryGroups    = "groups"
-	URLQueryResources = "resources"
+	URLQueryGroups    = storage.URLQueryGroups
+	URLQueryResources = storage.URLQueryResources
 )
 
 type CollectionResourceStorage struct {
@@ -26,10 +23,12 @@ type CollectionResourceStorage struct {
 	typesQuery *gorm.DB
 
 	collectionResource *internal.CollectionResource
+
+	fullTextSearch bool
 }
 
-func NewCollectionResourceStorage(db *gorm.DB, cr *internal.CollectionResource) storage.CollectionResourceStorage {
-	storage := &CollectionResourceStorage{db: db, collectionResource: cr.DeepCopy()}
+func NewCollectionResourceStorage(db *gorm.DB, cr *internal.CollectionResource, fullTextSearch bool) storage.CollectionResourceStorage {
+	storage := &CollectionResourceStorage{db: db, collectionResource: cr.DeepCopy(), fullTextSearch: fullTextSearch}
 	if len(cr.ResourceTypes) == 0 {
 		return storage
 	}
@@ -59,19 +58,23 @@ func NewCollectionResourceStorage(db *gorm.DB, cr *internal.CollectionResource)
 }
 
 func (s *CollectionResourceStorage) query(ctx context.Context, opts *internal.ListOptions) (*gorm.DB, ObjectList, error) {
+	if opts.AsOf != nil {
+		return nil, nil, apierrors.NewBadRequest("as-of is not supported by the collection resources")
+	}
+
 	var result ObjectList = &ResourceList{}
 	if opts.OnlyMetadata {
 		result = &ResourceMetadataList{}
 	}
 
-	query := s.db.WithContext(ctx).Model(&Resource{})
+	query := applyDeletedToQuery(s.db.WithContext(ctx).Model(&Resource{}), opts)
 	if s.typesQuery != nil {
 		return result.Select(query).Where(s.typesQuery), result, nil
 	}
 
 	// The `URLQueryGroups` and `URLQueryResources` only works on *Any Collection Resource*,
 	// does it nned to work on other collection resources?
-	gvrs, all, err := resolveGVRsFromURLQuery(opts.URLQuery)
+	gvrs, all, err := storage.ResolveGVRsFromURLQuery(opts.URLQuery)
 	if err != nil {
 		return nil, nil, apierrors.NewBadRequest(err.Error())
 	}
@@ -98,6 +101,10 @@ func (s *CollectionResourceStorage) query(ctx context.Context, opts *internal.Li
 }
 
 func (s *CollectionResourceStorage) Get(ctx context.Context, opts *internal.ListOptions) (*internal.CollectionResource, error) {
+	if err := checkFullTextSearch(s.fullTextSearch, opts); err != nil {
+		return nil, err
+	}
+
 	query, list, err := s.query(ctx, opts)
 	if err != nil {
 		return nil, err
@@ -141,7 +148,16 @@ func (s *CollectionResourceStorage) Get(ctx context.Context, opts *internal.List
 
 	if opts.WithContinue != nil && *opts.WithContinue {
 		if int64(len(items)) == opts.Limit {
-			collection.Continue = strconv.FormatInt(offset+opts.Limit, 10)
+			if shouldUseKeysetContinue(opts) {
+				id, keys := list.ContinueKeys()
+				token, err := buildContinueToken(id, keys, opts.OrderBy)
+				if err != nil {
+					return nil, InterpretDBError(s.collectionResource.Name, err)
+				}
+				collection.Continue = token
+			} else {
+				collection.Continue = strconv.FormatInt(offset+opts.Limit, 10)
+			}
 		}
 	}
 
@@ -155,100 +171,6 @@ func (s *CollectionResourceStorage) Get(ctx context.Context, opts *internal.List
 	return collection, nil
 }
 
-func resolveGVRsFromURLQuery(query url.Values) (gvrs []schema.GroupVersionResource, all bool, err error) {
-	if query.Has(URLQueryGroups) {
-		for _, group := range strings.Split(query.Get(URLQueryGroups), ",") {
-			if group == "*" {
-				return nil, true, nil
-			}
-
-			gv, err := parseGroupVersion(group)
-			if err != nil {
-				return nil, false, fmt.Errorf("%s query: %w", URLQueryGroups, err)
-			}
-
-			gvrs = append(gvrs, gv.WithResource(""))
-		}
-	}
-	if query.Has(URLQueryResources) {
-		for _, resource := range strings.Split(query.Get(URLQueryResources), ",") {
-			gvr, err := parseGroupVersionResource(resource)
-			if err != nil {
-				return nil, false, fmt.Errorf("%s query: %w", URLQueryResources, err)
-			}
-
-			gvrs = append(gvrs, gvr)
-		}
-	}
-	return
-}
-
-func parseGroupVersion(gv string) (schema.GroupVersion, error) {
-	gv = strings.ReplaceAll(gv, " ", "")
-	if (len(gv) == 0) || (gv == "/") {
-		// match legacy group
-		return schema.GroupVersion{}, nil
-	}
-
-	strs := strings.Split(gv, "/")
-	switch len(strs) {
-	case 1:
-		/*
-			match:
-				* "group"
-		*/
-		return schema.GroupVersion{Group: strs[0]}, nil
-	case 2:
-		/*
-			match:
-				* "/"
-				* "group/version"
-				* "/version"
-				* "group/"
-		*/
-		return schema.GroupVersion{Group: strs[0], Version: strs[1]}, nil
-	}
-	return schema.GroupVersion{}, fmt.Errorf("unexpected GroupVersion string: %v, expect <group> or <group>/<version>", gv)
-}
-
-func parseGroupVersionResource(gvr string) (schema.GroupVersionResource, error) {
-	gvr = strings.ReplaceAll(gvr, " ", "")
-	if gvr == "" {
-		return schema.GroupVersionResource{}, fmt.Errorf("unexpected GroupVersionResource string: %v, expect <group>/<resource> or <group>/<version>/<resource>", gvr)
-	}
-
-	strs := strings.Split(gvr, "/")
-	switch len(strs) {
-	case 2:
-		/*
-			match:
-				* "group/resource"
-				* "/resource" in legacy group /api
-			not match:
-				* "/"
-				* "group/"
-		*/
-		if strs[1] != "" {
-			return schema.GroupVersionResource{Group: strs[0], Resource: strs[1]}, nil
-		}
-	case 3:
-		/*
-			match:
-				* "group/version/resource"
-				* "/version/resource"
-				* "//resource"
-				* "group//resource"
-			not match:
-				* "group/version/"
-				* "group//"
-		*/
-		if strs[2] != "" {
-			return schema.GroupVersionResource{Group: strs[0], Version: strs[1], Resource: strs[2]}, nil
-		}
-	}
-	return schema.GroupVersionResource{}, fmt.Errorf("unexpected GroupVersionResource string: %v, expect <group>/<resource> or <group>/<version>/<resource>", gvr)
-}
-
 func applyListOptionsToCollectionResourceQuery(query *gorm.DB, opts *internal.ListOptions) (int64, *int64, *gorm.DB, error) {
 	return applyListOptionsToQuery(query, opts, nil)
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -20,6 +21,18 @@ const (
 	defaultMaxIdleConns    = 5
 	defaultMaxOpenConns    = 40
 	defaultConnMaxLifetime = time.Hour
+
+	defaultSQLiteBusyTimeout = "5000"
+
+	defaultWatchPollInterval   = time.Second
+	defaultWatchEventRetention = time.Hour
+	defaultWatchCommitTimeout  = 5 * time.Second
+
+	defaultHistoryRetention = 7 * 24 * time.Hour
+
+	defaultSoftDeleteRetention = 24 * time.Hour
+
+	defaultSlowThreshold = 200 * time.Millisecond
 )
 
 type Config struct {
@@ -45,6 +58,45 @@ type Config struct {
 	Params map[string]string `yaml:"params"`
 
 	Log *LogConfig `yaml:"log"`
+
+	Watch *WatchConfig `yaml:"watch"`
+
+	History *HistoryConfig `yaml:"history"`
+
+	SoftDelete *SoftDeleteConfig `yaml:"softDelete"`
+
+	// FullTextSearch enables the full-text search of the resources by the `query` list option,
+	// the full-text index is created when migrating the tables, for MySQL it adds the generated
+	// `object_text` column and rebuilds the `resources` table.
+	//
+	// MySQL only indexes the words of at least `innodb_ft_min_token_size` (3 by default) characters
+	// that are not the stopwords, so the shorter words can not be searched.
+	FullTextSearch bool `yaml:"fullTextSearch"`
+}
+
+// WatchConfig enables the watch of the resources, the changes of the resources
+// are recorded in the `resource_events` table and the watchers poll this table.
+type WatchConfig struct {
+	PollInterval   time.Duration `yaml:"pollInterval" default:"1s"`
+	EventRetention time.Duration `yaml:"eventRetention" default:"1h"`
+
+	// CommitTimeout is how long the watchers wait for the events whose IDs are allocated but not committed yet,
+	// the events after them are held back until they are committed or the timeout is reached.
+	CommitTimeout time.Duration `yaml:"commitTimeout" default:"5s"`
+}
+
+// HistoryConfig enables the history of the resources, each version of the resources
+// is recorded in the `resource_histories` table for the point-in-time queries.
+type HistoryConfig struct {
+	// Retention is how long the replaced or deleted versions are kept,
+	// the current versions of the resources are always kept.
+	Retention time.Duration `yaml:"retention" default:"168h"`
+}
+
+// SoftDeleteConfig enables the soft deletion of the resources, the resources deleted
+// from the clusters are kept as the tombstones and can still be listed for the retention.
+type SoftDeleteConfig struct {
+	Retention time.Duration `yaml:"retention" default:"24h"`
 }
 
 type LogConfig struct {
@@ -113,6 +165,14 @@ func (cfg *Config) LoggerConfig() (logger.Config, error) {
 	}, nil
 }
 
+// getSlowThreshold returns the threshold of the slow queries, it is used by both the logger and the metrics.
+func (cfg *Config) getSlowThreshold() time.Duration {
+	if cfg.Log == nil {
+		return defaultSlowThreshold
+	}
+	return cfg.Log.SlowThreshold
+}
+
 func (cfg *Config) getConnPoolConfig() (ConnPoolConfig, error) {
 	connPool := ConnPoolConfig{
 		MaxIdleConns:    cfg.ConnPool.MaxIdleConns,
@@ -136,6 +196,48 @@ func (cfg *Config) getConnPoolConfig() (ConnPoolConfig, error) {
 	return connPool, nil
 }
 
+func (cfg *Config) getWatchConfig() *WatchConfig {
+	if cfg.Watch == nil {
+		return nil
+	}
+
+	watch := *cfg.Watch
+	if watch.PollInterval <= 0 {
+		watch.PollInterval = defaultWatchPollInterval
+	}
+	if watch.EventRetention <= 0 {
+		watch.EventRetention = defaultWatchEventRetention
+	}
+	if watch.CommitTimeout <= 0 {
+		watch.CommitTimeout = defaultWatchCommitTimeout
+	}
+	return &watch
+}
+
+func (cfg *Config) getHistoryConfig() *HistoryConfig {
+	if cfg.History == nil {
+		return nil
+	}
+
+	history := *cfg.History
+	if history.Retention <= 0 {
+		history.Retention = defaultHistoryRetention
+	}
+	return &history
+}
+
+func (cfg *Config) getSoftDeleteConfig() *SoftDeleteConfig {
+	if cfg.SoftDelete == nil {
+		return nil
+	}
+
+	softDelete := *cfg.SoftDelete
+	if softDelete.Retention <= 0 {
+		softDelete.Retention = defaultSoftDeleteRetention
+	}
+	return &softDelete
+}
+
 func (cfg *Config) genMySQLConfig() (*mysql.Config, error) {
 	tlsConfig, err := configTLS(cfg.Host, cfg.SSLMode, cfg.RootCertFile, cfg.CertFile, cfg.KeyFile)
 	if err != nil {
@@ -247,6 +349,23 @@ func (cfg *Config) genPostgresConfig() (*pgx.ConnConfig, error) {
 	return pgx.ParseConfig(dns)
 }
 
+// genSQLiteDSN returns the dsn of sqlite, `Database` is the path of the database file,
+// and ":memory:" means using a shared in-memory database.
+func (cfg *Config) genSQLiteDSN() string {
+	params := url.Values{}
+	for key, value := range cfg.Params {
+		params.Set(key, value)
+	}
+	if !params.Has("_busy_timeout") {
+		params.Set("_busy_timeout", defaultSQLiteBusyTimeout)
+	}
+
+	if cfg.Database == ":memory:" {
+		params.Set("cache", "shared")
+	}
+	return fmt.Sprintf("file:%s?%s", cfg.Database, params.Encode())
+}
+
 func configTLS(host, sslmode, sslrootcert, sslcert, sslkey string) (*tls.Config, error) {
 	tlsConfig := &tls.Config{}
 	switch sslmode {
@@ -0,0 +1,188 @@
+package internalstorage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+)
+
+// continueTokenVersion is the version of the keyset continue token,
+// it needs to be increased when the format of the token is changed.
+const continueTokenVersion = 2
+
+// continueToken records the sort keys of the last row of the previous page,
+// the next page is queried by seeking from these keys instead of using `OFFSET`.
+//
+// The sort keys are selected as a JSON array by the list query, see `continueKeysColumn`,
+// so they keep the representation of the database and are compared with the columns as is.
+// The `id` of the row is always used as the last sort key to ensure that the order is stable.
+type continueToken struct {
+	Version int           `json:"v"`
+	OrderBy []string      `json:"o,omitempty"`
+	Keys    []interface{} `json:"k,omitempty"`
+	ID      uint          `json:"id"`
+}
+
+func continueKeyValue(key interface{}) interface{} {
+	if number, ok := key.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			return i
+		}
+		if f, err := number.Float64(); err == nil {
+			return f
+		}
+		return number.String()
+	}
+	return key
+}
+
+// isOffsetContinue returns true if `Continue` is the numeric offset,
+// such as the `search.clusterpedia.io/offset` or the continue token returned by the old version.
+func isOffsetContinue(continueValue string) bool {
+	_, err := strconv.Atoi(continueValue)
+	return err == nil
+}
+
+// shouldUseKeysetContinue returns true if the list needs to be sorted by keyset,
+// either to build the continue token or to seek from the continue token.
+func shouldUseKeysetContinue(opts *internal.ListOptions) bool {
+	if opts.Continue != "" {
+		return !isOffsetContinue(opts.Continue)
+	}
+
+	// The relevance of the full-text search can not be recorded in the keyset continue token,
+	// and the keys of the continue token are read from the resources rather than the histories,
+	// the offset continue token is used instead.
+	if isSortedByRelevance(opts) || opts.AsOf != nil {
+		return false
+	}
+	return opts.WithContinue != nil && *opts.WithContinue && opts.Limit > 0
+}
+
+// isSortedByRelevance returns true if the results of the full-text search are sorted by the relevance,
+// the relevance is only used when the order by is not specified.
+func isSortedByRelevance(opts *internal.ListOptions) bool {
+	return opts.Query != "" && len(opts.OrderBy) == 0
+}
+
+func orderByFields(orderbys []internal.OrderBy) []string {
+	fields := make([]string, 0, len(orderbys))
+	for _, orderby := range orderbys {
+		field := orderby.Field
+		if orderby.Desc {
+			field += " desc"
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func orderByColumn(field string) string {
+	if field == "resource_version" {
+		return "CAST(resource_version as decimal)"
+	}
+	return field
+}
+
+func decodeContinueToken(value string, orderbys []internal.OrderBy) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid continue token: %w", err)
+	}
+
+	// Use `json.Number` to avoid losing the precision of the integer keys.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	token := &continueToken{}
+	if err := decoder.Decode(token); err != nil {
+		return nil, fmt.Errorf("invalid continue token: %w", err)
+	}
+	if token.Version != continueTokenVersion {
+		return nil, fmt.Errorf("invalid continue token: unsupported version %d", token.Version)
+	}
+
+	fields := orderByFields(orderbys)
+	if len(token.Keys) != len(fields) || strings.Join(token.OrderBy, ",") != strings.Join(fields, ",") {
+		return nil, errors.New("invalid continue token: the orderby has been changed")
+	}
+	return token, nil
+}
+
+func (token *continueToken) encode() (string, error) {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// seekExpression returns the condition for the rows after the token, for example:
+// `(f1 > v1) OR (f1 = v1 AND f2 < v2) OR (f1 = v1 AND f2 = v2 AND id > id)`
+func (token *continueToken) seekExpression(orderbys []internal.OrderBy) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+
+		equals     []string
+		equalsArgs []interface{}
+	)
+	for i, orderby := range orderbys {
+		column := orderByColumn(orderby.Field)
+		op := ">"
+		if orderby.Desc {
+			op = "<"
+		}
+
+		condition := strings.Join(append(equals[0:len(equals):len(equals)], fmt.Sprintf("%s %s ?", column, op)), " AND ")
+		conditions = append(conditions, "("+condition+")")
+		args = append(append(args, equalsArgs...), continueKeyValue(token.Keys[i]))
+
+		equals = append(equals, column+" = ?")
+		equalsArgs = append(equalsArgs, continueKeyValue(token.Keys[i]))
+	}
+
+	conditions = append(conditions, "("+strings.Join(append(equals, "id > ?"), " AND ")+")")
+	args = append(append(args, equalsArgs...), token.ID)
+	return strings.Join(conditions, " OR "), args
+}
+
+// continueKeysColumn selects the sort keys as a JSON array, which are used to build the continue token
+// from the last row of the page.
+func continueKeysColumn(dialect string, orderbys []internal.OrderBy) string {
+	columns := make([]string, 0, len(orderbys))
+	for _, orderby := range orderbys {
+		columns = append(columns, orderByColumn(orderby.Field))
+	}
+
+	function := "json_array"
+	switch dialect {
+	case "mysql":
+		function = "JSON_ARRAY"
+	case "postgres":
+		function = "json_build_array"
+	}
+	return fmt.Sprintf("%s(%s) AS continue_keys", function, strings.Join(columns, ", "))
+}
+
+// buildContinueToken builds the continue token from the primary key and the sort keys of the last row.
+func buildContinueToken(id uint, keys []byte, orderbys []internal.OrderBy) (string, error) {
+	token := &continueToken{Version: continueTokenVersion, OrderBy: orderByFields(orderbys), ID: id}
+	if len(orderbys) != 0 {
+		decoder := json.NewDecoder(bytes.NewReader(keys))
+		decoder.UseNumber()
+		if err := decoder.Decode(&token.Keys); err != nil {
+			return "", fmt.Errorf("failed to decode the sort keys: %w", err)
+		}
+		if len(token.Keys) != len(orderbys) {
+			return "", fmt.Errorf("failed to build continue token: expected %d sort keys, but got %d", len(orderbys), len(token.Keys))
+		}
+	}
+	return token.encode()
+}
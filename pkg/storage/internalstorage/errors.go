@@ -12,6 +12,7 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/gorm"
 	genericstorage "k8s.io/apiserver/pkg/storage"
 
@@ -68,6 +69,11 @@ func InterpretDBError(key string, err error) error {
 		return pgError
 	}
 
+	sqliteError := InterpretSQLiteError(key, err)
+	if sqliteError != err {
+		return sqliteError
+	}
+
 	return err
 }
 
@@ -107,3 +113,20 @@ func InterpretPostgresError(key string, err error) error {
 	}
 	return err
 }
+
+func InterpretSQLiteError(key string, err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code {
+	case sqlite3.ErrConstraint:
+		if sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return genericstorage.NewKeyExistsError(key, 0)
+		}
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return storage.NewRecoverableException(err)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package internalstorage
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+)
+
+const (
+	fullTextIndex = "idx_resources_full_text"
+
+	// fullTextColumn is the generated column of mysql, the FULLTEXT index of mysql
+	// can not be created on the json column directly.
+	fullTextColumn = "object_text"
+
+	// fullTextPostgresVector is the expression of the GIN index of postgres,
+	// the query needs to use the same expression to hit the index.
+	fullTextPostgresVector = "to_tsvector('simple', object)"
+)
+
+// checkFullTextSearch rejects the `query` list option if the full-text search is not enabled,
+// there is no full-text index of the resources to search.
+func checkFullTextSearch(enabled bool, opts *internal.ListOptions) error {
+	if opts.Query != "" && !enabled {
+		return apierrors.NewBadRequest("query is not supported, the full-text search of the resources is not enabled")
+	}
+	return nil
+}
+
+// mysqlBooleanQuery quotes each word of the query as a required phrase of the boolean mode,
+// so the operators of the boolean mode in the words are matched literally, and a word like `nginx:1.21`
+// matches its tokens in order instead of any of them.
+func mysqlBooleanQuery(query string) string {
+	words := strings.Fields(strings.ReplaceAll(query, `"`, " "))
+	for i, word := range words {
+		words[i] = `+"` + word + `"`
+	}
+	return strings.Join(words, " ")
+}
+
+// migrateFullTextIndex creates the full-text index of the resources,
+// sqlite does not support the full-text index, and the full-text search falls back to `LIKE`.
+func migrateFullTextIndex(db *gorm.DB) error {
+	migrator := db.Migrator()
+	switch db.Dialector.Name() {
+	case "mysql":
+		if !migrator.HasColumn(&Resource{}, fullTextColumn) {
+			sql := fmt.Sprintf("ALTER TABLE resources ADD COLUMN %s LONGTEXT GENERATED ALWAYS AS (CAST(object AS CHAR)) STORED", fullTextColumn)
+			if err := db.Exec(sql).Error; err != nil {
+				return fmt.Errorf("failed to add full-text column: %w", err)
+			}
+		}
+		if !migrator.HasIndex(&Resource{}, fullTextIndex) {
+			sql := fmt.Sprintf("CREATE FULLTEXT INDEX %s ON resources (%s)", fullTextIndex, fullTextColumn)
+			if err := db.Exec(sql).Error; err != nil {
+				return fmt.Errorf("failed to create full-text index: %w", err)
+			}
+		}
+	case "postgres":
+		sql := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON resources USING GIN (%s)", fullTextIndex, fullTextPostgresVector)
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create full-text index: %w", err)
+		}
+	}
+	return nil
+}
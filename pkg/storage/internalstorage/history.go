@@ -0,0 +1,147 @@
+package internalstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+)
+
+// recordChanges returns true if the changes of the resources need to be recorded
+// to the resource events or the resource histories.
+func (s *ResourceStorage) recordChanges() bool {
+	return s.watch != nil || s.history != nil
+}
+
+// recordChange records the change of the resource in the same transaction as the change,
+// the resource event is used by the watch and the resource history is used by the point-in-time queries.
+func (s *ResourceStorage) recordChange(tx *gorm.DB, eventType watch.EventType, resource *Resource) error {
+	if s.watch != nil {
+		if err := tx.Create(newResourceEvent(eventType, resource)).Error; err != nil {
+			return err
+		}
+	}
+	if s.history == nil {
+		return nil
+	}
+
+	// The current version is replaced by the new version or closed by the deletion.
+	now := time.Now().UTC()
+	result := tx.Model(&ResourceHistory{}).Where(map[string]interface{}{
+		"cluster":   resource.Cluster,
+		"group":     resource.Group,
+		"version":   resource.Version,
+		"resource":  resource.Resource,
+		"namespace": resource.Namespace,
+		"name":      resource.Name,
+	}).Where("valid_to IS NULL").Update("valid_to", now)
+	if result.Error != nil || eventType == watch.Deleted {
+		return result.Error
+	}
+	return tx.Create(newResourceHistory(resource, now)).Error
+}
+
+// genResourceQuery returns the query of the resources, if `AsOf` is specified,
+// the versions of the resources that were valid at that time are queried from the resource histories.
+func (s *ResourceStorage) genResourceQuery(ctx context.Context, opts *internal.ListOptions) (*gorm.DB, error) {
+	if err := checkFullTextSearch(s.fullTextSearch, opts); err != nil {
+		return nil, err
+	}
+
+	gvr := map[string]interface{}{
+		"group":    s.storageGroupResource.Group,
+		"version":  s.storageVersion.Version,
+		"resource": s.storageGroupResource.Resource,
+	}
+	if opts.AsOf == nil {
+		return applyDeletedToQuery(s.db.WithContext(ctx).Model(&Resource{}).Where(gvr), opts), nil
+	}
+
+	if s.history == nil {
+		return nil, apierrors.NewBadRequest("as-of is not supported, the history of the resources is not enabled")
+	}
+	if opts.Query != "" {
+		return nil, apierrors.NewBadRequest("full-text search query is not supported with as-of")
+	}
+	return applyAsOfToQuery(s.db.WithContext(ctx).Model(&ResourceHistory{}).Where(gvr), opts.AsOf.Time), nil
+}
+
+func applyAsOfToQuery(query *gorm.DB, asOf time.Time) *gorm.DB {
+	asOf = asOf.UTC()
+	return query.Where("valid_from <= ?", asOf).Where("valid_to IS NULL OR valid_to > ?", asOf)
+}
+
+func (s *ResourceStorage) GetAsOf(ctx context.Context, cluster, namespace, name string, asOf time.Time, into runtime.Object) error {
+	if s.history == nil {
+		return apierrors.NewMethodNotSupported(s.storageGroupResource, "get as-of")
+	}
+
+	var objects [][]byte
+	query := s.db.WithContext(ctx).Model(&ResourceHistory{}).Select("object").Where(map[string]interface{}{
+		"cluster":   cluster,
+		"group":     s.storageGroupResource.Group,
+		"version":   s.storageVersion.Version,
+		"resource":  s.storageGroupResource.Resource,
+		"namespace": namespace,
+		"name":      name,
+	})
+	if result := applyAsOfToQuery(query, asOf).First(&objects); result.Error != nil {
+		return InterpretResourceDBError(cluster, namespace+"/"+name, result.Error)
+	}
+
+	obj, _, err := s.codec.Decode(objects[0], nil, into)
+	if err != nil {
+		return err
+	}
+	if obj != into {
+		return fmt.Errorf("Failed to decode resource, into is %T", into)
+	}
+	return nil
+}
+
+func (s *ResourceStorage) ListRevisions(ctx context.Context, cluster, namespace, name string) (*internal.ResourceRevisions, error) {
+	if s.history == nil {
+		return nil, apierrors.NewMethodNotSupported(s.storageGroupResource, "list revisions")
+	}
+
+	var histories []ResourceHistory
+	result := s.db.WithContext(ctx).Where(map[string]interface{}{
+		"cluster":   cluster,
+		"group":     s.storageGroupResource.Group,
+		"version":   s.storageVersion.Version,
+		"resource":  s.storageGroupResource.Resource,
+		"namespace": namespace,
+		"name":      name,
+	}).Order("id").Find(&histories)
+	if result.Error != nil {
+		return nil, InterpretResourceDBError(cluster, namespace+"/"+name, result.Error)
+	}
+
+	revisions := &internal.ResourceRevisions{Items: make([]internal.ResourceRevision, 0, len(histories))}
+	for _, history := range histories {
+		obj, err := Bytes(history.Object).ConvertToUnstructured()
+		if err != nil {
+			return nil, err
+		}
+
+		revision := internal.ResourceRevision{
+			Revision:        int64(history.ID),
+			ResourceVersion: history.ResourceVersion,
+			ValidFrom:       metav1.NewTime(history.ValidFrom),
+			Object:          obj,
+		}
+		if history.ValidTo.Valid {
+			validTo := metav1.NewTime(history.ValidTo.Time)
+			revision.ValidTo = &validTo
+		}
+		revisions.Items = append(revisions.Items, revision)
+	}
+	return revisions, nil
+}
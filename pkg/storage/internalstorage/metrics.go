@@ -0,0 +1,200 @@
+package internalstorage
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsNamespace = "clusterpedia"
+	metricsSubsystem = "internalstorage"
+
+	metricsStartTimeKey = "clusterpedia:metrics_start_time"
+)
+
+var (
+	queryDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "query_duration_seconds",
+			Help:           "Latency of the database statements, partitioned by table and operation.",
+			Buckets:        []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"table", "operation"},
+	)
+
+	queryRows = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "query_rows",
+			Help:           "Number of the rows returned by the queries, partitioned by table.",
+			Buckets:        compbasemetrics.ExponentialBuckets(1, 4, 9),
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"table"},
+	)
+
+	slowQueriesTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "slow_queries_total",
+			Help:           "Number of the database statements slower than the slow threshold of the log config, partitioned by table and operation.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"table", "operation"},
+	)
+
+	queryErrorsTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "query_errors_total",
+			Help:           "Number of the failed database statements, partitioned by table and operation.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"table", "operation"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// registerMetricsForDB registers the metrics of the storage layer and the stats of the connection pool,
+// only the first db is registered since a process uses only one storage factory.
+func registerMetricsForDB(db *sql.DB) {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(queryDuration)
+		legacyregistry.MustRegister(queryRows)
+		legacyregistry.MustRegister(slowQueriesTotal)
+		legacyregistry.MustRegister(queryErrorsTotal)
+		legacyregistry.CustomMustRegister(newDBStatsCollector(db))
+	})
+}
+
+// metricsPlugin is the gorm plugin that records the latency, the returned rows and the slow queries
+// of the database statements.
+type metricsPlugin struct {
+	slowThreshold time.Duration
+}
+
+func (p *metricsPlugin) Name() string {
+	return "clusterpedia:metrics"
+}
+
+func (p *metricsPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return utilerrors.NewAggregate([]error{
+		callback.Create().Before("gorm:create").Register("clusterpedia:metrics_before_create", p.before),
+		callback.Create().After("gorm:create").Register("clusterpedia:metrics_after_create", p.after("create")),
+		callback.Query().Before("gorm:query").Register("clusterpedia:metrics_before_query", p.before),
+		callback.Query().After("gorm:query").Register("clusterpedia:metrics_after_query", p.after("query")),
+		callback.Update().Before("gorm:update").Register("clusterpedia:metrics_before_update", p.before),
+		callback.Update().After("gorm:update").Register("clusterpedia:metrics_after_update", p.after("update")),
+		callback.Delete().Before("gorm:delete").Register("clusterpedia:metrics_before_delete", p.before),
+		callback.Delete().After("gorm:delete").Register("clusterpedia:metrics_after_delete", p.after("delete")),
+		callback.Row().Before("gorm:row").Register("clusterpedia:metrics_before_row", p.before),
+		callback.Row().After("gorm:row").Register("clusterpedia:metrics_after_row", p.after("row")),
+		callback.Raw().Before("gorm:raw").Register("clusterpedia:metrics_before_raw", p.before),
+		callback.Raw().After("gorm:raw").Register("clusterpedia:metrics_after_raw", p.after("raw")),
+	})
+}
+
+func (p *metricsPlugin) before(db *gorm.DB) {
+	db.InstanceSet(metricsStartTimeKey, time.Now())
+}
+
+func (p *metricsPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(metricsStartTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		elapsed := time.Since(start)
+		table := db.Statement.Table
+		queryDuration.WithLabelValues(table, operation).Observe(elapsed.Seconds())
+		if p.slowThreshold > 0 && elapsed > p.slowThreshold {
+			slowQueriesTotal.WithLabelValues(table, operation).Inc()
+		}
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErrorsTotal.WithLabelValues(table, operation).Inc()
+			return
+		}
+		if operation == "query" {
+			queryRows.WithLabelValues(table).Observe(float64(db.Statement.RowsAffected))
+		}
+	}
+}
+
+// dbStatsCollector collects the stats of the connection pool from `sql.DB.Stats()`.
+type dbStatsCollector struct {
+	compbasemetrics.BaseStableCollector
+
+	db *sql.DB
+}
+
+var (
+	dbMaxOpenConnectionsDesc = compbasemetrics.NewDesc(
+		compbasemetrics.BuildFQName(metricsNamespace, metricsSubsystem, "db_max_open_connections"),
+		"Maximum number of the open connections to the database.",
+		nil, nil, compbasemetrics.ALPHA, "",
+	)
+	dbOpenConnectionsDesc = compbasemetrics.NewDesc(
+		compbasemetrics.BuildFQName(metricsNamespace, metricsSubsystem, "db_open_connections"),
+		"Number of the established connections to the database, partitioned by state.",
+		[]string{"state"}, nil, compbasemetrics.ALPHA, "",
+	)
+	dbWaitCountDesc = compbasemetrics.NewDesc(
+		compbasemetrics.BuildFQName(metricsNamespace, metricsSubsystem, "db_wait_count_total"),
+		"Total number of the connections waited for.",
+		nil, nil, compbasemetrics.ALPHA, "",
+	)
+	dbWaitDurationDesc = compbasemetrics.NewDesc(
+		compbasemetrics.BuildFQName(metricsNamespace, metricsSubsystem, "db_wait_duration_seconds_total"),
+		"Total time blocked waiting for a new connection.",
+		nil, nil, compbasemetrics.ALPHA, "",
+	)
+	dbClosedConnectionsDesc = compbasemetrics.NewDesc(
+		compbasemetrics.BuildFQName(metricsNamespace, metricsSubsystem, "db_closed_connections_total"),
+		"Total number of the connections closed by the connection pool, partitioned by reason.",
+		[]string{"reason"}, nil, compbasemetrics.ALPHA, "",
+	)
+)
+
+func newDBStatsCollector(db *sql.DB) compbasemetrics.StableCollector {
+	return &dbStatsCollector{db: db}
+}
+
+func (c *dbStatsCollector) DescribeWithStability(ch chan<- *compbasemetrics.Desc) {
+	ch <- dbMaxOpenConnectionsDesc
+	ch <- dbOpenConnectionsDesc
+	ch <- dbWaitCountDesc
+	ch <- dbWaitDurationDesc
+	ch <- dbClosedConnectionsDesc
+}
+
+func (c *dbStatsCollector) CollectWithStability(ch chan<- compbasemetrics.Metric) {
+	stats := c.db.Stats()
+	ch <- compbasemetrics.NewLazyConstMetric(dbMaxOpenConnectionsDesc, compbasemetrics.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- compbasemetrics.NewLazyConstMetric(dbOpenConnectionsDesc, compbasemetrics.GaugeValue, float64(stats.InUse), "in_use")
+	ch <- compbasemetrics.NewLazyConstMetric(dbOpenConnectionsDesc, compbasemetrics.GaugeValue, float64(stats.Idle), "idle")
+	ch <- compbasemetrics.NewLazyConstMetric(dbWaitCountDesc, compbasemetrics.CounterValue, float64(stats.WaitCount))
+	ch <- compbasemetrics.NewLazyConstMetric(dbWaitDurationDesc, compbasemetrics.CounterValue, stats.WaitDuration.Seconds())
+	ch <- compbasemetrics.NewLazyConstMetric(dbClosedConnectionsDesc, compbasemetrics.CounterValue, float64(stats.MaxIdleClosed), "max_idle")
+	ch <- compbasemetrics.NewLazyConstMetric(dbClosedConnectionsDesc, compbasemetrics.CounterValue, float64(stats.MaxIdleTimeClosed), "max_idle_time")
+	ch <- compbasemetrics.NewLazyConstMetric(dbClosedConnectionsDesc, compbasemetrics.CounterValue, float64(stats.MaxLifetimeClosed), "max_lifetime")
+}
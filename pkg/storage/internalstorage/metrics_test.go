@@ -0,0 +1,60 @@
+package internalstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/apis/apps"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+)
+
+func TestMetricsPlugin(t *testing.T) {
+	factory, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+	sqlDB, err := factory.db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	registerMetricsForDB(sqlDB)
+	if err := factory.db.Use(&metricsPlugin{slowThreshold: time.Nanosecond}); err != nil {
+		t.Fatalf("use metrics plugin failed: %v", err)
+	}
+
+	ctx := context.TODO()
+	for _, name := range []string{"deploy-1", "deploy-2"} {
+		if err := rs.Create(ctx, "cluster-1", newTestDeployment("ns-1", name, nil, "")); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+	if err := rs.List(ctx, &apps.DeploymentList{}, &internal.ListOptions{}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	creates, err := testutil.GetHistogramMetricCount(queryDuration.WithLabelValues("resources", "create"))
+	if err != nil {
+		t.Fatalf("get query duration failed: %v", err)
+	}
+	if creates != 2 {
+		t.Errorf("expected 2 create statements, but got %d", creates)
+	}
+
+	rows, err := testutil.GetHistogramMetricValue(queryRows.WithLabelValues("resources"))
+	if err != nil {
+		t.Fatalf("get query rows failed: %v", err)
+	}
+	if rows != 2 {
+		t.Errorf("expected 2 rows, but got %v", rows)
+	}
+
+	slow, err := testutil.GetCounterMetricValue(slowQueriesTotal.WithLabelValues("resources", "query"))
+	if err != nil {
+		t.Fatalf("get slow queries failed: %v", err)
+	}
+	// the list and the queries of the owned resources after each create
+	if slow != 3 {
+		t.Errorf("expected 3 slow queries, but got %v", slow)
+	}
+}
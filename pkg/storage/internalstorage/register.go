@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/jackc/pgx/v4/stdlib"
@@ -13,8 +14,11 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 	gmysql "gorm.io/driver/mysql"
 	gpostgres "gorm.io/driver/postgres"
+	gsqlite "gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
 )
@@ -59,6 +63,8 @@ func NewStorageFactory(configPath string) (storage.StorageFactory, error) {
 		}
 
 		dialector = gpostgres.New(gpostgres.Config{Conn: stdlib.OpenDB(*pgconfig)})
+	case "sqlite":
+		dialector = gsqlite.Open(cfg.genSQLiteDSN())
 	default:
 		return nil, fmt.Errorf("not support storage type: %s", cfg.Type)
 	}
@@ -84,11 +90,53 @@ func NewStorageFactory(configPath string) (storage.StorageFactory, error) {
 	sqlDB.SetMaxOpenConns(connPool.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(connPool.ConnMaxLifetime)
 
+	if err := db.Use(&metricsPlugin{slowThreshold: cfg.getSlowThreshold()}); err != nil {
+		return nil, err
+	}
+	registerMetricsForDB(sqlDB)
+
 	if err := db.AutoMigrate(&Resource{}); err != nil {
 		return nil, err
 	}
+	if err := migrateTombstones(db); err != nil {
+		return nil, err
+	}
+	if err := migrateInvolvedUIDs(db); err != nil {
+		return nil, err
+	}
+	if cfg.FullTextSearch {
+		if err := migrateFullTextIndex(db); err != nil {
+			return nil, err
+		}
+	}
+
+	factory := &StorageFactory{
+		db:             db,
+		watch:          cfg.getWatchConfig(),
+		history:        cfg.getHistoryConfig(),
+		softDelete:     cfg.getSoftDeleteConfig(),
+		fullTextSearch: cfg.FullTextSearch,
+	}
+	if factory.watch != nil {
+		if err := db.AutoMigrate(&ResourceEvent{}); err != nil {
+			return nil, err
+		}
+
+		go wait.Until(factory.cleanExpiredResourceEvents, time.Minute, wait.NeverStop)
+	}
 
-	return &StorageFactory{db}, nil
+	if factory.history != nil {
+		if err := db.AutoMigrate(&ResourceHistory{}); err != nil {
+			return nil, err
+		}
+
+		go wait.Until(factory.cleanExpiredResourceHistories, time.Minute, wait.NeverStop)
+	}
+
+	if factory.softDelete != nil {
+		go wait.Until(factory.cleanExpiredTombstones, time.Minute, wait.NeverStop)
+	}
+	return factory, nil
 }
 
 func newLogger(cfg *Config) (logger.Interface, error) {
@@ -120,3 +168,48 @@ func newLogger(cfg *Config) (logger.Interface, error) {
 
 	return logger.New(log.New(logWriter, "", log.LstdFlags), loggerConfig), nil
 }
+
+// legacyResourceUniqueIndex is the unique index without `tombstone_id`,
+// which prevents keeping the tombstones of the resources with the same name.
+const legacyResourceUniqueIndex = "uni_group_version_resource_cluster_namespace_name"
+
+// migrateTombstones drops the legacy unique index of the resources,
+// and moves the existing tombstones out of the unique key of the live resources.
+func migrateTombstones(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if migrator.HasIndex(&Resource{}, legacyResourceUniqueIndex) {
+		if err := migrator.DropIndex(&Resource{}, legacyResourceUniqueIndex); err != nil {
+			return fmt.Errorf("failed to drop the legacy unique index: %w", err)
+		}
+	}
+
+	result := db.Model(&Resource{}).Where("removed_at IS NOT NULL AND tombstone_id = 0").Update("tombstone_id", gorm.Expr("id"))
+	if result.Error != nil {
+		return fmt.Errorf("failed to migrate the tombstones: %w", result.Error)
+	}
+	return nil
+}
+
+// migrateInvolvedUIDs fills the involved uids of the events stored before the column is added.
+func migrateInvolvedUIDs(db *gorm.DB) error {
+	var resources []Resource
+	result := db.Select("id", "group", "resource", "object").
+		Where(map[string]interface{}{"group": []string{"", "events.k8s.io"}, "resource": "events"}).Where("involved_uid = ''").
+		FindInBatches(&resources, 500, func(tx *gorm.DB, _ int) error {
+			for _, resource := range resources {
+				gr := schema.GroupResource{Group: resource.Group, Resource: resource.Resource}
+				uid, err := involvedObjectUID(gr, resource.Object)
+				if err != nil || uid == "" {
+					continue
+				}
+				if result := db.Model(&Resource{}).Where("id = ?", resource.ID).Update("involved_uid", uid); result.Error != nil {
+					return result.Error
+				}
+			}
+			return nil
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to migrate the involved uids of the events: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package internalstorage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+// maxRelatedResourceSeniority limits the depth of the owned resources,
+// such as the pods owned by the replicasets of a deployment.
+const maxRelatedResourceSeniority = 3
+
+type relatedResourceKey struct {
+	cluster string
+	uid     types.UID
+}
+
+// ownedResource is the resource found by the owner_uid index when resolving the related resources.
+type ownedResource struct {
+	ID       uint
+	Group    string
+	Resource string
+	Cluster  string
+	OwnerUID types.UID
+	UID      types.UID
+}
+
+// ResolveRelatedResources attaches the related resources to each object, the related resources are
+// the resources owned by the object directly or indirectly, and the events involving the object or its owned resources.
+func (s *ResourceStorage) ResolveRelatedResources(ctx context.Context, obj runtime.Object, opts *internal.ListOptions) error {
+	if len(opts.RelatedResources) == 0 {
+		return nil
+	}
+
+	objs := []runtime.Object{obj}
+	if meta.IsListType(obj) {
+		var err error
+		if objs, err = meta.ExtractList(obj); err != nil {
+			return err
+		}
+	}
+
+	// roots maps the resources to the requested objects which own them
+	roots := make(map[relatedResourceKey]relatedResourceKey, len(objs))
+	clusters := sets.NewString()
+	var parents []string
+	for _, o := range objs {
+		metaobj, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		key := relatedResourceKey{cluster: utils.ExtractClusterName(o), uid: metaobj.GetUID()}
+		if key.cluster == "" || key.uid == "" {
+			continue
+		}
+		roots[key] = key
+		clusters.Insert(key.cluster)
+		parents = append(parents, string(key.uid))
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+
+	var events []schema.GroupResource
+	related := make(map[schema.GroupResource]bool, len(opts.RelatedResources))
+	for _, gr := range opts.RelatedResources {
+		if isEventResource(gr) {
+			events = append(events, gr)
+			continue
+		}
+		related[gr] = true
+	}
+
+	db := s.db.WithContext(ctx)
+	var ids []uint
+	for seniority := 0; seniority < maxRelatedResourceSeniority && len(parents) != 0; seniority++ {
+		var owned []ownedResource
+		result := db.Model(&Resource{}).Select("id", "group", "resource", "cluster", "owner_uid", "uid").
+			Where("owner_uid IN (?)", parents).Where("cluster IN (?)", clusters.List()).
+			Where("removed_at IS NULL").Find(&owned)
+		if result.Error != nil {
+			return InterpretDBError(s.storageGroupResource.String(), result.Error)
+		}
+
+		parents = nil
+		for _, resource := range owned {
+			root, ok := roots[relatedResourceKey{cluster: resource.Cluster, uid: resource.OwnerUID}]
+			if !ok {
+				// the owner with the same uid is in another cluster
+				continue
+			}
+			key := relatedResourceKey{cluster: resource.Cluster, uid: resource.UID}
+			if _, ok := roots[key]; ok {
+				continue
+			}
+			roots[key] = root
+			parents = append(parents, string(resource.UID))
+
+			if related[schema.GroupResource{Group: resource.Group, Resource: resource.Resource}] {
+				ids = append(ids, resource.ID)
+			}
+		}
+	}
+
+	var resources []Resource
+	if len(ids) != 0 {
+		if result := db.Where("id IN (?)", ids).Order("id").Find(&resources); result.Error != nil {
+			return InterpretDBError(s.storageGroupResource.String(), result.Error)
+		}
+	}
+
+	relatedObjects := make(map[relatedResourceKey][]interface{}, len(objs))
+	for _, resource := range resources {
+		root := roots[relatedResourceKey{cluster: resource.Cluster, uid: resource.UID}]
+		object, err := relatedObject(resource, opts.OnlyMetadata)
+		if err != nil {
+			return err
+		}
+		relatedObjects[root] = append(relatedObjects[root], object)
+	}
+
+	if len(events) != 0 {
+		uids := make([]string, 0, len(roots))
+		for key := range roots {
+			uids = append(uids, string(key.uid))
+		}
+		sort.Strings(uids)
+
+		for _, gr := range events {
+			var resources []Resource
+			result := db.Where(map[string]interface{}{"group": gr.Group, "resource": gr.Resource}).
+				Where("involved_uid IN (?)", uids).Where("cluster IN (?)", clusters.List()).
+				Where("removed_at IS NULL").Order("id").Find(&resources)
+			if result.Error != nil {
+				return InterpretDBError(s.storageGroupResource.String(), result.Error)
+			}
+
+			for _, resource := range resources {
+				root, ok := roots[relatedResourceKey{cluster: resource.Cluster, uid: resource.InvolvedUID}]
+				if !ok {
+					continue
+				}
+
+				object, err := relatedObject(resource, opts.OnlyMetadata)
+				if err != nil {
+					return err
+				}
+				relatedObjects[root] = append(relatedObjects[root], object)
+			}
+		}
+	}
+
+	for _, o := range objs {
+		metaobj, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+
+		objects := relatedObjects[relatedResourceKey{cluster: utils.ExtractClusterName(o), uid: metaobj.GetUID()}]
+		if objects == nil {
+			objects = []interface{}{}
+		}
+		data, err := json.Marshal(objects)
+		if err != nil {
+			return err
+		}
+
+		annotations := metaobj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 1)
+		}
+		annotations[internal.ShadowAnnotationRelatedResources] = string(data)
+		metaobj.SetAnnotations(annotations)
+	}
+	return nil
+}
+
+func isEventResource(gr schema.GroupResource) bool {
+	return gr.Resource == "events" && (gr.Group == "" || gr.Group == "events.k8s.io")
+}
+
+// involvedObjectUID returns the uid of the object involved by the event,
+// it is empty if the resource is not the event.
+func involvedObjectUID(gr schema.GroupResource, object []byte) (types.UID, error) {
+	if !isEventResource(gr) {
+		return "", nil
+	}
+
+	var event struct {
+		InvolvedObject struct {
+			UID types.UID `json:"uid"`
+		} `json:"involvedObject"`
+		Regarding struct {
+			UID types.UID `json:"uid"`
+		} `json:"regarding"`
+	}
+	if err := json.Unmarshal(object, &event); err != nil {
+		return "", err
+	}
+	if gr.Group == "events.k8s.io" {
+		return event.Regarding.UID, nil
+	}
+	return event.InvolvedObject.UID, nil
+}
+
+func relatedObject(resource Resource, onlyMetadata bool) (interface{}, error) {
+	if !onlyMetadata {
+		return json.RawMessage(resource.Object), nil
+	}
+
+	obj, err := resource.ConvertToUnstructured()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"apiVersion": obj.GetAPIVersion(),
+		"kind":       obj.GetKind(),
+		"metadata":   obj.Object["metadata"],
+	}, nil
+}
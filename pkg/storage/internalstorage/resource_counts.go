@@ -0,0 +1,143 @@
+package internalstorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/fields"
+)
+
+// GroupByLabelPrefix is the prefix of the group by label key, such as `labels.app.kubernetes.io/name`
+const GroupByLabelPrefix = "labels."
+
+var groupByColumns = map[string]string{
+	"cluster":   "cluster",
+	"namespace": "namespace",
+	"kind":      "kind",
+
+	"rootOwnerKind": "root_owner_kind",
+	"rootOwnerName": "root_owner_name",
+}
+
+// groupByExpression returns the expression of the group by, which can be
+// a column, a label key prefixed with `labels.`, or the path of the enhanced field selector.
+func groupByExpression(groupBy string) (clause.Expression, error) {
+	if column, ok := groupByColumns[groupBy]; ok {
+		return clause.Expr{SQL: "?", Vars: []interface{}{clause.Column{Name: column}}}, nil
+	}
+
+	if strings.HasPrefix(groupBy, GroupByLabelPrefix) {
+		key := strings.TrimPrefix(groupBy, GroupByLabelPrefix)
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return nil, errors.New(strings.Join(errs, "; "))
+		}
+		return JSONValue("object", "metadata", "labels", key), nil
+	}
+
+	fields, err := fields.ParseFields(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.IsList() {
+			return nil, fmt.Errorf("Storage<%s>: Not Support list field", StorageName)
+		}
+		keys = append(keys, field.Name())
+	}
+	return JSONValue("object", keys...), nil
+}
+
+// applyGroupByToQuery selects the group by values and the count of each group,
+// the groups are sorted by the count in descending order.
+func applyGroupByToQuery(query *gorm.DB, groupBy []string) (*gorm.DB, error) {
+	selects := make([]string, 0, len(groupBy)+1)
+	positions := make([]string, 0, len(groupBy))
+	vars := make([]interface{}, 0, len(groupBy))
+	for i, g := range groupBy {
+		expression, err := groupByExpression(g)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid group by %q: %v", g, err))
+		}
+
+		selects = append(selects, "?")
+		positions = append(positions, strconv.Itoa(i+1))
+		vars = append(vars, expression)
+	}
+	query = query.Select(strings.Join(append(selects, "COUNT(*)"), ", "), vars...)
+	if len(positions) == 0 {
+		return query, nil
+	}
+
+	// Use the positions of the select list,
+	// because the json expressions with the parameters can not be repeated in `GROUP BY`.
+	query = query.Clauses(clause.GroupBy{Columns: []clause.Column{{Name: strings.Join(positions, ", "), Raw: true}}})
+	query = query.Order(fmt.Sprintf("%d DESC", len(groupBy)+1))
+	for _, position := range positions {
+		query = query.Order(position)
+	}
+	return query, nil
+}
+
+func (s *ResourceStorage) Count(ctx context.Context, opts *internal.ListOptions) (*internal.ResourceCounts, error) {
+	// Pagination and sorting are not used by counting.
+	countOpts := *opts
+	countOpts.Limit, countOpts.Continue, countOpts.OrderBy = 0, "", nil
+	countOpts.WithContinue, countOpts.WithRemainingCount = nil, nil
+
+	query, err := s.genResourceQuery(ctx, &countOpts)
+	if err != nil {
+		return nil, err
+	}
+	_, _, query, err = applyListOptionsToResourceQuery(s.db, query, &countOpts)
+	if err != nil {
+		return nil, err
+	}
+	if query, err = applyGroupByToQuery(query, opts.GroupBy); err != nil {
+		return nil, err
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, InterpretDBError(s.storageGroupResource.String(), err)
+	}
+	defer rows.Close()
+
+	counts := &internal.ResourceCounts{GroupBy: opts.GroupBy}
+	for rows.Next() {
+		var count internal.ResourceCount
+		values := make([]sql.NullString, len(opts.GroupBy))
+		dest := make([]interface{}, 0, len(values)+1)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(append(dest, &count.Count)...); err != nil {
+			return nil, InterpretDBError(s.storageGroupResource.String(), err)
+		}
+
+		for i, value := range values {
+			if !value.Valid {
+				continue
+			}
+			if count.Values == nil {
+				count.Values = make(map[string]string, len(values))
+			}
+			count.Values[opts.GroupBy[i]] = value.String
+		}
+		counts.Items = append(counts.Items, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, InterpretDBError(s.storageGroupResource.String(), err)
+	}
+	return counts, nil
+}
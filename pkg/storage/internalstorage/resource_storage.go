@@ -7,16 +7,16 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"gorm.io/gorm/clause"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	genericstorage "k8s.io/apiserver/pkg/storage"
 
@@ -25,8 +25,13 @@ import (
 )
 
 type ResourceStorage struct {
-	db    *gorm.DB
-	codec runtime.Codec
+	db         *gorm.DB
+	codec      runtime.Codec
+	watch      *WatchConfig
+	history    *HistoryConfig
+	softDelete *SoftDeleteConfig
+
+	fullTextSearch bool
 
 	storageGroupResource schema.GroupResource
 	storageVersion       schema.GroupVersion
@@ -42,30 +47,24 @@ func (s *ResourceStorage) GetStorageConfig() *storage.ResourceStorageConfig {
 	}
 }
 
-func (s *ResourceStorage) Create(ctx context.Context, cluster string, obj runtime.Object) error {
+func (s *ResourceStorage) newResource(cluster string, obj runtime.Object) (*Resource, error) {
 	gvk := obj.GetObjectKind().GroupVersionKind()
 	if gvk.Kind == "" {
-		return fmt.Errorf("%s: kind is required", gvk)
+		return nil, fmt.Errorf("%s: kind is required", gvk)
 	}
 
 	metaobj, err := meta.Accessor(obj)
 	if err != nil {
-		return err
-	}
-
-	var ownerUID types.UID
-	if owner := metav1.GetControllerOfNoCopy(metaobj); owner != nil {
-		ownerUID = owner.UID
+		return nil, err
 	}
 
 	var buffer bytes.Buffer
 	if err := s.codec.Encode(obj, &buffer); err != nil {
-		return err
+		return nil, err
 	}
 
-	resource := Resource{
+	resource := &Resource{
 		Cluster:         cluster,
-		OwnerUID:        ownerUID,
 		UID:             metaobj.GetUID(),
 		Name:            metaobj.GetName(),
 		Namespace:       metaobj.GetNamespace(),
@@ -77,12 +76,41 @@ func (s *ResourceStorage) Create(ctx context.Context, cluster string, obj runtim
 		Object:          buffer.Bytes(),
 		CreatedAt:       metaobj.GetCreationTimestamp().Time,
 	}
+	if owner := metav1.GetControllerOfNoCopy(metaobj); owner != nil {
+		// the direct owner is the root owner until the stored owners are resolved
+		resource.OwnerUID = owner.UID
+		resource.RootOwnerKind, resource.RootOwnerName, resource.RootOwnerUID = owner.Kind, owner.Name, owner.UID
+	}
 	if deletedAt := metaobj.GetDeletionTimestamp(); deletedAt != nil {
 		resource.DeletedAt = sql.NullTime{Time: deletedAt.Time, Valid: true}
 	}
+	if resource.InvolvedUID, err = involvedObjectUID(s.storageGroupResource, resource.Object); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
 
-	result := s.db.WithContext(ctx).Create(&resource)
-	return InterpretResourceDBError(cluster, metaobj.GetName(), result.Error)
+func (s *ResourceStorage) Create(ctx context.Context, cluster string, obj runtime.Object) error {
+	resource, err := s.newResource(cluster, obj)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := resolveRootOwners(tx, cluster, resource); err != nil {
+			return err
+		}
+		if result := tx.Create(resource); result.Error != nil {
+			return result.Error
+		}
+		if s.recordChanges() {
+			if err := s.recordChange(tx, watch.Added, resource); err != nil {
+				return err
+			}
+		}
+		return s.propagateRootOwners(tx, cluster, resource)
+	})
+	return InterpretResourceDBError(cluster, resource.Name, err)
 }
 
 func (s *ResourceStorage) Update(ctx context.Context, cluster string, obj runtime.Object) error {
@@ -96,43 +124,98 @@ func (s *ResourceStorage) Update(ctx context.Context, cluster string, obj runtim
 		return err
 	}
 
-	var ownerUID types.UID
+	resource := &Resource{
+		Kind: obj.GetObjectKind().GroupVersionKind().Kind,
+		Name: metaobj.GetName(),
+		UID:  metaobj.GetUID(),
+	}
 	if owner := metav1.GetControllerOfNoCopy(metaobj); owner != nil {
-		ownerUID = owner.UID
+		resource.OwnerUID = owner.UID
+		resource.RootOwnerKind, resource.RootOwnerName, resource.RootOwnerUID = owner.Kind, owner.Name, owner.UID
 	}
 
-	// The uid may not be the same for resources with the same namespace/name
-	// in the same cluster at different times.
-	updatedResource := map[string]interface{}{
-		"owner_uid":        ownerUID,
-		"uid":              metaobj.GetUID(),
-		"resource_version": metaobj.GetResourceVersion(),
-		"object":           buffer.Bytes(),
-	}
-	if deletedAt := metaobj.GetDeletionTimestamp(); deletedAt != nil {
-		updatedResource["deleted_at"] = sql.NullTime{Time: deletedAt.Time, Valid: true}
+	if resource.InvolvedUID, err = involvedObjectUID(s.storageGroupResource, buffer.Bytes()); err != nil {
+		return err
 	}
 
-	result := s.db.WithContext(ctx).Model(&Resource{}).Where(map[string]interface{}{
-		"cluster":   cluster,
-		"group":     s.storageGroupResource.Group,
-		"version":   s.storageVersion.Version,
-		"resource":  s.storageGroupResource.Resource,
-		"namespace": metaobj.GetNamespace(),
-		"name":      metaobj.GetName(),
-	}).Updates(updatedResource)
-	return InterpretResourceDBError(cluster, metaobj.GetName(), result.Error)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := resolveRootOwners(tx, cluster, resource); err != nil {
+			return err
+		}
+
+		// The uid may not be the same for resources with the same namespace/name
+		// in the same cluster at different times.
+		updatedResource := map[string]interface{}{
+			"owner_uid":        resource.OwnerUID,
+			"root_owner_kind":  resource.RootOwnerKind,
+			"root_owner_name":  resource.RootOwnerName,
+			"root_owner_uid":   resource.RootOwnerUID,
+			"involved_uid":     resource.InvolvedUID,
+			"uid":              metaobj.GetUID(),
+			"resource_version": metaobj.GetResourceVersion(),
+			"object":           buffer.Bytes(),
+		}
+		if deletedAt := metaobj.GetDeletionTimestamp(); deletedAt != nil {
+			updatedResource["deleted_at"] = sql.NullTime{Time: deletedAt.Time, Valid: true}
+		}
+
+		result := s.objectQuery(tx, cluster, metaobj.GetNamespace(), metaobj.GetName()).Where("removed_at IS NULL").Updates(updatedResource)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if s.recordChanges() {
+			err := s.recordChange(tx, watch.Modified, &Resource{
+				Cluster:         cluster,
+				OwnerUID:        resource.OwnerUID,
+				RootOwnerKind:   resource.RootOwnerKind,
+				RootOwnerName:   resource.RootOwnerName,
+				RootOwnerUID:    resource.RootOwnerUID,
+				UID:             metaobj.GetUID(),
+				Name:            metaobj.GetName(),
+				Namespace:       metaobj.GetNamespace(),
+				Group:           s.storageGroupResource.Group,
+				Resource:        s.storageGroupResource.Resource,
+				Version:         s.storageVersion.Version,
+				Kind:            obj.GetObjectKind().GroupVersionKind().Kind,
+				ResourceVersion: metaobj.GetResourceVersion(),
+				Object:          buffer.Bytes(),
+				CreatedAt:       metaobj.GetCreationTimestamp().Time,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return s.propagateRootOwners(tx, cluster, resource)
+	})
+	return InterpretResourceDBError(cluster, metaobj.GetName(), err)
 }
 
-func (s *ResourceStorage) deleteObject(cluster, namespace, name string) *gorm.DB {
-	return s.db.Model(&Resource{}).Where(map[string]interface{}{
+func (s *ResourceStorage) objectQuery(db *gorm.DB, cluster, namespace, name string) *gorm.DB {
+	return db.Model(&Resource{}).Where(map[string]interface{}{
 		"cluster":   cluster,
 		"group":     s.storageGroupResource.Group,
 		"version":   s.storageVersion.Version,
 		"resource":  s.storageGroupResource.Resource,
 		"namespace": namespace,
 		"name":      name,
-	}).Delete(&Resource{})
+	})
+}
+
+func (s *ResourceStorage) deleteObject(cluster, namespace, name string) *gorm.DB {
+	return s.deleteResources(s.objectQuery(s.db, cluster, namespace, name))
+}
+
+// deleteResources deletes the resources, or keeps them as the tombstones if the soft deletion is enabled,
+// the tombstones are moved out of the unique key of the live resources by `tombstone_id`.
+func (s *ResourceStorage) deleteResources(query *gorm.DB) *gorm.DB {
+	if s.softDelete != nil {
+		return query.Where("removed_at IS NULL").Updates(map[string]interface{}{
+			"removed_at":   time.Now().UTC(),
+			"tombstone_id": gorm.Expr("id"),
+		})
+	}
+	return query.Delete(&Resource{})
 }
 
 func (s *ResourceStorage) Delete(ctx context.Context, cluster string, obj runtime.Object) error {
@@ -141,21 +224,182 @@ func (s *ResourceStorage) Delete(ctx context.Context, cluster string, obj runtim
 		return err
 	}
 
-	if result := s.deleteObject(cluster, metaobj.GetNamespace(), metaobj.GetName()); result.Error != nil {
-		return InterpretResourceDBError(cluster, metaobj.GetName(), result.Error)
+	if !s.recordChanges() {
+		if result := s.deleteObject(cluster, metaobj.GetNamespace(), metaobj.GetName()); result.Error != nil {
+			return InterpretResourceDBError(cluster, metaobj.GetName(), result.Error)
+		}
+		return nil
 	}
-	return nil
+
+	// The deleted object passed in by the synchro only has the namespace and name,
+	// so the last state of the resource in the storage is used for the delete event.
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var resources []Resource
+		result := s.objectQuery(tx, cluster, metaobj.GetNamespace(), metaobj.GetName()).Where("removed_at IS NULL").Limit(1).Find(&resources)
+		if result.Error != nil || len(resources) == 0 {
+			return result.Error
+		}
+
+		if result := s.deleteResources(tx.Model(&Resource{}).Where("id = ?", resources[0].ID)); result.Error != nil {
+			return result.Error
+		}
+		return s.recordChange(tx, watch.Deleted, &resources[0])
+	})
+	return InterpretResourceDBError(cluster, metaobj.GetName(), err)
 }
 
-func (s *ResourceStorage) genGetObjectQuery(ctx context.Context, cluster, namespace, name string) *gorm.DB {
-	return s.db.WithContext(ctx).Model(&Resource{}).Select("object").Where(map[string]interface{}{
-		"cluster":   cluster,
-		"group":     s.storageGroupResource.Group,
-		"version":   s.storageVersion.Version,
-		"resource":  s.storageGroupResource.Resource,
-		"namespace": namespace,
-		"name":      name,
+// upsertBatchSize is the number of the rows in a multi-row insert statement,
+// which keeps the number of the placeholders under the limit of sqlite.
+const upsertBatchSize = 50
+
+// upsertedColumns are the columns overwritten by the upsert,
+// `resource_version` must be the last one since MySQL uses the updated values in the following assignments.
+var upsertedColumns = []string{
+	"kind", "owner_uid", "root_owner_kind", "root_owner_name", "root_owner_uid", "involved_uid", "uid", "object",
+	"created_at", "synced_at", "deleted_at", "resource_version",
+}
+
+// notOlderResourceVersion returns the condition that the resource version `incoming` is not older than `stored`,
+// the resource versions are decimal numbers in the string columns, so they are compared by the length first.
+func notOlderResourceVersion(incoming, stored string) string {
+	return fmt.Sprintf("(LENGTH(%[1]s) > LENGTH(%[2]s) OR (LENGTH(%[1]s) = LENGTH(%[2]s) AND %[1]s >= %[2]s))", incoming, stored)
+}
+
+// upsertClause inserts the resource, or updates the resource with the same unique key
+// only if the resource version of the inserted one is not older, so that the out-of-order writes don't regress the resource.
+// The resource with the same resource version is rewritten, because the relist after changing the transforms
+// writes the same resource versions with the newly transformed objects.
+// The tombstones are not in conflict with the inserted resource, because their `tombstone_id` is not 0.
+func (s *ResourceStorage) upsertClause() clause.OnConflict {
+	onConflict := clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "group"}, {Name: "version"}, {Name: "resource"},
+			{Name: "cluster"}, {Name: "namespace"}, {Name: "name"}, {Name: "tombstone_id"},
+		},
+	}
+
+	// MySQL doesn't support the conditional `ON DUPLICATE KEY UPDATE`,
+	// so each column keeps its value if the resource version is older.
+	if s.db.Dialector.Name() == "mysql" {
+		newer := notOlderResourceVersion("VALUES(`resource_version`)", "`resource_version`")
+		for _, column := range upsertedColumns {
+			onConflict.DoUpdates = append(onConflict.DoUpdates, clause.Assignment{
+				Column: clause.Column{Name: column},
+				Value:  clause.Expr{SQL: fmt.Sprintf("IF(%s, VALUES(`%s`), `%s`)", newer, column, column)},
+			})
+		}
+		return onConflict
+	}
+
+	onConflict.DoUpdates = clause.AssignmentColumns(upsertedColumns)
+	onConflict.Where = clause.Where{Exprs: []clause.Expression{
+		clause.Expr{SQL: notOlderResourceVersion("excluded.resource_version", "resources.resource_version")},
+	}}
+	return onConflict
+}
+
+// Upsert creates the resource or updates the stored one if its resource version is not newer than `obj`.
+func (s *ResourceStorage) Upsert(ctx context.Context, cluster string, obj runtime.Object) error {
+	resource, err := s.newResource(cluster, obj)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := resolveRootOwners(tx, cluster, resource); err != nil {
+			return err
+		}
+
+		var existing int64
+		if s.recordChanges() {
+			if result := s.objectQuery(tx, cluster, resource.Namespace, resource.Name).Where("removed_at IS NULL").Count(&existing); result.Error != nil {
+				return result.Error
+			}
+		}
+
+		// no rows are affected if the stored resource is newer
+		result := tx.Clauses(s.upsertClause()).Create(resource)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if s.recordChanges() {
+			eventType := watch.Added
+			if existing != 0 {
+				eventType = watch.Modified
+			}
+			if err := s.recordChange(tx, eventType, resource); err != nil {
+				return err
+			}
+		}
+		return s.propagateRootOwners(tx, cluster, resource)
+	})
+	return InterpretResourceDBError(cluster, resource.Name, err)
+}
+
+// BatchWrite writes the changes of the resources in a transaction,
+// the created or updated resources are written by the multi-row upserts.
+//
+// The type of the watch event and the history of each change depend on the stored resource,
+// so the changes are written one by one when the changes are recorded.
+func (s *ResourceStorage) BatchWrite(ctx context.Context, cluster string, changes []storage.ResourceChange) error {
+	if s.recordChanges() {
+		for _, change := range changes {
+			if err := s.writeChange(ctx, cluster, change); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var resources []*Resource
+	var deleted []metav1.Object
+	for _, change := range changes {
+		if change.Deleted {
+			metaobj, err := meta.Accessor(change.Object)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, metaobj)
+			continue
+		}
+
+		resource, err := s.newResource(cluster, change.Object)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, resource)
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(resources) != 0 {
+			if err := resolveRootOwners(tx, cluster, resources...); err != nil {
+				return err
+			}
+			if result := tx.Clauses(s.upsertClause()).CreateInBatches(resources, upsertBatchSize); result.Error != nil {
+				return result.Error
+			}
+		}
+
+		for _, metaobj := range deleted {
+			if result := s.deleteResources(s.objectQuery(tx, cluster, metaobj.GetNamespace(), metaobj.GetName())); result.Error != nil {
+				return result.Error
+			}
+		}
+		return s.propagateRootOwners(tx, cluster, resources...)
 	})
+	return InterpretDBError(cluster, err)
+}
+
+func (s *ResourceStorage) writeChange(ctx context.Context, cluster string, change storage.ResourceChange) error {
+	if change.Deleted {
+		return s.Delete(ctx, cluster, change.Object)
+	}
+	return s.Upsert(ctx, cluster, change.Object)
+}
+
+func (s *ResourceStorage) genGetObjectQuery(ctx context.Context, cluster, namespace, name string) *gorm.DB {
+	return s.objectQuery(s.db.WithContext(ctx), cluster, namespace, name).Select("object").Where("removed_at IS NULL")
 }
 
 func (s *ResourceStorage) Get(ctx context.Context, cluster, namespace, name string, into runtime.Object) error {
@@ -180,13 +424,11 @@ func (s *ResourceStorage) genListObjectsQuery(ctx context.Context, opts *interna
 		result = &ResourceMetadataList{}
 	}
 
-	query := s.db.WithContext(ctx).Model(&Resource{})
-	query = result.Select(query).Where(map[string]interface{}{
-		"group":    s.storageGroupResource.Group,
-		"version":  s.storageVersion.Version,
-		"resource": s.storageGroupResource.Resource,
-	})
-	offset, amount, query, err := applyListOptionsToResourceQuery(s.db, query, opts)
+	query, err := s.genResourceQuery(ctx, opts)
+	if err != nil {
+		return 0, nil, nil, nil, err
+	}
+	offset, amount, query, err := applyListOptionsToResourceQuery(s.db, result.Select(query), opts)
 	return offset, amount, query, result, err
 }
 
@@ -196,19 +438,38 @@ func (s *ResourceStorage) List(ctx context.Context, listObject runtime.Object, o
 		return err
 	}
 
-	if err := result.From(query); err != nil {
-		return InterpretDBError(s.storageGroupResource.String(), err)
-	}
-	objects := result.Items()
-
 	list, err := meta.ListAccessor(listObject)
 	if err != nil {
 		return err
 	}
 
+	if s.watch != nil {
+		// Get the resource version before listing, the events after it will be
+		// sent by the watch even though they may already be in the list.
+		rv, err := s.latestEventResourceVersion(ctx)
+		if err != nil {
+			return InterpretDBError(s.storageGroupResource.String(), err)
+		}
+		list.SetResourceVersion(strconv.FormatUint(rv, 10))
+	}
+
+	if err := result.From(query); err != nil {
+		return InterpretDBError(s.storageGroupResource.String(), err)
+	}
+	objects := result.Items()
+
 	if opts.WithContinue != nil && *opts.WithContinue {
 		if int64(len(objects)) == opts.Limit {
-			list.SetContinue(strconv.FormatInt(offset+opts.Limit, 10))
+			if shouldUseKeysetContinue(opts) {
+				id, keys := result.ContinueKeys()
+				token, err := buildContinueToken(id, keys, opts.OrderBy)
+				if err != nil {
+					return InterpretDBError(s.storageGroupResource.String(), err)
+				}
+				list.SetContinue(token)
+			} else {
+				list.SetContinue(strconv.FormatInt(offset+opts.Limit, 10))
+			}
 		}
 	}
 
@@ -274,10 +535,6 @@ func (s *ResourceStorage) List(ctx context.Context, listObject runtime.Object, o
 	return nil
 }
 
-func (s *ResourceStorage) Watch(_ context.Context, _ *internal.ListOptions) (watch.Interface, error) {
-	return nil, apierrors.NewMethodNotSupported(s.storageGroupResource, "watch")
-}
-
 func applyListOptionsToResourceQuery(db *gorm.DB, query *gorm.DB, opts *internal.ListOptions) (int64, *int64, *gorm.DB, error) {
 	applyFn := func(query *gorm.DB, opts *internal.ListOptions) (*gorm.DB, error) {
 		query, err := applyOwnerToResourceQuery(db, query, opts)
@@ -285,6 +542,12 @@ func applyListOptionsToResourceQuery(db *gorm.DB, query *gorm.DB, opts *internal
 			return nil, err
 		}
 
+		if opts.RootOwnerName != "" {
+			query = query.Where("root_owner_name = ?", opts.RootOwnerName)
+			if opts.RootOwnerKind != "" {
+				query = query.Where("root_owner_kind = ?", opts.RootOwnerKind)
+			}
+		}
 		return query, nil
 	}
 
@@ -294,11 +557,8 @@ func applyListOptionsToResourceQuery(db *gorm.DB, query *gorm.DB, opts *internal
 func applyOwnerToResourceQuery(db *gorm.DB, query *gorm.DB, opts *internal.ListOptions) (*gorm.DB, error) {
 	var ownerQuery interface{}
 	switch {
-	case len(opts.ClusterNames) != 1:
-		return query, nil
-
 	case opts.OwnerUID != "":
-		ownerQuery = buildOwnerQueryByUID(db, opts.ClusterNames[0], opts.OwnerUID, opts.OwnerSeniority)
+		ownerQuery = buildOwnerQueryByUID(db, opts.ClusterNames, opts.OwnerUID, opts.OwnerSeniority)
 
 	case opts.OwnerName != "":
 		var ownerNamespaces []string
@@ -306,16 +566,14 @@ func applyOwnerToResourceQuery(db *gorm.DB, query *gorm.DB, opts *internal.ListO
 			// match namespaced and clustered owner resources
 			ownerNamespaces = append(opts.Namespaces, "")
 		}
-		ownerQuery = buildOwnerQueryByName(db, opts.ClusterNames[0], ownerNamespaces, opts.OwnerGroupResource, opts.OwnerName, opts.OwnerSeniority)
+		ownerQuery = buildOwnerQueryByName(db, opts.ClusterNames, ownerNamespaces, opts.OwnerGroupResource, opts.OwnerName, opts.OwnerSeniority)
 
 	default:
 		return query, nil
 	}
 
 	if _, ok := ownerQuery.(string); ok {
-		query = query.Where("owner_uid = ?", ownerQuery)
-	} else {
-		query = query.Where("owner_uid IN (?)", ownerQuery)
+		return query.Where("owner_uid = ?", ownerQuery), nil
 	}
-	return query, nil
+	return whereOwnerIn(query, opts.ClusterNames, ownerQuery), nil
 }
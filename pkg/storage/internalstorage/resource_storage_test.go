@@ -3,6 +3,7 @@ package internalstorage
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"gorm.io/gorm"
@@ -71,8 +72,8 @@ func TestApplyListOptionsToResourceQuery_Owner(t *testing.T) {
 				OwnerSeniority: 1,
 			},
 			expected{
-				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT "uid" FROM "resources" WHERE "cluster" = 'cluster-1' AND owner_uid = 'owner-uid-1')`,
-				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT `uid` FROM `resources` WHERE `cluster` = 'cluster-1' AND owner_uid = 'owner-uid-1')",
+				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT "uid" FROM "resources" WHERE removed_at IS NULL AND "cluster" = 'cluster-1' AND owner_uid = 'owner-uid-1')`,
+				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT `uid` FROM `resources` WHERE removed_at IS NULL AND `cluster` = 'cluster-1' AND owner_uid = 'owner-uid-1')",
 				"",
 			},
 		},
@@ -83,8 +84,8 @@ func TestApplyListOptionsToResourceQuery_Owner(t *testing.T) {
 				OwnerName:    "owner-name-1",
 			},
 			expected{
-				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT "uid" FROM "resources" WHERE "cluster" = 'cluster-1' AND name = 'owner-name-1')`,
-				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT `uid` FROM `resources` WHERE `cluster` = 'cluster-1' AND name = 'owner-name-1')",
+				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT "uid" FROM "resources" WHERE removed_at IS NULL AND "cluster" = 'cluster-1' AND name = 'owner-name-1')`,
+				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT `uid` FROM `resources` WHERE removed_at IS NULL AND `cluster` = 'cluster-1' AND name = 'owner-name-1')",
 				"",
 			},
 		},
@@ -109,8 +110,8 @@ func TestApplyListOptionsToResourceQuery_Owner(t *testing.T) {
 				OwnerGroupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
 			},
 			expected{
-				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT "uid" FROM "resources" WHERE "cluster" = 'cluster-1' AND "group" = 'apps' AND "resource" = 'deployments' AND name = 'owner-name-1')`,
-				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT `uid` FROM `resources` WHERE `cluster` = 'cluster-1' AND `group` = 'apps' AND `resource` = 'deployments' AND name = 'owner-name-1')",
+				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT "uid" FROM "resources" WHERE removed_at IS NULL AND "cluster" = 'cluster-1' AND "group" = 'apps' AND "resource" = 'deployments' AND name = 'owner-name-1')`,
+				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND owner_uid IN (SELECT `uid` FROM `resources` WHERE removed_at IS NULL AND `cluster` = 'cluster-1' AND `group` = 'apps' AND `resource` = 'deployments' AND name = 'owner-name-1')",
 				"",
 			},
 		},
@@ -128,6 +129,19 @@ func TestApplyListOptionsToResourceQuery_Owner(t *testing.T) {
 			},
 		},
 
+		{
+			"root owner",
+			&internal.ListOptions{
+				RootOwnerKind: "Deployment",
+				RootOwnerName: "frontend",
+			},
+			expected{
+				`SELECT * FROM "resources" WHERE root_owner_name = 'frontend' AND root_owner_kind = 'Deployment'`,
+				"SELECT * FROM `resources` WHERE root_owner_name = 'frontend' AND root_owner_kind = 'Deployment'",
+				"",
+			},
+		},
+
 		// with clusters
 		{
 			"with multi clusters",
@@ -136,8 +150,34 @@ func TestApplyListOptionsToResourceQuery_Owner(t *testing.T) {
 				OwnerUID:     "owner-uid-1",
 			},
 			expected{
-				`SELECT * FROM "resources" WHERE cluster IN ('cluster-1','cluster-2')`,
-				"SELECT * FROM `resources` WHERE cluster IN ('cluster-1','cluster-2')",
+				`SELECT * FROM "resources" WHERE cluster IN ('cluster-1','cluster-2') AND owner_uid = 'owner-uid-1'`,
+				"SELECT * FROM `resources` WHERE cluster IN ('cluster-1','cluster-2') AND owner_uid = 'owner-uid-1'",
+				"",
+			},
+		},
+		{
+			"owner uid with seniority and multi clusters",
+			&internal.ListOptions{
+				ClusterNames:   []string{"cluster-1", "cluster-2"},
+				OwnerUID:       "owner-uid-1",
+				OwnerSeniority: 1,
+			},
+			expected{
+				`SELECT * FROM "resources" WHERE cluster IN ('cluster-1','cluster-2') AND (cluster, owner_uid) IN (SELECT "cluster","uid" FROM "resources" WHERE removed_at IS NULL AND cluster IN ('cluster-1','cluster-2') AND owner_uid = 'owner-uid-1')`,
+				"SELECT * FROM `resources` WHERE cluster IN ('cluster-1','cluster-2') AND (cluster, owner_uid) IN (SELECT `cluster`,`uid` FROM `resources` WHERE removed_at IS NULL AND cluster IN ('cluster-1','cluster-2') AND owner_uid = 'owner-uid-1')",
+				"",
+			},
+		},
+		{
+			"owner name with seniority and all clusters",
+			&internal.ListOptions{
+				OwnerName:          "owner-name-1",
+				OwnerGroupResource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+				OwnerSeniority:     1,
+			},
+			expected{
+				`SELECT * FROM "resources" WHERE (cluster, owner_uid) IN (SELECT "cluster","uid" FROM "resources" WHERE removed_at IS NULL AND (cluster, owner_uid) IN (SELECT "cluster","uid" FROM "resources" WHERE removed_at IS NULL AND "group" = 'apps' AND "resource" = 'deployments' AND name = 'owner-name-1'))`,
+				"SELECT * FROM `resources` WHERE (cluster, owner_uid) IN (SELECT `cluster`,`uid` FROM `resources` WHERE removed_at IS NULL AND (cluster, owner_uid) IN (SELECT `cluster`,`uid` FROM `resources` WHERE removed_at IS NULL AND `group` = 'apps' AND `resource` = 'deployments' AND name = 'owner-name-1'))",
 				"",
 			},
 		},
@@ -152,8 +192,8 @@ func TestApplyListOptionsToResourceQuery_Owner(t *testing.T) {
 				OwnerSeniority: 1,
 			},
 			expected{
-				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT "uid" FROM "resources" WHERE "cluster" = 'cluster-1' AND owner_uid = 'owner-uid-1')`,
-				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT `uid` FROM `resources` WHERE `cluster` = 'cluster-1' AND owner_uid = 'owner-uid-1')",
+				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT "uid" FROM "resources" WHERE removed_at IS NULL AND "cluster" = 'cluster-1' AND owner_uid = 'owner-uid-1')`,
+				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT `uid` FROM `resources` WHERE removed_at IS NULL AND `cluster` = 'cluster-1' AND owner_uid = 'owner-uid-1')",
 				"",
 			},
 		},
@@ -165,8 +205,8 @@ func TestApplyListOptionsToResourceQuery_Owner(t *testing.T) {
 				OwnerName:    "owner-name-1",
 			},
 			expected{
-				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT "uid" FROM "resources" WHERE "cluster" = 'cluster-1' AND namespace IN ('ns-1','ns-2','') AND name = 'owner-name-1')`,
-				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT `uid` FROM `resources` WHERE `cluster` = 'cluster-1' AND namespace IN ('ns-1','ns-2','') AND name = 'owner-name-1')",
+				`SELECT * FROM "resources" WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT "uid" FROM "resources" WHERE removed_at IS NULL AND "cluster" = 'cluster-1' AND namespace IN ('ns-1','ns-2','') AND name = 'owner-name-1')`,
+				"SELECT * FROM `resources` WHERE cluster = 'cluster-1' AND namespace IN ('ns-1','ns-2') AND owner_uid IN (SELECT `uid` FROM `resources` WHERE removed_at IS NULL AND `cluster` = 'cluster-1' AND namespace IN ('ns-1','ns-2','') AND name = 'owner-name-1')",
 				"",
 			},
 		},
@@ -193,8 +233,8 @@ func TestResourceStorage_genGetObjectQuery(t *testing.T) {
 			"",
 			"",
 			expected{
-				`SELECT "object" FROM "resources" WHERE "cluster" = '' AND "group" = '' AND "name" = '' AND "namespace" = '' AND "resource" = '' AND "version" = '' ORDER BY "resources"."id" LIMIT 1`,
-				"SELECT `object` FROM `resources` WHERE `cluster` = '' AND `group` = '' AND `name` = '' AND `namespace` = '' AND `resource` = '' AND `version` = '' ORDER BY `resources`.`id` LIMIT 1",
+				`SELECT "object" FROM "resources" WHERE "cluster" = '' AND "group" = '' AND "name" = '' AND "namespace" = '' AND "resource" = '' AND "version" = '' AND removed_at IS NULL ORDER BY "resources"."id" LIMIT 1`,
+				"SELECT `object` FROM `resources` WHERE `cluster` = '' AND `group` = '' AND `name` = '' AND `namespace` = '' AND `resource` = '' AND `version` = '' AND removed_at IS NULL ORDER BY `resources`.`id` LIMIT 1",
 				"",
 			},
 		},
@@ -205,8 +245,8 @@ func TestResourceStorage_genGetObjectQuery(t *testing.T) {
 			"ns-1",
 			"resource-1",
 			expected{
-				`SELECT "object" FROM "resources" WHERE "cluster" = 'cluster-1' AND "group" = 'apps' AND "name" = 'resource-1' AND "namespace" = 'ns-1' AND "resource" = 'deployments' AND "version" = 'v1' ORDER BY "resources"."id" LIMIT 1`,
-				"SELECT `object` FROM `resources` WHERE `cluster` = 'cluster-1' AND `group` = 'apps' AND `name` = 'resource-1' AND `namespace` = 'ns-1' AND `resource` = 'deployments' AND `version` = 'v1' ORDER BY `resources`.`id` LIMIT 1",
+				`SELECT "object" FROM "resources" WHERE "cluster" = 'cluster-1' AND "group" = 'apps' AND "name" = 'resource-1' AND "namespace" = 'ns-1' AND "resource" = 'deployments' AND "version" = 'v1' AND removed_at IS NULL ORDER BY "resources"."id" LIMIT 1`,
+				"SELECT `object` FROM `resources` WHERE `cluster` = 'cluster-1' AND `group` = 'apps' AND `name` = 'resource-1' AND `namespace` = 'ns-1' AND `resource` = 'deployments' AND `version` = 'v1' AND removed_at IS NULL ORDER BY `resources`.`id` LIMIT 1",
 				"",
 			},
 		},
@@ -250,8 +290,8 @@ func TestResourceStorage_genListObjectQuery(t *testing.T) {
 			appsv1.SchemeGroupVersion.WithResource("deployments"),
 			&internal.ListOptions{},
 			expected{
-				`SELECT "object" FROM "resources" WHERE "group" = 'apps' AND "resource" = 'deployments' AND "version" = 'v1'`,
-				"SELECT `object` FROM `resources` WHERE `group` = 'apps' AND `resource` = 'deployments' AND `version` = 'v1'",
+				`SELECT "object" FROM "resources" WHERE "group" = 'apps' AND "resource" = 'deployments' AND "version" = 'v1' AND removed_at IS NULL`,
+				"SELECT `object` FROM `resources` WHERE `group` = 'apps' AND `resource` = 'deployments' AND `version` = 'v1' AND removed_at IS NULL",
 				"",
 			},
 		},
@@ -358,6 +398,37 @@ func TestResourceStorage_deleteObject(t *testing.T) {
 	}
 }
 
+func TestResourceStorage_upsertClause(t *testing.T) {
+	upsert := func(tx *gorm.DB) *gorm.DB {
+		rs := newTestResourceStorage(tx, appsv1.SchemeGroupVersion.WithResource("deployments"))
+		return tx.Clauses(rs.upsertClause()).Create(&Resource{Cluster: "cluster-1", Namespace: "ns-1", Name: "resource-1", ResourceVersion: "10"})
+	}
+
+	// the inserted values contain the current time, only the upsert clause is compared
+	t.Run("postgres", func(t *testing.T) {
+		postgreSQL := postgresDB.Session(&gorm.Session{SkipDefaultTransaction: true}).ToSQL(upsert)
+		expected := `ON CONFLICT ("group","version","resource","cluster","namespace","name","tombstone_id") DO UPDATE SET "kind"="excluded"."kind","owner_uid"="excluded"."owner_uid","root_owner_kind"="excluded"."root_owner_kind","root_owner_name"="excluded"."root_owner_name","root_owner_uid"="excluded"."root_owner_uid","involved_uid"="excluded"."involved_uid","uid"="excluded"."uid","object"="excluded"."object","created_at"="excluded"."created_at","synced_at"="excluded"."synced_at","deleted_at"="excluded"."deleted_at","resource_version"="excluded"."resource_version" WHERE (LENGTH(excluded.resource_version) > LENGTH(resources.resource_version) OR (LENGTH(excluded.resource_version) = LENGTH(resources.resource_version) AND excluded.resource_version >= resources.resource_version))  RETURNING "id"`
+		if !strings.HasSuffix(postgreSQL, expected) {
+			t.Errorf("expected sql ends with: %q, but got: %q", expected, postgreSQL)
+		}
+	})
+
+	for version := range mysqlDBs {
+		t.Run(fmt.Sprintf("mysql-%s", version), func(t *testing.T) {
+			mysqlSQL := mysqlDBs[version].Session(&gorm.Session{SkipDefaultTransaction: true}).ToSQL(upsert)
+			if !strings.Contains(mysqlSQL, "ON DUPLICATE KEY UPDATE `kind`=IF(") {
+				t.Errorf("expected the conditional assignments, but got: %q", mysqlSQL)
+			}
+
+			// the condition of the other columns uses the stored resource version, so it is assigned last
+			expected := "`resource_version`=IF((LENGTH(VALUES(`resource_version`)) > LENGTH(`resource_version`) OR (LENGTH(VALUES(`resource_version`)) = LENGTH(`resource_version`) AND VALUES(`resource_version`) >= `resource_version`)), VALUES(`resource_version`), `resource_version`)"
+			if !strings.HasSuffix(mysqlSQL, expected) {
+				t.Errorf("expected sql ends with: %q, but got: %q", expected, mysqlSQL)
+			}
+		})
+	}
+}
+
 func newTestResourceStorage(db *gorm.DB, storageGVK schema.GroupVersionResource) *ResourceStorage {
 	return &ResourceStorage{
 		db:                   db,
@@ -365,3 +436,70 @@ func newTestResourceStorage(db *gorm.DB, storageGVK schema.GroupVersionResource)
 		storageVersion:       storageGVK.GroupVersion(),
 	}
 }
+
+func TestResourceStorage_genWatchEventsQuery(t *testing.T) {
+	tests := []struct {
+		name            string
+		resource        schema.GroupVersionResource
+		resourceVersion uint64
+		listOptions     *internal.ListOptions
+		expected        expected
+	}{
+		{
+			"empty list options",
+			appsv1.SchemeGroupVersion.WithResource("deployments"),
+			10,
+			&internal.ListOptions{},
+			expected{
+				`SELECT * FROM "resource_events" WHERE "group" = 'apps' AND "resource" = 'deployments' AND "version" = 'v1' AND id > 10 AND id <= 20 ORDER BY id`,
+				"SELECT * FROM `resource_events` WHERE `group` = 'apps' AND `resource` = 'deployments' AND `version` = 'v1' AND id > 10 AND id <= 20 ORDER BY id",
+				"",
+			},
+		},
+		{
+			"with clusters and namespace",
+			appsv1.SchemeGroupVersion.WithResource("deployments"),
+			10,
+			&internal.ListOptions{
+				ClusterNames: []string{"cluster-1", "cluster-2"},
+				Namespaces:   []string{"ns-1"},
+			},
+			expected{
+				`SELECT * FROM "resource_events" WHERE "group" = 'apps' AND "resource" = 'deployments' AND "version" = 'v1' AND id > 10 AND id <= 20 AND cluster IN ('cluster-1','cluster-2') AND namespace = 'ns-1' ORDER BY id`,
+				"SELECT * FROM `resource_events` WHERE `group` = 'apps' AND `resource` = 'deployments' AND `version` = 'v1' AND id > 10 AND id <= 20 AND cluster IN ('cluster-1','cluster-2') AND namespace = 'ns-1' ORDER BY id",
+				"",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s postgres", test.name), func(t *testing.T) {
+			postgreSQL, err := toSQL(postgresDB.Session(&gorm.Session{DryRun: true}), test.listOptions,
+				func(db *gorm.DB, options *internal.ListOptions) (*gorm.DB, error) {
+					rs := newTestResourceStorage(db, test.resource)
+					return rs.genWatchEventsQuery(context.TODO(), test.resourceVersion, test.resourceVersion+10, options)
+				},
+			)
+
+			assertError(t, test.expected.err, err)
+			if postgreSQL != test.expected.postgres {
+				t.Errorf("expected sql: %q, but got: %q", test.expected.postgres, postgreSQL)
+			}
+		})
+
+		for version := range mysqlDBs {
+			t.Run(fmt.Sprintf("%s mysql-%s", test.name, version), func(t *testing.T) {
+				mysqlSQL, err := toSQL(mysqlDBs[version].Session(&gorm.Session{DryRun: true}), test.listOptions,
+					func(db *gorm.DB, options *internal.ListOptions) (*gorm.DB, error) {
+						rs := newTestResourceStorage(db, test.resource)
+						return rs.genWatchEventsQuery(context.TODO(), test.resourceVersion, test.resourceVersion+10, options)
+					},
+				)
+
+				assertError(t, test.expected.err, err)
+				if mysqlSQL != test.expected.mysql {
+					t.Errorf("expected sql: %q, but got: %q", test.expected.mysql, mysqlSQL)
+				}
+			})
+		}
+	}
+}
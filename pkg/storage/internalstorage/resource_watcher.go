@@ -0,0 +1,317 @@
+package internalstorage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/scheme"
+	utilwatch "github.com/clusterpedia-io/clusterpedia/pkg/utils/watch"
+)
+
+const watchEventsBatchSize = 500
+
+func (s *ResourceStorage) Watch(ctx context.Context, options *internal.ListOptions) (watch.Interface, error) {
+	if s.watch == nil {
+		return nil, apierrors.NewMethodNotSupported(s.storageGroupResource, "watch")
+	}
+
+	// The resource events are not indexed by the full-text index.
+	if options.Query != "" {
+		return nil, apierrors.NewBadRequest("full-text search query is not supported by watch")
+	}
+	if options.AsOf != nil {
+		return nil, apierrors.NewBadRequest("as-of is not supported by watch")
+	}
+
+	var resourceVersion uint64
+	if rv := options.ResourceVersion; rv != "" && rv != "0" {
+		var err error
+		if resourceVersion, err = strconv.ParseUint(rv, 10, 64); err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid resource version: %s", rv))
+		}
+	}
+
+	// Paging and sorting are meaningless for the watch, only the filter conditions are retained.
+	opts := *options
+	opts.Limit, opts.Continue, opts.OrderBy = 0, "", nil
+	opts.WithContinue, opts.WithRemainingCount = nil, nil
+
+	watcher := &resourceWatcher{
+		storage:         s,
+		opts:            &opts,
+		resourceVersion: resourceVersion,
+		result:          make(chan watch.Event, 100),
+		done:            make(chan struct{}),
+	}
+	go watcher.run(ctx)
+	return watcher, nil
+}
+
+// latestEventResourceVersion returns the resource version from which to watch after listing the resources,
+// it is the latest committed event of the recent events, see `committedEventResourceVersion`.
+func (s *ResourceStorage) latestEventResourceVersion(ctx context.Context) (uint64, error) {
+	var bounds struct {
+		Oldest uint64
+		Latest uint64
+	}
+	result := s.db.WithContext(ctx).Model(&ResourceEvent{}).
+		Select("COALESCE(MIN(id), 0) AS oldest, COALESCE(MAX(id), 0) AS latest").Scan(&bounds)
+	if result.Error != nil || bounds.Latest == 0 {
+		return 0, result.Error
+	}
+
+	rv := bounds.Oldest - 1
+	if bounds.Latest > watchEventsBatchSize && bounds.Latest-watchEventsBatchSize > rv {
+		rv = bounds.Latest - watchEventsBatchSize
+	}
+	for {
+		committed, more, err := s.committedEventResourceVersion(ctx, rv)
+		if err != nil || !more {
+			return committed, err
+		}
+		rv = committed
+	}
+}
+
+// committedEventResourceVersion returns the resource version up to which the events after `rv` have been committed,
+// and whether there may be more committed events after it.
+//
+// The IDs of the events are allocated when they are inserted, but the transactions may be committed out of order,
+// so the events after a missing ID are held back until the missing one is committed. If the missing event is still
+// not committed after the commit timeout since the following event was recorded, its transaction is considered rolled back.
+func (s *ResourceStorage) committedEventResourceVersion(ctx context.Context, rv uint64) (uint64, bool, error) {
+	var events []ResourceEvent
+	result := s.db.WithContext(ctx).Model(&ResourceEvent{}).Select("id", "recorded_at").
+		Where("id > ?", rv).Order("id").Limit(watchEventsBatchSize).Find(&events)
+	if result.Error != nil {
+		return rv, false, result.Error
+	}
+
+	committed := rv
+	for _, event := range events {
+		if uint64(event.ID) != committed+1 && time.Since(event.RecordedAt) < s.watch.CommitTimeout {
+			return committed, false, nil
+		}
+		committed = uint64(event.ID)
+	}
+	return committed, len(events) == watchEventsBatchSize, nil
+}
+
+// genWatchEventsQuery returns the query of the events in the range of (resourceVersion, committed].
+func (s *ResourceStorage) genWatchEventsQuery(ctx context.Context, resourceVersion, committed uint64, opts *internal.ListOptions) (*gorm.DB, error) {
+	query := s.db.WithContext(ctx).Model(&ResourceEvent{}).Where(map[string]interface{}{
+		"group":    s.storageGroupResource.Group,
+		"version":  s.storageVersion.Version,
+		"resource": s.storageGroupResource.Resource,
+	}).Where("id > ?", resourceVersion).Where("id <= ?", committed)
+
+	_, _, query, err := applyListOptionsToResourceQuery(s.db, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return query.Order("id"), nil
+}
+
+func (s *ResourceStorage) newObject(kind string) runtime.Object {
+	if obj, err := scheme.LegacyResourceScheme.New(s.memoryVersion.WithKind(kind)); err == nil {
+		return obj
+	}
+	return &unstructured.Unstructured{}
+}
+
+// resourceWatcher polls the `resource_events` table and sends the events
+// that match the list options to the result channel.
+//
+// The filter conditions are applied to the object in the event, so when an object
+// no longer matches the conditions after being modified, no event will be sent.
+type resourceWatcher struct {
+	storage         *ResourceStorage
+	opts            *internal.ListOptions
+	resourceVersion uint64
+
+	result   chan watch.Event
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (w *resourceWatcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *resourceWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+	})
+}
+
+func (w *resourceWatcher) run(ctx context.Context) {
+	defer close(w.result)
+
+	if w.resourceVersion == 0 {
+		// resourceVersion = 0 means that we don't require any specific starting point,
+		// send the current state as the added events and then start watching from that point.
+		if err := w.sendInitialEvents(ctx); err != nil {
+			w.sendError(err)
+			return
+		}
+	} else if err := w.checkExpired(ctx); err != nil {
+		w.sendError(err)
+		return
+	}
+
+	ticker := time.NewTicker(w.storage.watch.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := w.poll(ctx); err != nil {
+			w.sendError(err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *resourceWatcher) sendInitialEvents(ctx context.Context) error {
+	rv, err := w.storage.latestEventResourceVersion(ctx)
+	if err != nil {
+		return InterpretDBError(w.storage.storageGroupResource.String(), err)
+	}
+
+	query := w.storage.db.WithContext(ctx).Model(&Resource{}).Where(map[string]interface{}{
+		"group":    w.storage.storageGroupResource.Group,
+		"version":  w.storage.storageVersion.Version,
+		"resource": w.storage.storageGroupResource.Resource,
+	}).Where("removed_at IS NULL")
+	_, _, query, err = applyListOptionsToResourceQuery(w.storage.db, query, w.opts)
+	if err != nil {
+		return err
+	}
+
+	// The resources are sent page by page, so that all the resources are not loaded into memory at once.
+	query = query.Session(&gorm.Session{})
+	var lastID uint
+	for {
+		var resources []Resource
+		if result := query.Where("id > ?", lastID).Order("id").Limit(watchEventsBatchSize).Find(&resources); result.Error != nil {
+			return InterpretDBError(w.storage.storageGroupResource.String(), result.Error)
+		}
+
+		for _, resource := range resources {
+			obj, err := resource.ConvertTo(w.storage.codec, w.storage.newObject(resource.Kind))
+			if err != nil {
+				return err
+			}
+			if !w.send(watch.Event{Type: watch.Added, Object: obj}) {
+				return nil
+			}
+		}
+		if len(resources) < watchEventsBatchSize {
+			break
+		}
+		lastID = resources[len(resources)-1].ID
+	}
+	w.resourceVersion = rv
+	return nil
+}
+
+func (w *resourceWatcher) checkExpired(ctx context.Context) error {
+	var oldest uint64
+	result := w.storage.db.WithContext(ctx).Model(&ResourceEvent{}).Select("COALESCE(MIN(id), 0)").Scan(&oldest)
+	if result.Error != nil {
+		return InterpretDBError(w.storage.storageGroupResource.String(), result.Error)
+	}
+
+	// The events after the resource version may have been cleaned up.
+	if oldest != 0 && w.resourceVersion+1 < oldest {
+		return apierrors.NewResourceExpired(fmt.Sprintf("too old resource version: %d (%d)", w.resourceVersion, oldest-1))
+	}
+	return nil
+}
+
+func (w *resourceWatcher) poll(ctx context.Context) error {
+	for {
+		committed, more, err := w.storage.committedEventResourceVersion(ctx, w.resourceVersion)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return InterpretDBError(w.storage.storageGroupResource.String(), err)
+		}
+		if committed == w.resourceVersion {
+			return nil
+		}
+
+		query, err := w.storage.genWatchEventsQuery(ctx, w.resourceVersion, committed, w.opts)
+		if err != nil {
+			return err
+		}
+
+		var events []ResourceEvent
+		if result := query.Find(&events); result.Error != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return InterpretDBError(w.storage.storageGroupResource.String(), result.Error)
+		}
+
+		for _, event := range events {
+			obj, _, err := w.storage.codec.Decode(event.Object, nil, w.storage.newObject(event.Kind))
+			if err != nil {
+				klog.ErrorS(err, "Failed to decode resource event", "resource", w.storage.storageGroupResource,
+					"cluster", event.Cluster, "namespace", event.Namespace, "name", event.Name)
+				w.resourceVersion = uint64(event.ID)
+				continue
+			}
+
+			// Use the ID of the event as the resource version of the object,
+			// so that the client can continue to watch from this event.
+			if metaobj, err := meta.Accessor(obj); err == nil {
+				metaobj.SetResourceVersion(strconv.FormatUint(uint64(event.ID), 10))
+			}
+			if !w.send(watch.Event{Type: event.Type, Object: obj}) {
+				return nil
+			}
+			w.resourceVersion = uint64(event.ID)
+		}
+
+		// the events in the range that don't match the list options are skipped
+		w.resourceVersion = committed
+		if !more {
+			return nil
+		}
+	}
+}
+
+func (w *resourceWatcher) send(event watch.Event) bool {
+	select {
+	case w.result <- event:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func (w *resourceWatcher) sendError(err error) {
+	select {
+	case w.result <- utilwatch.NewErrorEvent(err):
+	case <-w.done:
+	}
+}
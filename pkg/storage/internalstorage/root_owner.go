@@ -0,0 +1,126 @@
+package internalstorage
+
+import (
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// maxRootOwnerDepth limits the levels of the owned resources updated when the root owner is changed.
+const maxRootOwnerDepth = 5
+
+type rootOwner struct {
+	Kind string
+	Name string
+	UID  types.UID
+}
+
+// rootOwnerOfOwned returns the root owner of the resources owned by the resource,
+// it is the resource itself if it has no owner.
+func (res Resource) rootOwnerOfOwned() rootOwner {
+	if res.RootOwnerUID != "" {
+		return rootOwner{Kind: res.RootOwnerKind, Name: res.RootOwnerName, UID: res.RootOwnerUID}
+	}
+	return rootOwner{Kind: res.Kind, Name: res.Name, UID: res.UID}
+}
+
+// resolveRootOwners replaces the direct owners of the resources with the root owners of the stored owners,
+// the resources whose owners are not stored yet keep the direct owners, they are updated when the owners are stored.
+//
+// It is called in the transaction of the write, so that the root owners are consistent with the stored owners.
+func resolveRootOwners(tx *gorm.DB, cluster string, resources ...*Resource) error {
+	owners := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		if resource.OwnerUID != "" {
+			owners = append(owners, string(resource.OwnerUID))
+		}
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+
+	var stored []Resource
+	result := tx.Model(&Resource{}).Select("uid", "root_owner_kind", "root_owner_name", "root_owner_uid").
+		Where("cluster = ?", cluster).Where("uid IN (?)", owners).Where("removed_at IS NULL").Find(&stored)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	roots := make(map[types.UID]rootOwner, len(stored))
+	for _, owner := range stored {
+		if owner.RootOwnerUID != "" {
+			roots[owner.UID] = rootOwner{Kind: owner.RootOwnerKind, Name: owner.RootOwnerName, UID: owner.RootOwnerUID}
+		}
+	}
+	for _, resource := range resources {
+		if root, ok := roots[resource.OwnerUID]; ok {
+			resource.RootOwnerKind, resource.RootOwnerName, resource.RootOwnerUID = root.Kind, root.Name, root.UID
+		}
+	}
+	return nil
+}
+
+// propagateRootOwners updates the root owners of the resources owned by the written resources in the transaction,
+// the owned resources with the same root owner are updated in bulk, and they are walked down only if their root owners are changed.
+//
+// The root owner is one of the filter conditions of the watch and the history,
+// so the changes of the owned resources are recorded when the changes are recorded.
+func (s *ResourceStorage) propagateRootOwners(tx *gorm.DB, cluster string, resources ...*Resource) error {
+	parents := make(map[types.UID]rootOwner, len(resources))
+	for _, resource := range resources {
+		if resource.UID != "" {
+			parents[resource.UID] = resource.rootOwnerOfOwned()
+		}
+	}
+
+	for depth := 0; depth < maxRootOwnerDepth && len(parents) != 0; depth++ {
+		owners := make(map[rootOwner][]string)
+		for uid, root := range parents {
+			owners[root] = append(owners[root], string(uid))
+		}
+
+		next := make(map[types.UID]rootOwner)
+		for root, uids := range owners {
+			query := func() *gorm.DB {
+				return tx.Model(&Resource{}).Where("cluster = ?", cluster).Where("owner_uid IN (?)", uids).
+					Where("root_owner_uid <> ?", root.UID).Where("removed_at IS NULL")
+			}
+
+			var owned []Resource
+			if s.recordChanges() {
+				if result := query().Find(&owned); result.Error != nil {
+					return result.Error
+				}
+			} else if result := query().Select("uid").Find(&owned); result.Error != nil {
+				return result.Error
+			}
+			if len(owned) == 0 {
+				continue
+			}
+
+			result := query().UpdateColumns(map[string]interface{}{
+				"root_owner_kind": root.Kind,
+				"root_owner_name": root.Name,
+				"root_owner_uid":  root.UID,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+
+			for i := range owned {
+				resource := &owned[i]
+				next[resource.UID] = root
+				if !s.recordChanges() {
+					continue
+				}
+
+				resource.RootOwnerKind, resource.RootOwnerName, resource.RootOwnerUID = root.Kind, root.Name, root.UID
+				if err := s.recordChange(tx, watch.Modified, resource); err != nil {
+					return err
+				}
+			}
+		}
+		parents = next
+	}
+	return nil
+}
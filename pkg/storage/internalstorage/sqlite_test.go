@@ -0,0 +1,1450 @@
+package internalstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	gsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	genericstorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/kubernetes/pkg/apis/apps"
+	"k8s.io/kubernetes/pkg/apis/core"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/fields"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
+)
+
+func newSQLiteResourceStorage(t *testing.T, gr schema.GroupResource, watch *WatchConfig) (*StorageFactory, *ResourceStorage) {
+	// each test uses its own in-memory database
+	cfg := &Config{Type: "sqlite", Database: t.Name(), Params: map[string]string{"mode": "memory", "cache": "shared"}}
+	db, err := gorm.Open(gsqlite.Open(cfg.genSQLiteDSN()), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	// the tables of the shared cache are locked by the connection that is writing,
+	// so a single connection is used to serialize the access.
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&Resource{}, &ResourceEvent{}, &ResourceHistory{}); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+
+	config, err := storageconfig.NewStorageConfigFactory().NewLegacyResourceConfig(gr, true)
+	if err != nil {
+		t.Fatalf("new resource config failed: %v", err)
+	}
+
+	factory := &StorageFactory{db: db, watch: watch}
+	rs, err := factory.NewResourceStorage(config)
+	if err != nil {
+		t.Fatalf("new resource storage failed: %v", err)
+	}
+	return factory, rs.(*ResourceStorage)
+}
+
+func newTestDeployment(namespace, name string, labels map[string]string, owner types.UID) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			UID:             types.UID(namespace + "-" + name),
+			Labels:          labels,
+			ResourceVersion: "1",
+		},
+	}
+	if owner != "" {
+		controller := true
+		deploy.OwnerReferences = []metav1.OwnerReference{{UID: owner, Controller: &controller}}
+	}
+	return deploy
+}
+
+func newTestReplicaSet(namespace, name string, owner types.UID) *appsv1.ReplicaSet {
+	rs := &appsv1.ReplicaSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			UID:             types.UID(namespace + "-" + name),
+			ResourceVersion: "1",
+		},
+	}
+	if owner != "" {
+		controller := true
+		rs.OwnerReferences = []metav1.OwnerReference{{UID: owner, Controller: &controller}}
+	}
+	return rs
+}
+
+func newTestPod(namespace, name string, owner types.UID) *corev1.Pod {
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            name,
+			UID:             types.UID(namespace + "-" + name),
+			ResourceVersion: "1",
+		},
+	}
+	if owner != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{UID: owner, Controller: &controller}}
+	}
+	return pod
+}
+
+func newSQLiteFactoryResourceStorage(t *testing.T, factory *StorageFactory, gr schema.GroupResource) *ResourceStorage {
+	config, err := storageconfig.NewStorageConfigFactory().NewLegacyResourceConfig(gr, true)
+	if err != nil {
+		t.Fatalf("new resource config failed: %v", err)
+	}
+	rs, err := factory.NewResourceStorage(config)
+	if err != nil {
+		t.Fatalf("new resource storage failed: %v", err)
+	}
+	return rs.(*ResourceStorage)
+}
+
+func TestSQLiteResourceStorage(t *testing.T) {
+	factory, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+
+	ctx := context.TODO()
+	objects := map[string][]*appsv1.Deployment{
+		"cluster-1": {
+			newTestDeployment("ns-1", "deploy-1", map[string]string{"app": "a"}, ""),
+			newTestDeployment("ns-2", "deploy-2", map[string]string{"app": "b"}, "owner-1"),
+		},
+		"cluster-2": {
+			newTestDeployment("ns-1", "deploy-1", map[string]string{"app": "a"}, ""),
+		},
+	}
+	for cluster, deploys := range objects {
+		for _, deploy := range deploys {
+			if err := rs.Create(ctx, cluster, deploy); err != nil {
+				t.Fatalf("create %s/%s failed: %v", cluster, deploy.Name, err)
+			}
+		}
+	}
+
+	if err := rs.Create(ctx, "cluster-1", objects["cluster-1"][0]); !genericstorage.IsExist(err) {
+		t.Errorf("expected key exists error, but got %v", err)
+	}
+
+	selector, _ := labels.Parse("app=a")
+	fieldSelector, _ := fields.Parse("metadata.namespace!=ns-1")
+	tests := []struct {
+		name     string
+		opts     *internal.ListOptions
+		expected int
+	}{
+		{"all", &internal.ListOptions{}, 3},
+		{"cluster", &internal.ListOptions{ClusterNames: []string{"cluster-1"}}, 2},
+		{"label selector", &internal.ListOptions{ListOptions: metainternal.ListOptions{LabelSelector: selector}}, 2},
+		{"field selector", &internal.ListOptions{EnhancedFieldSelector: fieldSelector}, 1},
+		{"owner uid", &internal.ListOptions{ClusterNames: []string{"cluster-1"}, OwnerUID: "owner-1"}, 1},
+		{"limit", &internal.ListOptions{ListOptions: metainternal.ListOptions{Limit: 2}}, 2},
+		{"only metadata", &internal.ListOptions{OnlyMetadata: true}, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list := &apps.DeploymentList{}
+			if err := rs.List(ctx, list, test.opts); err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+			if len(list.Items) != test.expected {
+				t.Errorf("expected %d items, but got %d", test.expected, len(list.Items))
+			}
+		})
+	}
+
+	deploy := &apps.Deployment{}
+	if err := rs.Get(ctx, "cluster-1", "ns-2", "deploy-2", deploy); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if deploy.Labels["app"] != "b" {
+		t.Errorf("expected label app=b, but got %v", deploy.Labels)
+	}
+
+	updated := newTestDeployment("ns-2", "deploy-2", map[string]string{"app": "c"}, "owner-1")
+	updated.ResourceVersion = "2"
+	if err := rs.Update(ctx, "cluster-1", updated); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if err := rs.Delete(ctx, "cluster-2", objects["cluster-2"][0]); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	versions, err := factory.GetResourceVersions(ctx, "cluster-1")
+	if err != nil {
+		t.Fatalf("get resource versions failed: %v", err)
+	}
+	gvr := appsv1.SchemeGroupVersion.WithResource("deployments")
+	if rv := versions[gvr]["ns-2/deploy-2"]; rv != "2" {
+		t.Errorf("expected resource version 2, but got %v", rv)
+	}
+
+	versions, err = factory.GetResourceVersions(ctx, "cluster-2")
+	if err != nil {
+		t.Fatalf("get resource versions failed: %v", err)
+	}
+	if len(versions[gvr]) != 0 {
+		t.Errorf("expected no resources in cluster-2, but got %v", versions[gvr])
+	}
+}
+
+func TestSQLiteResourceStorage_FieldSelectorTypedValues(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+
+	ctx := context.TODO()
+	for i, name := range []string{"deploy-1", "deploy-2", "deploy-3"} {
+		deploy := newTestDeployment("ns-1", name, nil, "")
+		replicas := int32(i + 1)
+		deploy.Spec.Replicas = &replicas
+		deploy.Spec.Paused = i == 0
+		if err := rs.Create(ctx, "cluster-1", deploy); err != nil {
+			t.Fatalf("create %s failed: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		selector string
+		expected []string
+	}{
+		{"spec.replicas=3", []string{"deploy-3"}},
+		{"spec.replicas!=3", []string{"deploy-1", "deploy-2"}},
+		{"spec.replicas in (1,2)", []string{"deploy-1", "deploy-2"}},
+		{"spec.paused=true", []string{"deploy-1"}},
+		{"spec.paused!=true", []string{"deploy-2", "deploy-3"}},
+		{"metadata.name=deploy-2", []string{"deploy-2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.selector, func(t *testing.T) {
+			fieldSelector, err := fields.Parse(test.selector)
+			if err != nil {
+				t.Fatalf("parse field selector failed: %v", err)
+			}
+			list := &apps.DeploymentList{}
+			if err := rs.List(ctx, list, &internal.ListOptions{EnhancedFieldSelector: fieldSelector}); err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+
+			names := []string{}
+			for _, deploy := range list.Items {
+				names = append(names, deploy.Name)
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, but got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func TestSQLiteResourceStorage_Watch(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"},
+		&WatchConfig{PollInterval: 10 * time.Millisecond, EventRetention: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	if err := rs.Create(ctx, "cluster-1", newTestDeployment("ns-1", "deploy-1", nil, "")); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	list := &apps.DeploymentList{}
+	if err := rs.List(ctx, list, &internal.ListOptions{}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if list.ResourceVersion != "1" {
+		t.Fatalf("expected list resource version 1, but got %q", list.ResourceVersion)
+	}
+
+	opts := &internal.ListOptions{ClusterNames: []string{"cluster-1"}}
+	opts.ResourceVersion = list.ResourceVersion
+	watcher, err := rs.Watch(ctx, opts)
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	updated := newTestDeployment("ns-1", "deploy-1", map[string]string{"app": "a"}, "")
+	updated.ResourceVersion = "2"
+	if err := rs.Update(ctx, "cluster-1", updated); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if err := rs.Create(ctx, "cluster-2", newTestDeployment("ns-1", "deploy-2", nil, "")); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if err := rs.Delete(ctx, "cluster-1", updated); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	expected := []watch.EventType{watch.Modified, watch.Deleted}
+	for _, eventType := range expected {
+		select {
+		case event := <-watcher.ResultChan():
+			if event.Type != eventType {
+				t.Fatalf("expected %s event, but got %s: %v", eventType, event.Type, event.Object)
+			}
+			if deploy := event.Object.(*apps.Deployment); deploy.Name != "deploy-1" {
+				t.Errorf("expected deploy-1, but got %s", deploy.Name)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %s event", eventType)
+		}
+	}
+}
+
+func TestSQLiteResourceStorage_WatchCleanedResources(t *testing.T) {
+	factory, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"},
+		&WatchConfig{PollInterval: 10 * time.Millisecond, EventRetention: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	for _, cluster := range []string{"cluster-1", "cluster-2"} {
+		for _, name := range []string{"deploy-1", "deploy-2"} {
+			if err := rs.Create(ctx, cluster, newTestDeployment("ns-1", cluster+"-"+name, nil, "")); err != nil {
+				t.Fatalf("create failed: %v", err)
+			}
+		}
+	}
+
+	list := &apps.DeploymentList{}
+	if err := rs.List(ctx, list, &internal.ListOptions{}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	opts := &internal.ListOptions{}
+	opts.ResourceVersion = list.ResourceVersion
+	watcher, err := rs.Watch(ctx, opts)
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	if err := factory.CleanClusterResource(ctx, "cluster-1", appsv1.SchemeGroupVersion.WithResource("deployments")); err != nil {
+		t.Fatalf("clean cluster resource failed: %v", err)
+	}
+	if err := factory.CleanCluster(ctx, "cluster-2"); err != nil {
+		t.Fatalf("clean cluster failed: %v", err)
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		select {
+		case event := <-watcher.ResultChan():
+			deploy := event.Object.(*apps.Deployment)
+			got = append(got, string(event.Type)+" "+deploy.Name)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for the delete events, got %v", got)
+		}
+	}
+	expected := []string{
+		"DELETED cluster-1-deploy-1", "DELETED cluster-1-deploy-2",
+		"DELETED cluster-2-deploy-1", "DELETED cluster-2-deploy-2",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected events %v, but got %v", expected, got)
+	}
+
+	list = &apps.DeploymentList{}
+	if err := rs.List(ctx, list, &internal.ListOptions{}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("expected the resources are cleaned, but got %d", len(list.Items))
+	}
+}
+
+func TestSQLiteResourceStorage_WatchOutOfOrderCommits(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"},
+		&WatchConfig{PollInterval: 10 * time.Millisecond, EventRetention: time.Hour, CommitTimeout: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	if err := rs.Create(ctx, "cluster-1", newTestDeployment("ns-1", "deploy-1", nil, "")); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	// commitEvent simulates the transactions committed out of the order of the allocated IDs
+	commitEvent := func(id uint, name string, recordedAt time.Time) {
+		resource, err := rs.newResource("cluster-1", newTestDeployment("ns-1", name, nil, ""))
+		if err != nil {
+			t.Fatalf("new resource failed: %v", err)
+		}
+		event := newResourceEvent(watch.Added, resource)
+		event.ID, event.RecordedAt = id, recordedAt
+		if result := rs.db.Create(event); result.Error != nil {
+			t.Fatalf("create event failed: %v", result.Error)
+		}
+	}
+
+	opts := &internal.ListOptions{}
+	opts.ResourceVersion = "1"
+	watcher, err := rs.Watch(ctx, opts)
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	receive := func(expected string) {
+		select {
+		case event := <-watcher.ResultChan():
+			deploy, ok := event.Object.(*apps.Deployment)
+			if !ok || deploy.Name != expected {
+				t.Fatalf("expected the event of %s, but got %s: %v", expected, event.Type, event.Object)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for the event of %s", expected)
+		}
+	}
+
+	// the event 3 is held back until the event 2 is committed
+	commitEvent(3, "deploy-3", time.Now())
+	select {
+	case event := <-watcher.ResultChan():
+		t.Fatalf("expected the event after the missing one is held back, but got %s: %v", event.Type, event.Object)
+	case <-time.After(100 * time.Millisecond):
+	}
+	commitEvent(2, "deploy-2", time.Now())
+	receive("deploy-2")
+	receive("deploy-3")
+
+	// the missing event 4 is considered rolled back after the commit timeout
+	commitEvent(5, "deploy-5", time.Now().Add(-2*time.Hour))
+	receive("deploy-5")
+
+	list := &apps.DeploymentList{}
+	if err := rs.List(ctx, list, &internal.ListOptions{}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if list.ResourceVersion != "5" {
+		t.Errorf("expected list resource version 5, but got %q", list.ResourceVersion)
+	}
+}
+
+func TestSQLiteResourceStorage_WatchInitialEvents(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"},
+		&WatchConfig{PollInterval: 10 * time.Millisecond, EventRetention: time.Hour, CommitTimeout: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	// the initial events are sent in multiple pages
+	total := watchEventsBatchSize + 10
+	var changes []storage.ResourceChange
+	for i := 0; i < total; i++ {
+		changes = append(changes, storage.ResourceChange{Object: newTestDeployment("ns-1", "deploy-"+strconv.Itoa(i), nil, "")})
+	}
+	if err := rs.BatchWrite(ctx, "cluster-1", changes); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+
+	watcher, err := rs.Watch(ctx, &internal.ListOptions{})
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	names := sets.NewString()
+	for names.Len() < total {
+		select {
+		case event := <-watcher.ResultChan():
+			if event.Type != watch.Added {
+				t.Fatalf("expected the added event, but got %s: %v", event.Type, event.Object)
+			}
+			names.Insert(event.Object.(*apps.Deployment).Name)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %d initial events, but got %d", total, names.Len())
+		}
+	}
+
+	if err := rs.Create(ctx, "cluster-1", newTestDeployment("ns-1", "deploy-new", nil, "")); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	select {
+	case event := <-watcher.ResultChan():
+		if deploy := event.Object.(*apps.Deployment); event.Type != watch.Added || deploy.Name != "deploy-new" {
+			t.Errorf("expected the added deploy-new, but got %s %s", event.Type, deploy.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the event after the initial events")
+	}
+}
+
+func TestSQLiteResourceStorage_KeysetContinue(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+
+	ctx := context.TODO()
+	for _, name := range []string{"deploy-1", "deploy-2", "deploy-3", "deploy-4", "deploy-5"} {
+		// the same name in different clusters makes the primary key the tiebreaker
+		for _, cluster := range []string{"cluster-1", "cluster-2"} {
+			if err := rs.Create(ctx, cluster, newTestDeployment("ns-1", name, nil, "")); err != nil {
+				t.Fatalf("create %s/%s failed: %v", cluster, name, err)
+			}
+		}
+	}
+
+	withContinue, withRemainingCount := true, true
+	opts := &internal.ListOptions{
+		OrderBy:            []internal.OrderBy{{Field: "name", Desc: true}},
+		WithContinue:       &withContinue,
+		WithRemainingCount: &withRemainingCount,
+	}
+	opts.Limit = 3
+
+	var names []string
+	for page := 0; ; page++ {
+		list := &apps.DeploymentList{}
+		if err := rs.List(ctx, list, opts); err != nil {
+			t.Fatalf("list page %d failed: %v", page, err)
+		}
+		for _, deploy := range list.Items {
+			names = append(names, deploy.Name)
+		}
+		if remain := *list.RemainingItemCount; remain != int64(10-len(names)) {
+			t.Errorf("page %d: expected %d remaining items, but got %d", page, 10-len(names), remain)
+		}
+
+		if list.Continue == "" {
+			break
+		}
+		if isOffsetContinue(list.Continue) {
+			t.Fatalf("expected keyset continue token, but got %q", list.Continue)
+		}
+		opts.Continue = list.Continue
+	}
+
+	expected := []string{"deploy-5", "deploy-5", "deploy-4", "deploy-4", "deploy-3", "deploy-3", "deploy-2", "deploy-2", "deploy-1", "deploy-1"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, but got %v", expected, names)
+	}
+
+	// the numeric offset continue is still supported
+	opts.Continue, opts.OrderBy = "8", nil
+	list := &apps.DeploymentList{}
+	if err := rs.List(ctx, list, opts); err != nil {
+		t.Fatalf("list with offset failed: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("expected 2 items, but got %d", len(list.Items))
+	}
+
+	// the continue token doesn't depend on the last row of the previous page, which may be deleted
+	opts.Continue, opts.OrderBy = "", []internal.OrderBy{{Field: "cluster"}, {Field: "name"}}
+	first := &apps.DeploymentList{}
+	if err := rs.List(ctx, first, opts); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if err := rs.Delete(ctx, "cluster-1", &first.Items[len(first.Items)-1]); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	opts.Continue = first.Continue
+	next := &apps.DeploymentList{}
+	if err := rs.List(ctx, next, opts); err != nil {
+		t.Fatalf("list the next page failed: %v", err)
+	}
+	if len(next.Items) != 3 || next.Items[0].Name != "deploy-4" {
+		t.Errorf("expected the next page starts from deploy-4, but got %d items", len(next.Items))
+	}
+}
+
+func TestSQLiteCollectionResourceStorage_Custom(t *testing.T) {
+	factory, deployments := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+
+	config, err := storageconfig.NewStorageConfigFactory().NewLegacyResourceConfig(schema.GroupResource{Resource: "services"}, true)
+	if err != nil {
+		t.Fatalf("new resource config failed: %v", err)
+	}
+	rs, err := factory.NewResourceStorage(config)
+	if err != nil {
+		t.Fatalf("new resource storage failed: %v", err)
+	}
+	services := rs.(*ResourceStorage)
+
+	ctx := context.TODO()
+	if err := deployments.Create(ctx, "cluster-1", newTestDeployment("ns-1", "deploy-1", nil, "")); err != nil {
+		t.Fatalf("create deployment failed: %v", err)
+	}
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "service-1", UID: "ns-1-service-1", ResourceVersion: "1"},
+	}
+	if err := services.Create(ctx, "cluster-1", service); err != nil {
+		t.Fatalf("create service failed: %v", err)
+	}
+
+	if _, err := factory.NewCollectionResourceStorage(&internal.CollectionResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "empty"},
+	}); err == nil {
+		t.Errorf("expected error for the custom collection resource without resource types")
+	}
+
+	storage, err := factory.NewCollectionResourceStorage(&internal.CollectionResource{
+		ObjectMeta:    metav1.ObjectMeta{Name: "networking"},
+		ResourceTypes: []internal.CollectionResourceType{{Group: "", Resource: "services"}},
+	})
+	if err != nil {
+		t.Fatalf("new collection resource storage failed: %v", err)
+	}
+	collection, err := storage.Get(ctx, &internal.ListOptions{})
+	if err != nil {
+		t.Fatalf("get collection resource failed: %v", err)
+	}
+	if len(collection.Items) != 1 {
+		t.Fatalf("expected 1 item, but got %d", len(collection.Items))
+	}
+	if kind := collection.Items[0].GetObjectKind().GroupVersionKind().Kind; kind != "Service" {
+		t.Errorf("expected Service, but got %s", kind)
+	}
+
+	service = service.DeepCopy()
+	service.Name, service.UID = "service-2", "ns-1-service-2"
+	if err := services.Create(ctx, "cluster-1", service); err != nil {
+		t.Fatalf("create service failed: %v", err)
+	}
+	withContinue := true
+	opts := &internal.ListOptions{OrderBy: []internal.OrderBy{{Field: "name", Desc: true}}, WithContinue: &withContinue}
+	opts.Limit = 1
+	var names []string
+	for {
+		collection, err := storage.Get(ctx, opts)
+		if err != nil {
+			t.Fatalf("get collection resource failed: %v", err)
+		}
+		for _, item := range collection.Items {
+			names = append(names, item.(metav1.Object).GetName())
+		}
+		if collection.Continue == "" || len(collection.Items) == 0 {
+			break
+		}
+		opts.Continue = collection.Continue
+	}
+	if expected := []string{"service-2", "service-1"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, but got %v", expected, names)
+	}
+}
+
+func TestSQLiteResourceStorage_Count(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+
+	ctx := context.TODO()
+	for _, deploy := range []struct {
+		cluster   string
+		namespace string
+		name      string
+		labels    map[string]string
+	}{
+		{"cluster-1", "ns-1", "deploy-1", map[string]string{"app": "nginx"}},
+		{"cluster-1", "ns-1", "deploy-2", map[string]string{"app": "nginx"}},
+		{"cluster-1", "ns-2", "deploy-3", map[string]string{"app": "redis"}},
+		{"cluster-2", "ns-1", "deploy-4", map[string]string{"app": "nginx"}},
+		{"cluster-2", "ns-1", "deploy-5", nil},
+	} {
+		if err := rs.Create(ctx, deploy.cluster, newTestDeployment(deploy.namespace, deploy.name, deploy.labels, "")); err != nil {
+			t.Fatalf("create %s/%s failed: %v", deploy.cluster, deploy.name, err)
+		}
+	}
+
+	counts, err := rs.Count(ctx, &internal.ListOptions{GroupBy: []string{"cluster", "labels.app"}})
+	if err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	expected := []internal.ResourceCount{
+		{Values: map[string]string{"cluster": "cluster-1", "labels.app": "nginx"}, Count: 2},
+		{Values: map[string]string{"cluster": "cluster-1", "labels.app": "redis"}, Count: 1},
+		{Values: map[string]string{"cluster": "cluster-2"}, Count: 1},
+		{Values: map[string]string{"cluster": "cluster-2", "labels.app": "nginx"}, Count: 1},
+	}
+	if !reflect.DeepEqual(counts.Items, expected) {
+		t.Errorf("expected %v, but got %v", expected, counts.Items)
+	}
+
+	// the list options filter the counted resources, and the limit is ignored
+	opts := &internal.ListOptions{ClusterNames: []string{"cluster-1"}, GroupBy: []string{"metadata.namespace"}}
+	opts.Limit = 1
+	counts, err = rs.Count(ctx, opts)
+	if err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	expected = []internal.ResourceCount{
+		{Values: map[string]string{"metadata.namespace": "ns-1"}, Count: 2},
+		{Values: map[string]string{"metadata.namespace": "ns-2"}, Count: 1},
+	}
+	if !reflect.DeepEqual(counts.Items, expected) {
+		t.Errorf("expected %v, but got %v", expected, counts.Items)
+	}
+
+	counts, err = rs.Count(ctx, &internal.ListOptions{})
+	if err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	if len(counts.Items) != 1 || counts.Items[0].Count != 5 {
+		t.Errorf("expected total count 5, but got %v", counts.Items)
+	}
+}
+
+func TestSQLiteResourceStorage_FullText(t *testing.T) {
+	factory, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+
+	ctx := context.TODO()
+	if err := rs.List(ctx, &apps.DeploymentList{}, &internal.ListOptions{Query: "nginx"}); !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected the bad request error when the full-text search is not enabled, but got %v", err)
+	}
+	if _, err := rs.Count(ctx, &internal.ListOptions{Query: "nginx"}); !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected the bad request error when the full-text search is not enabled, but got %v", err)
+	}
+	factory.fullTextSearch, rs.fullTextSearch = true, true
+
+	images := map[string]string{"deploy-1": "nginx:1.21", "deploy-2": "nginx:1.23", "deploy-3": "redis:7.0"}
+	for _, name := range []string{"deploy-1", "deploy-2", "deploy-3"} {
+		deploy := newTestDeployment("ns-1", name, map[string]string{"app": name}, "")
+		deploy.Spec.Template.Spec.Containers = []corev1.Container{{Name: "main", Image: images[name]}}
+		if err := rs.Create(ctx, "cluster-1", deploy); err != nil {
+			t.Fatalf("create %s failed: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"nginx", []string{"deploy-1", "deploy-2"}},
+		{"nginx:1.21", []string{"deploy-1"}},
+		{"redis 7.0", []string{"deploy-3"}},
+		{"deploy-2", []string{"deploy-2"}},
+		{"mysql", nil},
+	}
+	for _, test := range tests {
+		list := &apps.DeploymentList{}
+		opts := &internal.ListOptions{Query: test.query, OrderBy: []internal.OrderBy{{Field: "name"}}}
+		if err := rs.List(ctx, list, opts); err != nil {
+			t.Fatalf("list with query %q failed: %v", test.query, err)
+		}
+		var names []string
+		for _, deploy := range list.Items {
+			names = append(names, deploy.Name)
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("query %q: expected %v, but got %v", test.query, test.expected, names)
+		}
+	}
+
+	storage, err := factory.NewCollectionResourceStorage(&internal.CollectionResource{
+		ObjectMeta:    metav1.ObjectMeta{Name: "workloads"},
+		ResourceTypes: []internal.CollectionResourceType{{Group: "apps", Resource: "deployments"}},
+	})
+	if err != nil {
+		t.Fatalf("new collection resource storage failed: %v", err)
+	}
+	collection, err := storage.Get(ctx, &internal.ListOptions{Query: "redis"})
+	if err != nil {
+		t.Fatalf("get collection resource failed: %v", err)
+	}
+	if len(collection.Items) != 1 {
+		t.Errorf("expected 1 item, but got %d", len(collection.Items))
+	}
+}
+
+func TestSQLiteResourceStorage_History(t *testing.T) {
+	factory, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+	factory.history = &HistoryConfig{Retention: time.Hour}
+	rs.history = factory.history
+
+	ctx := context.TODO()
+	tick := func() time.Time {
+		time.Sleep(10 * time.Millisecond)
+		now := time.Now()
+		time.Sleep(10 * time.Millisecond)
+		return now
+	}
+
+	beforeCreated := tick()
+	if err := rs.Create(ctx, "cluster-1", newTestDeployment("ns-1", "deploy-1", map[string]string{"version": "1"}, "")); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	created := tick()
+	updated := newTestDeployment("ns-1", "deploy-1", map[string]string{"version": "2"}, "")
+	updated.ResourceVersion = "2"
+	if err := rs.Update(ctx, "cluster-1", updated); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	modified := tick()
+	if err := rs.Delete(ctx, "cluster-1", updated); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	deleted := tick()
+	if err := rs.Create(ctx, "cluster-1", newTestDeployment("ns-1", "deploy-2", nil, "")); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		asOf     *time.Time
+		expected []string
+	}{
+		{"before created", &beforeCreated, nil},
+		{"created", &created, []string{"deploy-1/1"}},
+		{"modified", &modified, []string{"deploy-1/2"}},
+		{"deleted", &deleted, nil},
+		{"current", nil, []string{"deploy-2/1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := &internal.ListOptions{}
+			if test.asOf != nil {
+				opts.AsOf = &metav1.Time{Time: *test.asOf}
+			}
+			list := &apps.DeploymentList{}
+			if err := rs.List(ctx, list, opts); err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+			var items []string
+			for _, deploy := range list.Items {
+				items = append(items, deploy.Name+"/"+deploy.ResourceVersion)
+			}
+			if !reflect.DeepEqual(items, test.expected) {
+				t.Errorf("expected %v, but got %v", test.expected, items)
+			}
+		})
+	}
+
+	deploy := &apps.Deployment{}
+	if err := rs.GetAsOf(ctx, "cluster-1", "ns-1", "deploy-1", created, deploy); err != nil {
+		t.Fatalf("get as of failed: %v", err)
+	}
+	if deploy.Labels["version"] != "1" {
+		t.Errorf("expected label version=1, but got %v", deploy.Labels)
+	}
+	if err := rs.GetAsOf(ctx, "cluster-1", "ns-1", "deploy-1", deleted, &apps.Deployment{}); !genericstorage.IsNotFound(err) {
+		t.Errorf("expected not found error, but got %v", err)
+	}
+
+	revisions, err := rs.ListRevisions(ctx, "cluster-1", "ns-1", "deploy-1")
+	if err != nil {
+		t.Fatalf("list revisions failed: %v", err)
+	}
+	if len(revisions.Items) != 2 {
+		t.Fatalf("expected 2 revisions, but got %d", len(revisions.Items))
+	}
+	for i, revision := range revisions.Items {
+		if revision.ResourceVersion != strconv.Itoa(i+1) || revision.ValidTo == nil {
+			t.Errorf("unexpected revision %d: %s, %v", i, revision.ResourceVersion, revision.ValidTo)
+		}
+	}
+
+	// the histories of the cleaned cluster are kept until they expire
+	if err := factory.CleanCluster(ctx, "cluster-1"); err != nil {
+		t.Fatalf("clean cluster failed: %v", err)
+	}
+	revisions, err = rs.ListRevisions(ctx, "cluster-1", "ns-1", "deploy-2")
+	if err != nil {
+		t.Fatalf("list revisions failed: %v", err)
+	}
+	if len(revisions.Items) != 1 || revisions.Items[0].ValidTo == nil {
+		t.Errorf("expected the closed revision, but got %v", revisions.Items)
+	}
+
+	rs.history = nil
+	opts := &internal.ListOptions{AsOf: &metav1.Time{Time: created}}
+	if err := rs.List(ctx, &apps.DeploymentList{}, opts); err == nil {
+		t.Errorf("expected error for as-of without the history")
+	}
+}
+
+func TestSQLiteResourceStorage_SoftDelete(t *testing.T) {
+	factory, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+	factory.softDelete = &SoftDeleteConfig{Retention: time.Hour}
+	rs.softDelete = factory.softDelete
+
+	ctx := context.TODO()
+	for _, name := range []string{"deploy-1", "deploy-2"} {
+		if err := rs.Create(ctx, "cluster-1", newTestDeployment("ns-1", name, nil, "")); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+	if err := rs.Delete(ctx, "cluster-1", newTestDeployment("ns-1", "deploy-1", nil, "")); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     *internal.ListOptions
+		expected []string
+	}{
+		{"default", &internal.ListOptions{}, []string{"deploy-2"}},
+		{"include deleted", &internal.ListOptions{IncludeDeleted: true}, []string{"deploy-1", "deploy-2"}},
+		{"only deleted", &internal.ListOptions{OnlyDeleted: true}, []string{"deploy-1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list := &apps.DeploymentList{}
+			if err := rs.List(ctx, list, test.opts); err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+			var names []string
+			for _, deploy := range list.Items {
+				names = append(names, deploy.Name)
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, but got %v", test.expected, names)
+			}
+		})
+	}
+
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-1", &apps.Deployment{}); !genericstorage.IsNotFound(err) {
+		t.Errorf("expected not found error for the tombstone, but got %v", err)
+	}
+
+	// the tombstones are not reported to the synchro, so that the recreated resources can be stored again
+	versions, err := factory.GetResourceVersions(ctx, "cluster-1")
+	if err != nil {
+		t.Fatalf("get resource versions failed: %v", err)
+	}
+	if deployments := versions[schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}]; len(deployments) != 1 {
+		t.Errorf("expected only the live resource versions, but got %v", deployments)
+	}
+
+	recreated := newTestDeployment("ns-1", "deploy-1", nil, "")
+	recreated.ResourceVersion = "3"
+	if err := rs.Create(ctx, "cluster-1", recreated); err != nil {
+		t.Fatalf("recreate failed: %v", err)
+	}
+	deploy := &apps.Deployment{}
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-1", deploy); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if deploy.ResourceVersion != "3" {
+		t.Errorf("expected the recreated resource, but got resource version %s", deploy.ResourceVersion)
+	}
+
+	if err := rs.Delete(ctx, "cluster-1", recreated); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	// the upserted resource doesn't replace the tombstones either
+	upserted := newTestDeployment("ns-1", "deploy-1", nil, "")
+	upserted.ResourceVersion = "5"
+	if err := rs.Upsert(ctx, "cluster-1", upserted); err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	tombstones := &apps.DeploymentList{}
+	if err := rs.List(ctx, tombstones, &internal.ListOptions{OnlyDeleted: true}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	var removed []string
+	for _, deploy := range tombstones.Items {
+		removed = append(removed, deploy.Name+"@"+deploy.ResourceVersion)
+	}
+	if expected := []string{"deploy-1@1", "deploy-1@3"}; !reflect.DeepEqual(removed, expected) {
+		t.Errorf("expected the tombstones %v, but got %v", expected, removed)
+	}
+
+	// the resources owned by the tombstone are not matched by the owner
+	owned := newTestDeployment("ns-1", "deploy-owned", nil, recreated.UID)
+	if err := rs.Create(ctx, "cluster-1", owned); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if err := rs.Delete(ctx, "cluster-1", upserted); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	ownedList := &apps.DeploymentList{}
+	if err := rs.List(ctx, ownedList, &internal.ListOptions{ClusterNames: []string{"cluster-1"}, OwnerName: "deploy-1"}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(ownedList.Items) != 0 {
+		t.Errorf("expected no resources owned by the tombstones, but got %d items", len(ownedList.Items))
+	}
+	if err := rs.Delete(ctx, "cluster-1", owned); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	factory.softDelete.Retention = 0
+	factory.cleanExpiredTombstones()
+
+	list := &apps.DeploymentList{}
+	if err := rs.List(ctx, list, &internal.ListOptions{IncludeDeleted: true}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "deploy-2" {
+		t.Errorf("expected the expired tombstones to be cleaned, but got %d items", len(list.Items))
+	}
+}
+
+func TestSQLiteResourceStorage_BatchWrite(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+	rs.softDelete = &SoftDeleteConfig{Retention: time.Hour}
+
+	ctx := context.TODO()
+	var changes []storage.ResourceChange
+	for i := 0; i < upsertBatchSize+10; i++ {
+		changes = append(changes, storage.ResourceChange{Object: newTestDeployment("ns-1", "deploy-"+strconv.Itoa(i), nil, "")})
+	}
+	if err := rs.BatchWrite(ctx, "cluster-1", changes); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+
+	updated := newTestDeployment("ns-1", "deploy-0", map[string]string{"app": "a"}, "")
+	updated.ResourceVersion = "2"
+	changes = []storage.ResourceChange{
+		{Object: updated},
+		{Deleted: true, Object: newTestDeployment("ns-1", "deploy-1", nil, "")},
+	}
+	if err := rs.BatchWrite(ctx, "cluster-1", changes); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+
+	list := &apps.DeploymentList{}
+	if err := rs.List(ctx, list, &internal.ListOptions{}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(list.Items) != upsertBatchSize+9 {
+		t.Errorf("expected %d deployments, but got %d", upsertBatchSize+9, len(list.Items))
+	}
+
+	deploy := &apps.Deployment{}
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-0", deploy); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if deploy.ResourceVersion != "2" || deploy.Labels["app"] != "a" {
+		t.Errorf("expected the updated deployment, but got %v", deploy.ObjectMeta)
+	}
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-1", &apps.Deployment{}); !genericstorage.IsNotFound(err) {
+		t.Errorf("expected not found error for the deleted resource, but got %v", err)
+	}
+
+	// the upsert replaces the tombstone of the recreated resource
+	recreated := newTestDeployment("ns-1", "deploy-1", nil, "")
+	recreated.ResourceVersion = "3"
+	if err := rs.BatchWrite(ctx, "cluster-1", []storage.ResourceChange{{Object: recreated}}); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-1", &apps.Deployment{}); err != nil {
+		t.Errorf("get recreated resource failed: %v", err)
+	}
+}
+
+func TestSQLiteResourceStorage_BatchWriteWithWatch(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, &WatchConfig{})
+
+	ctx := context.TODO()
+	changes := []storage.ResourceChange{
+		{Object: newTestDeployment("ns-1", "deploy-1", nil, "")},
+		{Object: newTestDeployment("ns-1", "deploy-2", nil, "")},
+	}
+	if err := rs.BatchWrite(ctx, "cluster-1", changes); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	updated := newTestDeployment("ns-1", "deploy-1", nil, "")
+	updated.ResourceVersion = "2"
+	changes = []storage.ResourceChange{
+		{Object: updated},
+		{Deleted: true, Object: newTestDeployment("ns-1", "deploy-2", nil, "")},
+	}
+	if err := rs.BatchWrite(ctx, "cluster-1", changes); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+
+	var events []ResourceEvent
+	if result := rs.db.Order("id").Find(&events); result.Error != nil {
+		t.Fatalf("find events failed: %v", result.Error)
+	}
+	var got []string
+	for _, event := range events {
+		got = append(got, string(event.Type)+" "+event.Name)
+	}
+	expected := []string{"ADDED deploy-1", "ADDED deploy-2", "MODIFIED deploy-1", "DELETED deploy-2"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected events %v, but got %v", expected, got)
+	}
+}
+
+func TestSQLiteResourceStorage_Upsert(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, &WatchConfig{})
+
+	ctx := context.TODO()
+	for _, rv := range []string{"9", "10", "8", "10", "11"} {
+		deploy := newTestDeployment("ns-1", "deploy-1", map[string]string{"rv": rv}, "")
+		deploy.ResourceVersion = rv
+		if err := rs.Upsert(ctx, "cluster-1", deploy); err != nil {
+			t.Fatalf("upsert failed: %v", err)
+		}
+	}
+
+	deploy := &apps.Deployment{}
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-1", deploy); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if deploy.ResourceVersion != "11" || deploy.Labels["rv"] != "11" {
+		t.Errorf("expected the newest deployment, but got %v", deploy.ObjectMeta)
+	}
+
+	// the older resource versions are not written, but the same resource version is rewritten
+	var events []ResourceEvent
+	if result := rs.db.Order("id").Find(&events); result.Error != nil {
+		t.Fatalf("find events failed: %v", result.Error)
+	}
+	var got []string
+	for _, event := range events {
+		got = append(got, string(event.Type)+" "+event.ResourceVersion)
+	}
+	expected := []string{"ADDED 9", "MODIFIED 10", "MODIFIED 10", "MODIFIED 11"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected events %v, but got %v", expected, got)
+	}
+}
+
+func TestSQLiteResourceStorage_UpsertTransformedResource(t *testing.T) {
+	_, rs := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+
+	ctx := context.TODO()
+	deploy := newTestDeployment("ns-1", "deploy-1", map[string]string{"token": "secret"}, "")
+	deploy.ResourceVersion = "10"
+	if err := rs.Upsert(ctx, "cluster-1", deploy); err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+
+	// the relist after adding a transform writes the transformed resource with the same resource version
+	redacted := newTestDeployment("ns-1", "deploy-1", map[string]string{"token": "REDACTED"}, "")
+	redacted.ResourceVersion = "10"
+	if err := rs.BatchWrite(ctx, "cluster-1", []storage.ResourceChange{{Object: redacted}}); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+
+	got := &apps.Deployment{}
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-1", got); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got.Labels["token"] != "REDACTED" {
+		t.Errorf("expected the transformed deployment, but got the labels %v", got.Labels)
+	}
+
+	older := newTestDeployment("ns-1", "deploy-1", map[string]string{"token": "secret"}, "")
+	older.ResourceVersion = "9"
+	if err := rs.Upsert(ctx, "cluster-1", older); err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if err := rs.Get(ctx, "cluster-1", "ns-1", "deploy-1", got); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got.Labels["token"] != "REDACTED" {
+		t.Errorf("expected the older deployment is not written, but got the labels %v", got.Labels)
+	}
+}
+
+func TestSQLiteResourceStorage_RelatedResources(t *testing.T) {
+	factory, deployments := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+	replicasets := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Group: "apps", Resource: "replicasets"})
+	pods := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Resource: "pods"})
+	events := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Resource: "events"})
+
+	newEvent := func(name string, involved types.UID) *corev1.Event {
+		return &corev1.Event{
+			TypeMeta:       metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "ns-1", Name: name, UID: types.UID("ns-1-" + name), ResourceVersion: "1"},
+			InvolvedObject: corev1.ObjectReference{UID: involved},
+			Message:        name,
+		}
+	}
+
+	ctx := context.TODO()
+	for _, create := range []struct {
+		rs      *ResourceStorage
+		cluster string
+		obj     runtime.Object
+	}{
+		{deployments, "cluster-1", newTestDeployment("ns-1", "deploy-1", nil, "")},
+		{deployments, "cluster-1", newTestDeployment("ns-1", "deploy-2", nil, "")},
+		{replicasets, "cluster-1", newTestReplicaSet("ns-1", "rs-1", "ns-1-deploy-1")},
+		{pods, "cluster-1", newTestPod("ns-1", "pod-1", "ns-1-rs-1")},
+		{pods, "cluster-2", newTestPod("ns-1", "pod-2", "ns-1-rs-1")},
+		{events, "cluster-1", newEvent("event-1", "ns-1-deploy-1")},
+		{events, "cluster-1", newEvent("event-2", "ns-1-pod-1")},
+		{events, "cluster-1", newEvent("event-3", "ns-1-deploy-2")},
+	} {
+		utils.InjectClusterName(create.obj, create.cluster)
+		if err := create.rs.Create(ctx, create.cluster, create.obj); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	relatedNames := func(obj metav1.Object) []string {
+		var related []map[string]interface{}
+		if err := json.Unmarshal([]byte(obj.GetAnnotations()[internal.ShadowAnnotationRelatedResources]), &related); err != nil {
+			t.Fatalf("unmarshal related resources failed: %v", err)
+		}
+		names := []string{}
+		for _, r := range related {
+			names = append(names, r["kind"].(string)+"/"+r["metadata"].(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	opts := &internal.ListOptions{RelatedResources: []schema.GroupResource{{Resource: "pods"}, {Resource: "events"}}}
+	list := &apps.DeploymentList{}
+	if err := deployments.List(ctx, list, &internal.ListOptions{}); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if err := deployments.ResolveRelatedResources(ctx, list, opts); err != nil {
+		t.Fatalf("resolve related resources failed: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("expected 2 items, but got %d", len(list.Items))
+	}
+	if names := relatedNames(&list.Items[0]); !reflect.DeepEqual(names, []string{"Pod/pod-1", "Event/event-1", "Event/event-2"}) {
+		t.Errorf("unexpected related resources of deploy-1: %v", names)
+	}
+	if names := relatedNames(&list.Items[1]); !reflect.DeepEqual(names, []string{"Event/event-3"}) {
+		t.Errorf("unexpected related resources of deploy-2: %v", names)
+	}
+
+	deploy := &apps.Deployment{}
+	if err := deployments.Get(ctx, "cluster-1", "ns-1", "deploy-1", deploy); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	opts = &internal.ListOptions{RelatedResources: []schema.GroupResource{{Group: "apps", Resource: "replicasets"}}, OnlyMetadata: true}
+	if err := deployments.ResolveRelatedResources(ctx, deploy, opts); err != nil {
+		t.Fatalf("resolve related resources failed: %v", err)
+	}
+	if names := relatedNames(deploy); !reflect.DeepEqual(names, []string{"ReplicaSet/rs-1"}) {
+		t.Errorf("unexpected related resources: %v", names)
+	}
+
+	// the events stored before the involved uid column is added
+	if result := factory.db.Model(&Resource{}).Where("resource = ?", "events").Update("involved_uid", ""); result.Error != nil {
+		t.Fatalf("clear the involved uids failed: %v", result.Error)
+	}
+	if err := migrateInvolvedUIDs(factory.db); err != nil {
+		t.Fatalf("migrate the involved uids failed: %v", err)
+	}
+	opts = &internal.ListOptions{RelatedResources: []schema.GroupResource{{Resource: "events"}}}
+	if err := deployments.ResolveRelatedResources(ctx, deploy, opts); err != nil {
+		t.Fatalf("resolve related resources failed: %v", err)
+	}
+	if names := relatedNames(deploy); !reflect.DeepEqual(names, []string{"Event/event-1", "Event/event-2"}) {
+		t.Errorf("unexpected related events after the migration: %v", names)
+	}
+}
+
+func TestSQLiteResourceStorage_OwnerInClusters(t *testing.T) {
+	factory, deployments := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+	replicasets := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Group: "apps", Resource: "replicasets"})
+	pods := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Resource: "pods"})
+
+	ctx := context.TODO()
+	for _, create := range []struct {
+		rs      *ResourceStorage
+		cluster string
+		obj     runtime.Object
+	}{
+		{deployments, "cluster-1", newTestDeployment("ns-1", "frontend", nil, "")},
+		{deployments, "cluster-2", newTestDeployment("ns-1", "frontend", nil, "")},
+		{replicasets, "cluster-1", newTestReplicaSet("ns-1", "frontend-1", "ns-1-frontend")},
+		{replicasets, "cluster-2", newTestReplicaSet("ns-1", "frontend-2", "ns-1-frontend")},
+		// the owner with the same uid is not in cluster-3
+		{replicasets, "cluster-3", newTestReplicaSet("ns-1", "frontend-3", "ns-1-frontend")},
+		{pods, "cluster-1", newTestPod("ns-1", "frontend-1-a", "ns-1-frontend-1")},
+		{pods, "cluster-1", newTestPod("ns-1", "frontend-1-b", "ns-1-frontend-1")},
+		{pods, "cluster-2", newTestPod("ns-1", "frontend-2-a", "ns-1-frontend-2")},
+		{pods, "cluster-3", newTestPod("ns-1", "frontend-3-a", "ns-1-frontend-3")},
+		// the owner uid of cluster-2 in cluster-1
+		{pods, "cluster-1", newTestPod("ns-1", "other", "ns-1-frontend-2")},
+	} {
+		if err := create.rs.Create(ctx, create.cluster, create.obj); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     *internal.ListOptions
+		expected []string
+	}{
+		{
+			"owner name in all clusters",
+			&internal.ListOptions{OwnerName: "frontend", OwnerGroupResource: schema.GroupResource{Group: "apps", Resource: "deployments"}, OwnerSeniority: 1},
+			[]string{"frontend-1-a", "frontend-1-b", "frontend-2-a"},
+		},
+		{
+			"owner name in multi clusters",
+			&internal.ListOptions{ClusterNames: []string{"cluster-2", "cluster-3"}, OwnerName: "frontend", OwnerSeniority: 1},
+			[]string{"frontend-2-a"},
+		},
+		{
+			"owner uid in all clusters",
+			&internal.ListOptions{OwnerUID: "ns-1-frontend", OwnerSeniority: 1},
+			[]string{"frontend-1-a", "frontend-1-b", "frontend-2-a", "frontend-3-a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.OrderBy = []internal.OrderBy{{Field: "name"}}
+			list := &core.PodList{}
+			if err := pods.List(ctx, list, test.opts); err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+			var names []string
+			for _, pod := range list.Items {
+				names = append(names, pod.Name)
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, but got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func TestSQLiteResourceStorage_RootOwner(t *testing.T) {
+	factory, deployments := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, nil)
+	replicasets := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Group: "apps", Resource: "replicasets"})
+	pods := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Resource: "pods"})
+
+	withOwner := func(obj metav1.Object, kind, name string) metav1.Object {
+		obj.GetOwnerReferences()[0].Kind, obj.GetOwnerReferences()[0].Name = kind, name
+		return obj
+	}
+	replicaset := withOwner(newTestReplicaSet("ns-1", "frontend-1", "ns-1-frontend"), "Deployment", "frontend").(*appsv1.ReplicaSet)
+
+	ctx := context.TODO()
+	// the owned resources are written before their owners
+	for _, create := range []struct {
+		rs  *ResourceStorage
+		obj runtime.Object
+	}{
+		{pods, withOwner(newTestPod("ns-1", "frontend-1-a", "ns-1-frontend-1"), "ReplicaSet", "frontend-1").(*corev1.Pod)},
+		{replicasets, replicaset},
+		{deployments, newTestDeployment("ns-1", "frontend", nil, "")},
+		{pods, withOwner(newTestPod("ns-1", "frontend-1-b", "ns-1-frontend-1"), "ReplicaSet", "frontend-1").(*corev1.Pod)},
+		{pods, newTestPod("ns-1", "standalone", "")},
+	} {
+		if err := create.rs.Create(ctx, "cluster-1", create.obj); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	listNames := func(rs *ResourceStorage, list runtime.Object, opts *internal.ListOptions) []string {
+		opts.OrderBy = []internal.OrderBy{{Field: "name"}}
+		if err := rs.List(ctx, list, opts); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, obj := range objs {
+			names = append(names, obj.(metav1.Object).GetName())
+		}
+		return names
+	}
+
+	opts := &internal.ListOptions{RootOwnerKind: "Deployment", RootOwnerName: "frontend"}
+	if names := listNames(pods, &core.PodList{}, opts); !reflect.DeepEqual(names, []string{"frontend-1-a", "frontend-1-b"}) {
+		t.Errorf("unexpected pods of the root owner: %v", names)
+	}
+	if names := listNames(replicasets, &apps.ReplicaSetList{}, &internal.ListOptions{RootOwnerName: "frontend"}); !reflect.DeepEqual(names, []string{"frontend-1"}) {
+		t.Errorf("unexpected replicasets of the root owner: %v", names)
+	}
+	if names := listNames(pods, &core.PodList{}, &internal.ListOptions{RootOwnerKind: "CronJob", RootOwnerName: "frontend"}); len(names) != 0 {
+		t.Errorf("expected no pods of the other kind, but got %v", names)
+	}
+
+	// the replicaset is adopted by another deployment
+	replicaset = withOwner(newTestReplicaSet("ns-1", "frontend-1", "ns-1-backend"), "Deployment", "backend").(*appsv1.ReplicaSet)
+	replicaset.ResourceVersion = "2"
+	if err := replicasets.Upsert(ctx, "cluster-1", replicaset); err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	counts, err := pods.Count(ctx, &internal.ListOptions{GroupBy: []string{"rootOwnerKind", "rootOwnerName"}})
+	if err != nil {
+		t.Fatalf("count failed: %v", err)
+	}
+	expected := []internal.ResourceCount{
+		{Values: map[string]string{"rootOwnerKind": "Deployment", "rootOwnerName": "backend"}, Count: 2},
+		{Values: map[string]string{"rootOwnerKind": "", "rootOwnerName": ""}, Count: 1},
+	}
+	if !reflect.DeepEqual(counts.Items, expected) {
+		t.Errorf("expected counts %v, but got %v", expected, counts.Items)
+	}
+}
+
+func TestSQLiteResourceStorage_RootOwnerChanges(t *testing.T) {
+	factory, deployments := newSQLiteResourceStorage(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, &WatchConfig{PollInterval: 10 * time.Millisecond})
+	replicasets := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Group: "apps", Resource: "replicasets"})
+	pods := newSQLiteFactoryResourceStorage(t, factory, schema.GroupResource{Resource: "pods"})
+
+	pod := newTestPod("ns-1", "frontend-1-a", "ns-1-frontend-1")
+	pod.OwnerReferences[0].Kind, pod.OwnerReferences[0].Name = "ReplicaSet", "frontend-1"
+	replicaset := newTestReplicaSet("ns-1", "frontend-1", "ns-1-frontend")
+	replicaset.OwnerReferences[0].Kind, replicaset.OwnerReferences[0].Name = "Deployment", "frontend"
+
+	ctx := context.TODO()
+	if err := pods.Create(ctx, "cluster-1", pod); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if err := replicasets.Create(ctx, "cluster-1", replicaset); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	// the deployment is stored in the batch, and the root owners of the owned resources are changed in the same transaction
+	deployment := newTestDeployment("ns-1", "frontend", nil, "")
+	if err := deployments.BatchWrite(ctx, "cluster-1", []storage.ResourceChange{{Object: deployment}}); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+
+	var events []ResourceEvent
+	if result := factory.db.Where("resource = ?", "pods").Order("id").Find(&events); result.Error != nil {
+		t.Fatalf("find events failed: %v", result.Error)
+	}
+	var got []string
+	for _, event := range events {
+		got = append(got, fmt.Sprintf("%s %s %s/%s", event.Type, event.Name, event.RootOwnerKind, event.RootOwnerName))
+	}
+	expected := []string{"ADDED frontend-1-a ReplicaSet/frontend-1", "MODIFIED frontend-1-a Deployment/frontend"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the pod events %v, but got %v", expected, got)
+	}
+
+	watcher, err := pods.Watch(ctx, &internal.ListOptions{
+		ListOptions:   metainternal.ListOptions{ResourceVersion: strconv.Itoa(int(events[0].ID))},
+		RootOwnerKind: "Deployment", RootOwnerName: "frontend",
+	})
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	defer watcher.Stop()
+	select {
+	case event := <-watcher.ResultChan():
+		if event.Type != watch.Modified || event.Object.(metav1.Object).GetName() != "frontend-1-a" {
+			t.Errorf("expected the modified pod, but got %s %v", event.Type, event.Object)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the watch event of the root owner")
+	}
+}
@@ -3,26 +3,42 @@ package internalstorage
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
 
 	internal "github.com/clusterpedia-io/api/clusterpedia"
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
 )
 
 type StorageFactory struct {
-	db *gorm.DB
+	db         *gorm.DB
+	watch      *WatchConfig
+	history    *HistoryConfig
+	softDelete *SoftDeleteConfig
+
+	fullTextSearch bool
 }
 
 func (s *StorageFactory) GetSupportedRequestVerbs() []string {
+	if s.watch != nil {
+		return []string{"get", "list", "watch"}
+	}
 	return []string{"get", "list"}
 }
 
 func (s *StorageFactory) NewResourceStorage(config *storage.ResourceStorageConfig) (storage.ResourceStorage, error) {
 	return &ResourceStorage{
-		db:    s.db,
-		codec: config.Codec,
+		db:         s.db,
+		codec:      config.Codec,
+		watch:      s.watch,
+		history:    s.history,
+		softDelete: s.softDelete,
+
+		fullTextSearch: s.fullTextSearch,
 
 		storageGroupResource: config.StorageGroupResource,
 		storageVersion:       config.StorageVersion,
@@ -31,18 +47,22 @@ func (s *StorageFactory) NewResourceStorage(config *storage.ResourceStorageConfi
 }
 
 func (s *StorageFactory) NewCollectionResourceStorage(cr *internal.CollectionResource) (storage.CollectionResourceStorage, error) {
-	for i := range collectionResources {
-		if collectionResources[i].Name == cr.Name {
-			return NewCollectionResourceStorage(s.db, cr), nil
-		}
+	if storage.IsBuiltInCollectionResource(cr.Name) {
+		return NewCollectionResourceStorage(s.db, cr, s.fullTextSearch), nil
 	}
-	return nil, fmt.Errorf("not support collection resource: %s", cr.Name)
+
+	// The custom collection resource must specify the resource types,
+	// otherwise it will match all resources just like the *any* collection resource.
+	if len(cr.ResourceTypes) == 0 {
+		return nil, fmt.Errorf("not support collection resource: %s, resource types are required", cr.Name)
+	}
+	return NewCollectionResourceStorage(s.db, cr, s.fullTextSearch), nil
 }
 
 func (f *StorageFactory) GetResourceVersions(ctx context.Context, cluster string) (map[schema.GroupVersionResource]map[string]interface{}, error) {
 	var resources []Resource
 	result := f.db.WithContext(ctx).Select("group", "version", "resource", "namespace", "name", "resource_version").
-		Where(map[string]interface{}{"cluster": cluster}).
+		Where(map[string]interface{}{"cluster": cluster}).Where("removed_at IS NULL").
 		Find(&resources)
 	if result.Error != nil {
 		return nil, InterpretDBError(cluster, result.Error)
@@ -67,26 +87,91 @@ func (f *StorageFactory) GetResourceVersions(ctx context.Context, cluster string
 }
 
 func (f *StorageFactory) CleanCluster(ctx context.Context, cluster string) error {
-	result := f.db.WithContext(ctx).Where(map[string]interface{}{"cluster": cluster}).Delete(&Resource{})
-	return InterpretDBError(cluster, result.Error)
+	where := map[string]interface{}{"cluster": cluster}
+	if err := f.cleanResources(ctx, where); err != nil {
+		return InterpretDBError(cluster, err)
+	}
+	return InterpretDBError(cluster, f.closeResourceHistories(ctx, where))
 }
 
 func (s *StorageFactory) CleanClusterResource(ctx context.Context, cluster string, gvr schema.GroupVersionResource) error {
-	result := s.db.Where(map[string]interface{}{
+	where := map[string]interface{}{
 		"cluster":  cluster,
 		"group":    gvr.Group,
 		"version":  gvr.Version,
 		"resource": gvr.Resource,
-	}).Delete(&Resource{})
-	return InterpretDBError(fmt.Sprintf("%s/%s", cluster, gvr), result.Error)
+	}
+	if err := s.cleanResources(ctx, where); err != nil {
+		return InterpretDBError(fmt.Sprintf("%s/%s", cluster, gvr), err)
+	}
+	return InterpretDBError(fmt.Sprintf("%s/%s", cluster, gvr), s.closeResourceHistories(ctx, where))
+}
+
+// cleanEventsBatchSize is the number of the cleaned resources whose delete events are recorded in a batch.
+const cleanEventsBatchSize = 500
+
+// cleanResources removes the resources and their tombstones, if the watch is enabled,
+// the delete events of the live resources are recorded in the same transaction,
+// so the watchers see the removed resources as deleted.
+func (s *StorageFactory) cleanResources(ctx context.Context, where map[string]interface{}) error {
+	if s.watch == nil {
+		return s.db.WithContext(ctx).Where(where).Delete(&Resource{}).Error
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var resources []Resource
+		result := tx.Where(where).Where("removed_at IS NULL").FindInBatches(&resources, cleanEventsBatchSize, func(_ *gorm.DB, _ int) error {
+			events := make([]*ResourceEvent, 0, len(resources))
+			for i := range resources {
+				events = append(events, newResourceEvent(watch.Deleted, &resources[i]))
+			}
+			return tx.Create(events).Error
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Where(where).Delete(&Resource{}).Error
+	})
 }
 
 func (s *StorageFactory) GetCollectionResources(ctx context.Context) ([]*internal.CollectionResource, error) {
-	var crs []*internal.CollectionResource
-	for _, cr := range collectionResources {
-		crs = append(crs, cr.DeepCopy())
+	return storage.BuiltInCollectionResources(), nil
+}
+
+func (s *StorageFactory) cleanExpiredResourceEvents() {
+	expired := time.Now().Add(-s.watch.EventRetention)
+	result := s.db.Where("recorded_at < ?", expired).Delete(&ResourceEvent{})
+	if result.Error != nil {
+		klog.ErrorS(result.Error, "Failed to clean expired resource events")
+	}
+}
+
+// closeResourceHistories closes the current versions of the cleaned resources,
+// the histories are kept until they expire, so the point-in-time queries still work.
+func (s *StorageFactory) closeResourceHistories(ctx context.Context, where map[string]interface{}) error {
+	if s.history == nil {
+		return nil
+	}
+	result := s.db.WithContext(ctx).Model(&ResourceHistory{}).Where(where).Where("valid_to IS NULL").Update("valid_to", time.Now().UTC())
+	return result.Error
+}
+
+func (s *StorageFactory) cleanExpiredResourceHistories() {
+	expired := time.Now().Add(-s.history.Retention).UTC()
+	result := s.db.Where("valid_to < ?", expired).Delete(&ResourceHistory{})
+	if result.Error != nil {
+		klog.ErrorS(result.Error, "Failed to clean expired resource histories")
+	}
+}
+
+// cleanExpiredTombstones removes the tombstones of the resources that were deleted
+// from the clusters before the retention of the soft deletion.
+func (s *StorageFactory) cleanExpiredTombstones() {
+	expired := time.Now().Add(-s.softDelete.Retention).UTC()
+	result := s.db.Where("removed_at < ?", expired).Delete(&Resource{})
+	if result.Error != nil {
+		klog.ErrorS(result.Error, "Failed to clean expired tombstones of the resources")
 	}
-	return crs, nil
 }
 
 func (s *StorageFactory) PrepareCluster(cluster string) error {
@@ -3,6 +3,7 @@ package internalstorage
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 type Object interface {
@@ -26,6 +28,10 @@ type ObjectList interface {
 	Select(db *gorm.DB) *gorm.DB
 	From(db *gorm.DB) error
 	Items() []Object
+
+	// ContinueKeys returns the primary key and the sort keys of the last item,
+	// they are used to build the keyset continue token.
+	ContinueKeys() (uint, []byte)
 }
 
 type ResourceType struct {
@@ -50,23 +56,144 @@ func (rt ResourceType) GroupVersionResource() schema.GroupVersionResource {
 type Resource struct {
 	ID uint `gorm:"primaryKey"`
 
-	Group    string `gorm:"size:63;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
-	Version  string `gorm:"size:15;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
-	Resource string `gorm:"size:63;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
+	Group    string `gorm:"size:63;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name_tombstone_id;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
+	Version  string `gorm:"size:15;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name_tombstone_id;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
+	Resource string `gorm:"size:63;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name_tombstone_id;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
 	Kind     string `gorm:"size:63;not null"`
 
-	Cluster         string    `gorm:"size:253;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name,length:100;index:idx_cluster"`
-	Namespace       string    `gorm:"size:253;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name,length:50;index:idx_group_version_resource_namespace_name"`
-	Name            string    `gorm:"size:253;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name,length:100;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
-	OwnerUID        types.UID `gorm:"column:owner_uid;size:36;not null;default:''"`
+	Cluster         string    `gorm:"size:253;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name_tombstone_id,length:100;index:idx_cluster"`
+	Namespace       string    `gorm:"size:253;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name_tombstone_id,length:50;index:idx_group_version_resource_namespace_name"`
+	Name            string    `gorm:"size:253;not null;uniqueIndex:uni_group_version_resource_cluster_namespace_name_tombstone_id,length:100;index:idx_group_version_resource_namespace_name;index:idx_group_version_resource_name"`
+	OwnerUID        types.UID `gorm:"column:owner_uid;size:36;not null;default:'';index:idx_owner_uid"`
 	UID             types.UID `gorm:"size:36;not null"`
 	ResourceVersion string    `gorm:"size:30;not null"`
 
+	// InvolvedUID is the uid of the object involved by the event,
+	// it is only set for the events, so the events of the related resources are found by the index.
+	InvolvedUID types.UID `gorm:"column:involved_uid;size:36;not null;default:'';index:idx_involved_uid"`
+
+	// RootOwner is the ultimate controller owner of the resource,
+	// such as the Deployment of a Pod, it is empty if the resource has no owner.
+	RootOwnerKind string    `gorm:"size:63;not null;default:'';index:idx_root_owner,priority:2"`
+	RootOwnerName string    `gorm:"size:253;not null;default:'';index:idx_root_owner,priority:1,length:100"`
+	RootOwnerUID  types.UID `gorm:"column:root_owner_uid;size:36;not null;default:''"`
+
 	Object datatypes.JSON `gorm:"not null"`
 
 	CreatedAt time.Time `gorm:"not null"`
 	SyncedAt  time.Time `gorm:"not null;autoUpdateTime"`
 	DeletedAt sql.NullTime
+
+	// RemovedAt is the time when the resource was deleted from the cluster,
+	// it is only set for the tombstones kept by the soft deletion.
+	// DeletedAt is the `deletionTimestamp` of the resource, which is set before the deletion.
+	RemovedAt sql.NullTime `gorm:"index"`
+
+	// TombstoneID is set to the ID of the tombstone, and it is 0 for the live resource,
+	// so the tombstones are kept when the resource with the same name is created again.
+	TombstoneID uint `gorm:"not null;default:0;uniqueIndex:uni_group_version_resource_cluster_namespace_name_tombstone_id"`
+
+	// ContinueKeys are the sort keys selected by the list query, it is not a column of the table.
+	ContinueKeys Bytes `gorm:"->;-:migration"`
+}
+
+// ResourceEvent records the changes of the resources for watch,
+// the auto-increment ID is used as the resourceVersion of the watch events,
+// so it is monotonic across all clusters.
+type ResourceEvent struct {
+	ID uint `gorm:"primaryKey"`
+
+	Group    string `gorm:"size:63;not null;index:idx_event_group_version_resource"`
+	Version  string `gorm:"size:15;not null;index:idx_event_group_version_resource"`
+	Resource string `gorm:"size:63;not null;index:idx_event_group_version_resource"`
+	Kind     string `gorm:"size:63;not null"`
+
+	Cluster         string    `gorm:"size:253;not null"`
+	Namespace       string    `gorm:"size:253;not null"`
+	Name            string    `gorm:"size:253;not null"`
+	OwnerUID        types.UID `gorm:"column:owner_uid;size:36;not null;default:''"`
+	UID             types.UID `gorm:"size:36;not null"`
+	ResourceVersion string    `gorm:"size:30;not null"`
+
+	RootOwnerKind string    `gorm:"size:63;not null;default:''"`
+	RootOwnerName string    `gorm:"size:253;not null;default:''"`
+	RootOwnerUID  types.UID `gorm:"column:root_owner_uid;size:36;not null;default:''"`
+
+	Type   watch.EventType `gorm:"size:15;not null"`
+	Object datatypes.JSON  `gorm:"not null"`
+
+	CreatedAt  time.Time `gorm:"not null"`
+	RecordedAt time.Time `gorm:"not null;autoCreateTime;index"`
+}
+
+// ResourceHistory records the versions of the resources, each version is valid
+// from `ValidFrom` until `ValidTo`, and the current version has no `ValidTo`.
+type ResourceHistory struct {
+	ID uint `gorm:"primaryKey"`
+
+	Group    string `gorm:"size:63;not null;index:idx_history_group_version_resource_cluster_namespace_name"`
+	Version  string `gorm:"size:15;not null;index:idx_history_group_version_resource_cluster_namespace_name"`
+	Resource string `gorm:"size:63;not null;index:idx_history_group_version_resource_cluster_namespace_name"`
+	Kind     string `gorm:"size:63;not null"`
+
+	Cluster         string    `gorm:"size:253;not null;index:idx_history_group_version_resource_cluster_namespace_name,length:100"`
+	Namespace       string    `gorm:"size:253;not null;index:idx_history_group_version_resource_cluster_namespace_name,length:50"`
+	Name            string    `gorm:"size:253;not null;index:idx_history_group_version_resource_cluster_namespace_name,length:100"`
+	OwnerUID        types.UID `gorm:"column:owner_uid;size:36;not null;default:''"`
+	UID             types.UID `gorm:"size:36;not null"`
+	ResourceVersion string    `gorm:"size:30;not null"`
+
+	RootOwnerKind string    `gorm:"size:63;not null;default:''"`
+	RootOwnerName string    `gorm:"size:253;not null;default:''"`
+	RootOwnerUID  types.UID `gorm:"column:root_owner_uid;size:36;not null;default:''"`
+
+	Object datatypes.JSON `gorm:"not null"`
+
+	CreatedAt time.Time    `gorm:"not null"`
+	ValidFrom time.Time    `gorm:"not null;index"`
+	ValidTo   sql.NullTime `gorm:"index"`
+}
+
+func newResourceHistory(resource *Resource, validFrom time.Time) *ResourceHistory {
+	return &ResourceHistory{
+		Group:           resource.Group,
+		Version:         resource.Version,
+		Resource:        resource.Resource,
+		Kind:            resource.Kind,
+		Cluster:         resource.Cluster,
+		Namespace:       resource.Namespace,
+		Name:            resource.Name,
+		OwnerUID:        resource.OwnerUID,
+		RootOwnerKind:   resource.RootOwnerKind,
+		RootOwnerName:   resource.RootOwnerName,
+		RootOwnerUID:    resource.RootOwnerUID,
+		UID:             resource.UID,
+		ResourceVersion: resource.ResourceVersion,
+		Object:          resource.Object,
+		CreatedAt:       resource.CreatedAt,
+		ValidFrom:       validFrom,
+	}
+}
+
+func newResourceEvent(eventType watch.EventType, resource *Resource) *ResourceEvent {
+	return &ResourceEvent{
+		Group:           resource.Group,
+		Version:         resource.Version,
+		Resource:        resource.Resource,
+		Kind:            resource.Kind,
+		Cluster:         resource.Cluster,
+		Namespace:       resource.Namespace,
+		Name:            resource.Name,
+		OwnerUID:        resource.OwnerUID,
+		RootOwnerKind:   resource.RootOwnerKind,
+		RootOwnerName:   resource.RootOwnerName,
+		RootOwnerUID:    resource.RootOwnerUID,
+		UID:             resource.UID,
+		ResourceVersion: resource.ResourceVersion,
+		Type:            eventType,
+		Object:          resource.Object,
+		CreatedAt:       resource.CreatedAt,
+	}
 }
 
 func (res Resource) GroupVersionResource() schema.GroupVersionResource {
@@ -100,9 +227,13 @@ func (res Resource) ConvertTo(codec runtime.Codec, object runtime.Object) (runti
 }
 
 type ResourceMetadata struct {
+	ID uint
+
 	ResourceType `gorm:"embedded"`
 
 	Metadata datatypes.JSON
+
+	ContinueKeys Bytes
 }
 
 func (data ResourceMetadata) ConvertToUnstructured() (*unstructured.Unstructured, error) {
@@ -175,6 +306,20 @@ func (bytes Bytes) GetResourceType() ResourceType {
 	return ResourceType{}
 }
 
+// Scan implements the sql.Scanner interface,
+// some drivers, such as sqlite, return the json column as a string.
+func (bytes *Bytes) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		*bytes = append((*bytes)[0:0], v...)
+	case string:
+		*bytes = Bytes(v)
+	default:
+		return fmt.Errorf("failed to scan %T into Bytes", value)
+	}
+	return nil
+}
+
 type ResourceList []Resource
 
 func (list ResourceList) Select(query *gorm.DB) *gorm.DB {
@@ -190,6 +335,14 @@ func (list *ResourceList) From(db *gorm.DB) error {
 	return nil
 }
 
+func (list ResourceList) ContinueKeys() (uint, []byte) {
+	if len(list) == 0 {
+		return 0, nil
+	}
+	last := list[len(list)-1]
+	return last.ID, last.ContinueKeys
+}
+
 func (list ResourceList) Items() []Object {
 	objects := make([]Object, 0, len(list))
 	for _, object := range list {
@@ -206,8 +359,10 @@ func (list ResourceMetadataList) Select(query *gorm.DB) *gorm.DB {
 		return query.Select("`group`, version, resource, kind, object->>'$.metadata' as metadata")
 	case "postgres":
 		return query.Select(`"group", version, resource, kind, object->>'metadata' as metadata`)
+	case "sqlite":
+		return query.Select("`group`, version, resource, kind, JSON_EXTRACT(object, '$.metadata') as metadata")
 	}
-	panic("storage: only support mysql or postgres")
+	panic("storage: only support mysql, postgres or sqlite")
 }
 
 func (list *ResourceMetadataList) From(db *gorm.DB) error {
@@ -219,6 +374,14 @@ func (list *ResourceMetadataList) From(db *gorm.DB) error {
 	return nil
 }
 
+func (list ResourceMetadataList) ContinueKeys() (uint, []byte) {
+	if len(list) == 0 {
+		return 0, nil
+	}
+	last := list[len(list)-1]
+	return last.ID, last.ContinueKeys
+}
+
 func (list ResourceMetadataList) Items() []Object {
 	objects := make([]Object, 0, len(list))
 	for _, object := range list {
@@ -227,7 +390,13 @@ func (list ResourceMetadataList) Items() []Object {
 	return objects
 }
 
-type BytesList []Bytes
+type BytesObject struct {
+	ID           uint
+	Object       Bytes
+	ContinueKeys Bytes
+}
+
+type BytesList []BytesObject
 
 func (list BytesList) Select(query *gorm.DB) *gorm.DB {
 	return query.Select("object")
@@ -240,10 +409,18 @@ func (list *BytesList) From(db *gorm.DB) error {
 	return nil
 }
 
+func (list BytesList) ContinueKeys() (uint, []byte) {
+	if len(list) == 0 {
+		return 0, nil
+	}
+	last := list[len(list)-1]
+	return last.ID, last.ContinueKeys
+}
+
 func (list BytesList) Items() []Object {
 	objects := make([]Object, 0, len(list))
 	for _, object := range list {
-		objects = append(objects, object)
+		objects = append(objects, object.Object)
 	}
 	return objects
 }
@@ -104,6 +104,10 @@ func applyListOptionsToQuery(query *gorm.DB, opts *internal.ListOptions, applyFn
 		}
 	}
 
+	if opts.Query != "" {
+		query = query.Where(FullTextQuery(opts.Query))
+	}
+
 	if opts.EnhancedFieldSelector != nil {
 		if requirements, selectable := opts.EnhancedFieldSelector.Requirements(); selectable {
 			for _, requirement := range requirements {
@@ -155,6 +159,18 @@ func applyListOptionsToQuery(query *gorm.DB, opts *internal.ListOptions, applyFn
 		}
 	}
 
+	// The keyset continue token seeks from the last row of the previous page,
+	// it needs to be applied before counting the remaining items.
+	keyset := shouldUseKeysetContinue(opts)
+	if keyset && opts.Continue != "" {
+		token, err := decodeContinueToken(opts.Continue, opts.OrderBy)
+		if err != nil {
+			return 0, nil, nil, apierrors.NewBadRequest(err.Error())
+		}
+		expression, args := token.seekExpression(opts.OrderBy)
+		query = query.Where(expression, args...)
+	}
+
 	var amount *int64
 	if opts.WithRemainingCount != nil && *opts.WithRemainingCount {
 		amount = new(int64)
@@ -164,28 +180,57 @@ func applyListOptionsToQuery(query *gorm.DB, opts *internal.ListOptions, applyFn
 	// Due to performance reasons, the default order by is not set.
 	// https://github.com/clusterpedia-io/clusterpedia/pull/44
 	for _, orderby := range opts.OrderBy {
-		orderByField := orderby.Field
-		if orderByField == "resource_version" {
-			orderByField = "CAST(resource_version as decimal)"
-		}
-
 		column := clause.OrderByColumn{
-			Column: clause.Column{Name: orderByField, Raw: true},
+			Column: clause.Column{Name: orderByColumn(orderby.Field), Raw: true},
 			Desc:   orderby.Desc,
 		}
 		query = query.Order(column)
 
 		// if orderby.Field is unsupported, return invalid error?
 	}
+	if isSortedByRelevance(opts) && query.Dialector.Name() != "sqlite" {
+		query = query.Clauses(clause.OrderBy{
+			Expression: clause.Expr{SQL: "? DESC", Vars: []interface{}{FullTextRank(opts.Query)}},
+		})
+	}
+	if keyset {
+		// The primary key is used as the last sort key to keep the order stable,
+		// it is also selected with the sort keys to build the continue token of the next page.
+		query = query.Order("id")
+
+		var columns []string
+		if len(opts.OrderBy) != 0 {
+			columns = append(columns, continueKeysColumn(query.Dialector.Name(), opts.OrderBy))
+		}
+		if selects := query.Statement.Selects; len(selects) != 0 {
+			query = query.Select(append(selects[0:len(selects):len(selects)], append([]string{"id"}, columns...)...))
+		} else if len(columns) != 0 {
+			query = query.Select(append([]string{"*"}, columns...))
+		}
+	}
 	// kube ListOptions does not specify a limit default value of 0, gorm will execute limit = 0, resulting in the return of empty data.
 	// https://github.com/go-gorm/gorm/commit/e8f48b5c155b6fbf2e1fe6a554e2280f62af21a7
 	if opts.Limit > 0 {
 		query = query.Limit(int(opts.Limit))
 	}
 
-	offset, err := strconv.Atoi(opts.Continue)
-	if err == nil {
+	var offset int
+	if isOffsetContinue(opts.Continue) {
+		offset, _ = strconv.Atoi(opts.Continue)
 		query = query.Offset(offset)
 	}
 	return int64(offset), amount, query, nil
 }
+
+// applyDeletedToQuery filters the tombstones of the resources by `IncludeDeleted` and `OnlyDeleted`,
+// the tombstones are only kept if the soft deletion is enabled.
+func applyDeletedToQuery(query *gorm.DB, opts *internal.ListOptions) *gorm.DB {
+	switch {
+	case opts.OnlyDeleted:
+		return query.Where("removed_at IS NOT NULL")
+	case opts.IncludeDeleted:
+		return query
+	default:
+		return query.Where("removed_at IS NULL")
+	}
+}
@@ -508,9 +508,9 @@ func TestApplyListOptionsToQuery_Page(t *testing.T) {
 			"bad continue",
 			0, "abdfdf",
 			expected{
-				`SELECT * FROM "resources"`,
-				"SELECT * FROM `resources`",
 				"",
+				"",
+				"invalid continue token: invalid character 'i' looking for beginning of value",
 			},
 		},
 		{
@@ -531,6 +531,117 @@ func TestApplyListOptionsToQuery_Page(t *testing.T) {
 	}
 }
 
+func TestApplyListOptionsToQuery_KeysetContinue(t *testing.T) {
+	token, err := (&continueToken{
+		Version: continueTokenVersion,
+		OrderBy: []string{"name", "created_at desc"},
+		Keys:    []interface{}{"deploy-1", "2022-03-04 00:00:00"},
+		ID:      10,
+	}).encode()
+	if err != nil {
+		t.Fatalf("encode continue token failed: %v", err)
+	}
+	withContinue := true
+
+	tests := []struct {
+		name         string
+		limit        int64
+		continueStr  string
+		orderby      []internal.OrderBy
+		withContinue *bool
+		expected     expected
+	}{
+		{
+			"first page",
+			10, "", nil, &withContinue,
+			expected{
+				`SELECT * FROM "resources" ORDER BY id LIMIT 10`,
+				"SELECT * FROM `resources` ORDER BY id LIMIT 10",
+				"",
+			},
+		},
+		{
+			"first page without continue",
+			10, "", nil, nil,
+			expected{
+				`SELECT * FROM "resources" LIMIT 10`,
+				"SELECT * FROM `resources` LIMIT 10",
+				"",
+			},
+		},
+		{
+			"seek",
+			10, token, []internal.OrderBy{{Field: "name"}, {Field: "created_at", Desc: true}}, &withContinue,
+			expected{
+				`SELECT *,json_build_array(name, created_at) AS continue_keys FROM "resources" WHERE (name > 'deploy-1') OR (name = 'deploy-1' AND created_at < '2022-03-04 00:00:00') OR (name = 'deploy-1' AND created_at = '2022-03-04 00:00:00' AND id > 10) ORDER BY name,created_at DESC,id LIMIT 10`,
+				"SELECT *,JSON_ARRAY(name, created_at) AS continue_keys FROM `resources` WHERE (name > 'deploy-1') OR (name = 'deploy-1' AND created_at < '2022-03-04 00:00:00') OR (name = 'deploy-1' AND created_at = '2022-03-04 00:00:00' AND id > 10) ORDER BY name,created_at DESC,id LIMIT 10",
+				"",
+			},
+		},
+		{
+			"orderby changed",
+			10, token, []internal.OrderBy{{Field: "name"}}, &withContinue,
+			expected{
+				"",
+				"",
+				"invalid continue token: the orderby has been changed",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		listOptions := &internal.ListOptions{OrderBy: test.orderby, WithContinue: test.withContinue}
+		listOptions.Limit, listOptions.Continue = test.limit, test.continueStr
+		testApplyListOptionsToQuery(t, test.name, listOptions, test.expected)
+	}
+}
+
+func TestApplyListOptionsToQuery_FullText(t *testing.T) {
+	withContinue := true
+
+	tests := []struct {
+		name         string
+		query        string
+		orderby      []internal.OrderBy
+		withContinue *bool
+		expected     expected
+	}{
+		{
+			"sorted by relevance",
+			"nginx:1.21", nil, nil,
+			expected{
+				`SELECT * FROM "resources" WHERE to_tsvector('simple', object) @@ plainto_tsquery('simple', 'nginx:1.21') ORDER BY ts_rank(to_tsvector('simple', object), plainto_tsquery('simple', 'nginx:1.21')) DESC LIMIT 10`,
+				"SELECT * FROM `resources` WHERE MATCH (`object_text`) AGAINST ('+\"nginx:1.21\"' IN BOOLEAN MODE) ORDER BY MATCH (`object_text`) AGAINST ('+\"nginx:1.21\"' IN BOOLEAN MODE) DESC LIMIT 10",
+				"",
+			},
+		},
+		{
+			"with orderby",
+			"nginx", []internal.OrderBy{{Field: "name"}}, nil,
+			expected{
+				`SELECT * FROM "resources" WHERE to_tsvector('simple', object) @@ plainto_tsquery('simple', 'nginx') ORDER BY name LIMIT 10`,
+				"SELECT * FROM `resources` WHERE MATCH (`object_text`) AGAINST ('+\"nginx\"' IN BOOLEAN MODE) ORDER BY name LIMIT 10",
+				"",
+			},
+		},
+		{
+			"with continue",
+			"nginx", nil, &withContinue,
+			expected{
+				`SELECT * FROM "resources" WHERE to_tsvector('simple', object) @@ plainto_tsquery('simple', 'nginx') ORDER BY ts_rank(to_tsvector('simple', object), plainto_tsquery('simple', 'nginx')) DESC LIMIT 10`,
+				"SELECT * FROM `resources` WHERE MATCH (`object_text`) AGAINST ('+\"nginx\"' IN BOOLEAN MODE) ORDER BY MATCH (`object_text`) AGAINST ('+\"nginx\"' IN BOOLEAN MODE) DESC LIMIT 10",
+				"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		listOptions := &internal.ListOptions{Query: test.query, OrderBy: test.orderby, WithContinue: test.withContinue}
+		listOptions.Limit = 10
+		testApplyListOptionsToQuery(t, test.name, listOptions, test.expected)
+	}
+}
+
 // replace db.ToSQL
 func toSQL(db *gorm.DB, options *internal.ListOptions, applyFn func(*gorm.DB, *internal.ListOptions) (*gorm.DB, error)) (string, error) {
 	query := db.Session(&gorm.Session{DryRun: true}).Model(&Resource{})
@@ -552,3 +663,90 @@ func assertError(t *testing.T, expectedErr string, err error) {
 		t.Errorf("expected nil error, but got: %#v", err)
 	}
 }
+
+func TestApplyGroupByToQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		groupBy  []string
+		expected expected
+	}{
+		{
+			"without group by",
+			nil,
+			expected{
+				`SELECT COUNT(*) FROM "resources"`,
+				"SELECT COUNT(*) FROM `resources`",
+				"",
+			},
+		},
+		{
+			"group by columns",
+			[]string{"cluster", "namespace"},
+			expected{
+				`SELECT "cluster", "namespace", COUNT(*) FROM "resources" GROUP BY 1, 2 ORDER BY 3 DESC,1,2`,
+				"SELECT `cluster`, `namespace`, COUNT(*) FROM `resources` GROUP BY 1, 2 ORDER BY 3 DESC,1,2",
+				"",
+			},
+		},
+		{
+			"group by label and field",
+			[]string{"cluster", "labels.app.kubernetes.io/name", "status.phase"},
+			expected{
+				`SELECT "cluster", "object" -> 'metadata' -> 'labels' ->> 'app.kubernetes.io/name', "object" -> 'status' ->> 'phase', COUNT(*) FROM "resources" GROUP BY 1, 2, 3 ORDER BY 4 DESC,1,2,3`,
+				"SELECT `cluster`, JSON_UNQUOTE(JSON_EXTRACT(`object`,'$.\"metadata\".\"labels\".\"app.kubernetes.io/name\"')), JSON_UNQUOTE(JSON_EXTRACT(`object`,'$.\"status\".\"phase\"')), COUNT(*) FROM `resources` GROUP BY 1, 2, 3 ORDER BY 4 DESC,1,2,3",
+				"",
+			},
+		},
+		{
+			"group by annotation",
+			[]string{"metadata.annotations['test.io/key']"},
+			expected{
+				`SELECT "object" -> 'metadata' -> 'annotations' ->> 'test.io/key', COUNT(*) FROM "resources" GROUP BY 1 ORDER BY 2 DESC,1`,
+				"SELECT JSON_UNQUOTE(JSON_EXTRACT(`object`,'$.\"metadata\".\"annotations\".\"test.io/key\"')), COUNT(*) FROM `resources` GROUP BY 1 ORDER BY 2 DESC,1",
+				"",
+			},
+		},
+		{
+			"group by list field",
+			[]string{"spec.containers[].name"},
+			expected{
+				"",
+				"",
+				`invalid group by "spec.containers[].name": Storage<internal>: Not Support list field`,
+			},
+		},
+		{
+			"group by invalid label",
+			[]string{"labels.-"},
+			expected{
+				"",
+				"",
+				`invalid group by "labels.-": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`,
+			},
+		},
+	}
+
+	applyFn := func(query *gorm.DB, options *internal.ListOptions) (*gorm.DB, error) {
+		return applyGroupByToQuery(query, options.GroupBy)
+	}
+	for _, test := range tests {
+		options := &internal.ListOptions{GroupBy: test.groupBy}
+		t.Run(fmt.Sprintf("%s postgres", test.name), func(t *testing.T) {
+			postgreSQL, err := toSQL(postgresDB, options, applyFn)
+			assertError(t, test.expected.err, err)
+			if postgreSQL != test.expected.postgres {
+				t.Errorf("expected sql: %q, but got: %q", test.expected.postgres, postgreSQL)
+			}
+		})
+
+		for version := range mysqlDBs {
+			t.Run(fmt.Sprintf("%s mysql-%s", test.name, version), func(t *testing.T) {
+				mysqlSQL, err := toSQL(mysqlDBs[version], options, applyFn)
+				assertError(t, test.expected.err, err)
+				if mysqlSQL != test.expected.mysql {
+					t.Errorf("expected sql: %q, but got: %q", test.expected.mysql, mysqlSQL)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package memorystorage
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+)
+
+type CollectionResourceStorage struct {
+	collectionResource *internal.CollectionResource
+}
+
+func NewCollectionResourceStorage(cr *internal.CollectionResource) storage.CollectionResourceStorage {
+	return &CollectionResourceStorage{collectionResource: cr.DeepCopy()}
+}
+
+// matchResourceTypes returns the function to match the resource storages,
+// the resource types of the collection resource are used, otherwise the `groups` and `resources` url queries.
+func (s *CollectionResourceStorage) matchResourceTypes(opts *internal.ListOptions) (func(gvr schema.GroupVersionResource) bool, error) {
+	var gvrs []schema.GroupVersionResource
+	if len(s.collectionResource.ResourceTypes) != 0 {
+		for _, rt := range s.collectionResource.ResourceTypes {
+			gvrs = append(gvrs, schema.GroupVersionResource{Group: rt.Group, Version: rt.Version, Resource: rt.Resource})
+		}
+	} else {
+		// The `URLQueryGroups` and `URLQueryResources` only works on *Any Collection Resource*,
+		// just like the internalstorage.
+		var all bool
+		var err error
+		gvrs, all, err = storage.ResolveGVRsFromURLQuery(opts.URLQuery)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(err.Error())
+		}
+		if all {
+			return func(schema.GroupVersionResource) bool { return true }, nil
+		}
+		if len(gvrs) == 0 {
+			return nil, apierrors.NewBadRequest("url query - `groups` or `resources` is required")
+		}
+	}
+
+	return func(gvr schema.GroupVersionResource) bool {
+		for _, match := range gvrs {
+			if match.Group != gvr.Group {
+				continue
+			}
+			if match.Version != "" && match.Version != gvr.Version {
+				continue
+			}
+			if match.Resource != "" && match.Resource != gvr.Resource {
+				continue
+			}
+			return true
+		}
+		return false
+	}, nil
+}
+
+func (s *CollectionResourceStorage) Get(ctx context.Context, opts *internal.ListOptions) (*internal.CollectionResource, error) {
+	if opts.AsOf != nil {
+		return nil, apierrors.NewBadRequest("as-of is not supported by the collection resources")
+	}
+
+	match, err := s.matchResourceTypes(opts)
+	if err != nil {
+		return nil, err
+	}
+	matchFields, err := newFieldSelectorMatcher(opts.EnhancedFieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceStorages := make(map[schema.GroupVersionResource]*ResourceStorage)
+	storages.RLock()
+	for gvr, rs := range storages.resourceStorages {
+		if match(gvr) {
+			resourceStorages[gvr] = rs
+		}
+	}
+	storages.RUnlock()
+
+	var elems []listElement
+	for gvr, rs := range resourceStorages {
+		storeElems, _, err := rs.watchCache.WaitUntilFreshAndList(opts)
+		if err != nil {
+			return nil, err
+		}
+		filtered, err := filterStoreElements(storeElems, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range filtered {
+			filtered[i].resource = gvr
+		}
+		elems = append(elems, filtered...)
+	}
+	if err := sortListElements(elems, opts.OrderBy); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	decode := func(elem listElement) (*unstructured.Unstructured, error) {
+		buffer.Reset()
+		if err := resourceStorages[elem.resource].Codec.Encode(elem.Object, &buffer); err != nil {
+			return nil, err
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(buffer.Bytes()); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
+	var items []runtime.Object
+	var resources []schema.GroupVersionResource
+	var offset, total int64
+	if matchFields == nil {
+		// only the elements of the page are decoded
+		var end int64
+		total = int64(len(elems))
+		if offset, end, err = pageRange(total, opts); err != nil {
+			return nil, err
+		}
+		for _, elem := range elems[offset:end] {
+			obj, err := decode(elem)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, obj)
+			resources = append(resources, elem.resource)
+		}
+	} else {
+		// the field selector is matched on the decoded objects, so all elements are decoded before paginating
+		objects := make([]runtime.Object, 0, len(elems))
+		objectResources := make([]schema.GroupVersionResource, 0, len(elems))
+		for _, elem := range elems {
+			obj, err := decode(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			matched, err := matchFields(obj)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				objects = append(objects, obj)
+				objectResources = append(objectResources, elem.resource)
+			}
+		}
+
+		total = int64(len(objects))
+		if items, offset, err = paginate(objects, opts); err != nil {
+			return nil, err
+		}
+		resources = objectResources[offset : offset+int64(len(items))]
+	}
+
+	if opts.OnlyMetadata {
+		for i, item := range items {
+			obj := item.(*unstructured.Unstructured)
+			items[i] = &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": obj.GetAPIVersion(),
+					"kind":       obj.GetKind(),
+					"metadata":   obj.Object["metadata"],
+				},
+			}
+		}
+	}
+
+	collection := &internal.CollectionResource{
+		TypeMeta:   s.collectionResource.TypeMeta,
+		ObjectMeta: s.collectionResource.ObjectMeta,
+		Items:      items,
+	}
+
+	gvrs := make(map[schema.GroupVersionResource]struct{})
+	for i, obj := range items {
+		gvr := resources[i]
+		if _, ok := gvrs[gvr]; ok {
+			continue
+		}
+		gvrs[gvr] = struct{}{}
+		collection.ResourceTypes = append(collection.ResourceTypes, internal.CollectionResourceType{
+			Group:    gvr.Group,
+			Version:  gvr.Version,
+			Resource: gvr.Resource,
+			Kind:     obj.GetObjectKind().GroupVersionKind().Kind,
+		})
+	}
+
+	if opts.WithContinue != nil && *opts.WithContinue {
+		if next := offset + int64(len(items)); opts.Limit > 0 && next < total {
+			collection.Continue = strconv.FormatInt(next, 10)
+		}
+	}
+
+	if opts.WithRemainingCount != nil && *opts.WithRemainingCount {
+		remain := total - offset - int64(len(items))
+		collection.RemainingItemCount = &remain
+	}
+	return collection, nil
+}
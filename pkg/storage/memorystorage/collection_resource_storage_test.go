@@ -0,0 +1,177 @@
+package memorystorage
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/fields"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+)
+
+func TestCollectionResourceStorage(t *testing.T) {
+	gvrs := []schema.GroupVersionResource{
+		corev1.SchemeGroupVersion.WithResource("pods"),
+		appsv1.SchemeGroupVersion.WithResource("deployments"),
+	}
+	cleanStorages := func() {
+		storages.Lock()
+		for _, gvr := range gvrs {
+			delete(storages.resourceStorages, gvr)
+		}
+		storages.Unlock()
+	}
+	cleanStorages()
+	defer cleanStorages()
+
+	factory := &StorageFactory{clusters: map[string]bool{"cluster-1": true}}
+	objects := map[schema.GroupVersionResource][]runtime.Object{
+		gvrs[0]: {
+			&corev1.Pod{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-a", ResourceVersion: "1"}},
+		},
+		gvrs[1]: {
+			&appsv1.Deployment{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy-a", ResourceVersion: "2"}},
+			&appsv1.Deployment{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy-b", ResourceVersion: "3"}},
+		},
+	}
+	for _, gvr := range gvrs {
+		config, err := storageconfig.NewStorageConfigFactory().NewConfig(gvr, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs, err := factory.NewResourceStorage(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, obj := range objects[gvr] {
+			if err := rs.Create(context.TODO(), "cluster-1", obj); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	crs, err := factory.GetCollectionResources(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections := make(map[string]*internal.CollectionResource, len(crs))
+	for _, cr := range crs {
+		collections[cr.Name] = cr
+	}
+
+	withContinue := true
+	nameSelector, err := fields.Parse("metadata.name=deploy-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		collection string
+		opts       *internal.ListOptions
+		expected   []string
+		cont       string
+		hasError   bool
+	}{
+		{
+			name:       "workloads",
+			collection: storage.CollectionResourceWorkloads,
+			opts:       &internal.ListOptions{},
+			expected:   []string{"deploy-a", "deploy-b"},
+		},
+		{
+			name:       "any without url query",
+			collection: storage.CollectionResourceAny,
+			opts:       &internal.ListOptions{},
+			hasError:   true,
+		},
+		{
+			name:       "any with resources",
+			collection: storage.CollectionResourceAny,
+			opts:       &internal.ListOptions{URLQuery: url.Values{storage.URLQueryResources: []string{"/pods"}}},
+			expected:   []string{"pod-a"},
+		},
+		{
+			name:       "kuberesources with limit",
+			collection: storage.CollectionResourceKubeResources,
+			opts: &internal.ListOptions{
+				ListOptions:  metainternal.ListOptions{Limit: 2},
+				WithContinue: &withContinue,
+			},
+			expected: []string{"pod-a", "deploy-a"},
+			cont:     "2",
+		},
+		{
+			name:       "workloads with field selector",
+			collection: storage.CollectionResourceWorkloads,
+			opts: &internal.ListOptions{
+				ListOptions:           metainternal.ListOptions{Limit: 1},
+				EnhancedFieldSelector: nameSelector,
+				WithContinue:          &withContinue,
+			},
+			expected: []string{"deploy-b"},
+		},
+		{
+			name:       "kuberesources with continue",
+			collection: storage.CollectionResourceKubeResources,
+			opts: &internal.ListOptions{
+				ListOptions:  metainternal.ListOptions{Limit: 2, Continue: "2"},
+				WithContinue: &withContinue,
+				OnlyMetadata: true,
+			},
+			expected: []string{"deploy-b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			crs, err := factory.NewCollectionResourceStorage(collections[test.collection])
+			if err != nil {
+				t.Fatal(err)
+			}
+			collection, err := crs.Get(context.TODO(), test.opts)
+			if test.hasError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, item := range collection.Items {
+				obj := item.(*unstructured.Unstructured)
+				if test.opts.OnlyMetadata {
+					if _, ok := obj.Object["spec"]; ok {
+						t.Errorf("expected only metadata of %s", obj.GetName())
+					}
+				}
+				names = append(names, obj.GetName())
+			}
+			if len(names) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, names)
+			}
+			for i := range names {
+				if names[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, names)
+				}
+			}
+			if collection.Continue != test.cont {
+				t.Errorf("expected continue %q, got %q", test.cont, collection.Continue)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package memorystorage
+
+import (
+	"time"
+)
+
+const (
+	defaultSnapshotInterval = 5 * time.Minute
+)
+
+type Config struct {
+	Snapshot *SnapshotConfig `yaml:"snapshot"`
+}
+
+// SnapshotConfig enables the snapshots of the memory storage, the resources of each cluster
+// and their resource versions are saved to the directory periodically and restored on start,
+// so that the synchro can resume from the stored resource versions instead of relisting all resources.
+type SnapshotConfig struct {
+	Directory string        `yaml:"directory"`
+	Interval  time.Duration `yaml:"interval" default:"5m"`
+}
+
+func (cfg *Config) getSnapshotConfig() *SnapshotConfig {
+	if cfg.Snapshot == nil || cfg.Snapshot.Directory == "" {
+		return nil
+	}
+
+	snapshot := *cfg.Snapshot
+	if snapshot.Interval <= 0 {
+		snapshot.Interval = defaultSnapshotInterval
+	}
+	return &snapshot
+}
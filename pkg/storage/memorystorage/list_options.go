@@ -0,0 +1,299 @@
+package memorystorage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/fields"
+	cache "github.com/clusterpedia-io/clusterpedia/pkg/storage/memorystorage/watchcache"
+)
+
+type listElement struct {
+	*cache.StoreElement
+	meta metav1.Object
+
+	// resource is only set when listing the collection resources
+	resource schema.GroupVersionResource
+}
+
+// SearchLabelFuzzyName matches the resources whose names contain the values,
+// it is the same label as the fuzzy name of the internalstorage.
+const SearchLabelFuzzyName = "internalstorage.clusterpedia.io/fuzzy-name"
+
+// checkOwnerListOptions rejects the owner options that need to look up the owners in the other resources,
+// the memory storage only matches the resources by the uid of their controller owner.
+func checkOwnerListOptions(opts *internal.ListOptions) error {
+	if opts.OwnerName != "" || !opts.OwnerGroupResource.Empty() {
+		return apierrors.NewBadRequest(fmt.Sprintf("Storage<%s>: Not Support owner name", StorageName))
+	}
+	if opts.OwnerSeniority != 0 {
+		return apierrors.NewBadRequest(fmt.Sprintf("Storage<%s>: Not Support owner seniority", StorageName))
+	}
+	if opts.RootOwnerKind != "" || opts.RootOwnerName != "" {
+		return apierrors.NewBadRequest(fmt.Sprintf("Storage<%s>: Not Support root owner", StorageName))
+	}
+	return nil
+}
+
+// fuzzyNames returns the values of the fuzzy name in the extra label selector.
+func fuzzyNames(opts *internal.ListOptions) []string {
+	if opts.ExtraLabelSelector == nil {
+		return nil
+	}
+	requirements, selectable := opts.ExtraLabelSelector.Requirements()
+	if !selectable {
+		return nil
+	}
+
+	var names []string
+	for _, require := range requirements {
+		if require.Key() != SearchLabelFuzzyName {
+			continue
+		}
+		for _, name := range require.Values().List() {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	return names
+}
+
+// filterStoreElements filters the store elements by the label selector, the owner uid, the fuzzy names
+// and the creation time, the clusters, namespaces and names are already filtered by the watch cache.
+func filterStoreElements(elems []*cache.StoreElement, opts *internal.ListOptions) ([]listElement, error) {
+	if err := checkOwnerListOptions(opts); err != nil {
+		return nil, err
+	}
+	names := fuzzyNames(opts)
+
+	result := make([]listElement, 0, len(elems))
+	for _, elem := range elems {
+		metaobj, err := meta.Accessor(elem.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.LabelSelector != nil && !opts.LabelSelector.Matches(labels.Set(metaobj.GetLabels())) {
+			continue
+		}
+
+		if opts.OwnerUID != "" {
+			if owner := metav1.GetControllerOfNoCopy(metaobj); owner == nil || string(owner.UID) != opts.OwnerUID {
+				continue
+			}
+		}
+
+		if !containsAll(metaobj.GetName(), names) {
+			continue
+		}
+
+		created := metaobj.GetCreationTimestamp()
+		if opts.Since != nil && created.Before(opts.Since) {
+			continue
+		}
+		if opts.Before != nil && !created.Before(opts.Before) {
+			continue
+		}
+		result = append(result, listElement{StoreElement: elem, meta: metaobj})
+	}
+	return result, nil
+}
+
+func containsAll(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if !strings.Contains(s, substr) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortListElements sorts the elements by the order by of the list options,
+// the elements are finally sorted by the resource, cluster, namespace and name to keep the order stable
+// so that the offset continue token can be used.
+func sortListElements(elems []listElement, orderbys []internal.OrderBy) error {
+	compares := make([]func(a, b listElement) int, 0, len(orderbys)+3)
+	for _, orderby := range orderbys {
+		compare, err := orderByCompareFunc(orderby.Field)
+		if err != nil {
+			return err
+		}
+		if orderby.Desc {
+			asc := compare
+			compare = func(a, b listElement) int { return asc(b, a) }
+		}
+		compares = append(compares, compare)
+	}
+	for _, field := range []string{"group", "version", "resource", "cluster", "namespace", "name"} {
+		compare, _ := orderByCompareFunc(field)
+		compares = append(compares, compare)
+	}
+
+	sort.SliceStable(elems, func(i, j int) bool {
+		for _, compare := range compares {
+			if c := compare(elems[i], elems[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+func orderByCompareFunc(field string) (func(a, b listElement) int, error) {
+	switch field {
+	case "group":
+		return func(a, b listElement) int { return strings.Compare(a.resource.Group, b.resource.Group) }, nil
+	case "version":
+		return func(a, b listElement) int { return strings.Compare(a.resource.Version, b.resource.Version) }, nil
+	case "resource":
+		return func(a, b listElement) int { return strings.Compare(a.resource.Resource, b.resource.Resource) }, nil
+	case "cluster":
+		return func(a, b listElement) int { return strings.Compare(a.Cluster, b.Cluster) }, nil
+	case "namespace":
+		return func(a, b listElement) int { return strings.Compare(a.meta.GetNamespace(), b.meta.GetNamespace()) }, nil
+	case "name":
+		return func(a, b listElement) int { return strings.Compare(a.meta.GetName(), b.meta.GetName()) }, nil
+	case "created_at":
+		return func(a, b listElement) int {
+			at, bt := a.meta.GetCreationTimestamp(), b.meta.GetCreationTimestamp()
+			switch {
+			case at.Equal(&bt):
+				return 0
+			case at.Before(&bt):
+				return -1
+			default:
+				return 1
+			}
+		}, nil
+	case "resource_version":
+		return func(a, b listElement) int {
+			arv, _ := strconv.ParseUint(a.meta.GetResourceVersion(), 10, 64)
+			brv, _ := strconv.ParseUint(b.meta.GetResourceVersion(), 10, 64)
+			switch {
+			case arv == brv:
+				return 0
+			case arv < brv:
+				return -1
+			default:
+				return 1
+			}
+		}, nil
+	}
+	return nil, apierrors.NewBadRequest(fmt.Sprintf("Storage<%s>: Not Support order by field %q", StorageName, field))
+}
+
+// newFieldSelectorMatcher returns the function to match the versioned objects with the enhanced field selector,
+// the value of the field is compared as the string just like the internalstorage.
+func newFieldSelectorMatcher(selector fields.Selector) (func(obj runtime.Object) (bool, error), error) {
+	if selector == nil || selector.Empty() {
+		return nil, nil
+	}
+	requirements, selectable := selector.Requirements()
+	if !selectable {
+		return nil, nil
+	}
+
+	paths := make([][]string, 0, len(requirements))
+	var fieldErrors field.ErrorList
+	for _, requirement := range requirements {
+		var path []string
+		for _, f := range requirement.Fields() {
+			if f.IsList() {
+				fieldErrors = append(fieldErrors, field.Invalid(f.Path(), f.Name(), fmt.Sprintf("Storage<%s>: Not Support list field", StorageName)))
+				continue
+			}
+			path = append(path, f.Name())
+		}
+		paths = append(paths, path)
+	}
+	if len(fieldErrors) != 0 {
+		return nil, apierrors.NewInvalid(schema.GroupKind{Group: internal.GroupName, Kind: "ListOptions"}, "fieldSelector", fieldErrors)
+	}
+
+	return func(obj runtime.Object) (bool, error) {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return false, err
+		}
+
+		for i, requirement := range requirements {
+			value, exists, err := unstructured.NestedFieldNoCopy(content, paths[i]...)
+			if err != nil || value == nil {
+				exists = false
+			}
+
+			var str string
+			if exists {
+				if s, ok := value.(string); ok {
+					str = s
+				} else {
+					str = fmt.Sprint(value)
+				}
+			}
+
+			values := requirement.Values()
+			var matched bool
+			switch requirement.Operator() {
+			case selection.Exists:
+				matched = exists
+			case selection.DoesNotExist:
+				matched = !exists
+			case selection.Equals, selection.DoubleEquals, selection.In:
+				matched = exists && values.Has(str)
+			case selection.NotEquals, selection.NotIn:
+				matched = !exists || !values.Has(str)
+			default:
+				continue
+			}
+			if !matched {
+				return false, nil
+			}
+		}
+		return true, nil
+	}, nil
+}
+
+// paginate returns the page of the objects by the limit and the offset continue token,
+// the keyset continue token of the internalstorage is not supported.
+func paginate(objects []runtime.Object, opts *internal.ListOptions) ([]runtime.Object, int64, error) {
+	offset, end, err := pageRange(int64(len(objects)), opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return objects[offset:end], offset, nil
+}
+
+// pageRange returns the range of the page in the total items, so that the items out of the page
+// don't need to be decoded.
+func pageRange(total int64, opts *internal.ListOptions) (int64, int64, error) {
+	var offset int64
+	if opts.Continue != "" {
+		var err error
+		offset, err = strconv.ParseInt(opts.Continue, 10, 64)
+		if err != nil || offset < 0 {
+			return 0, 0, apierrors.NewBadRequest(fmt.Sprintf("Storage<%s>: invalid continue token %q", StorageName, opts.Continue))
+		}
+	}
+
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if opts.Limit > 0 && offset+opts.Limit < total {
+		end = offset + opts.Limit
+	}
+	return offset, end, nil
+}
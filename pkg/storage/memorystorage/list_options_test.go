@@ -0,0 +1,328 @@
+package memorystorage
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/api/clusterpedia/fields"
+	cache "github.com/clusterpedia-io/clusterpedia/pkg/storage/memorystorage/watchcache"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+)
+
+func newTestPod(namespace, name string, podLabels map[string]string, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: podLabels},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func newTestWatchCache(t *testing.T, objects map[string][]runtime.Object) *cache.WatchCache {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	wc := cache.NewWatchCache(10, gvr, true)
+	for cluster, objs := range objects {
+		wc.AddIndexer(cluster, nil)
+		for _, obj := range objs {
+			key, err := wc.KeyFunc(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := wc.GetStores()[cluster].Add(&cache.StoreElement{Cluster: cluster, Key: key, Object: obj}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return wc
+}
+
+func listTestElements(t *testing.T, wc *cache.WatchCache, opts *internal.ListOptions) []string {
+	elems, _, err := wc.WaitUntilFreshAndList(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered, err := filterStoreElements(elems, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sortListElements(filtered, opts.OrderBy); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]string, 0, len(filtered))
+	for _, elem := range filtered {
+		keys = append(keys, elem.Cluster+"/"+elem.meta.GetNamespace()+"/"+elem.meta.GetName())
+	}
+	return keys
+}
+
+func TestListStoreElements(t *testing.T) {
+	controller := true
+	owned := newTestPod("default", "pod-owned", nil, "node-1")
+	owned.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs-1", UID: "rs-uid", Controller: &controller}}
+	notControlled := newTestPod("default", "pod-not-controlled", nil, "node-1")
+	notControlled.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs-1", UID: "rs-uid"}}
+
+	fuzzyName, err := labels.NewRequirement(SearchLabelFuzzyName, selection.In, []string{"own", "pod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wc := newTestWatchCache(t, map[string][]runtime.Object{
+		"cluster-1": {
+			newTestPod("default", "pod-a", map[string]string{"app": "a"}, "node-1"),
+			newTestPod("default", "pod-b", map[string]string{"app": "b"}, "node-2"),
+			newTestPod("kube-system", "pod-a", nil, "node-1"),
+		},
+		"cluster-2": {
+			newTestPod("default", "pod-a", map[string]string{"app": "a"}, "node-1"),
+		},
+		"cluster-4": {owned, notControlled},
+	})
+
+	tests := []struct {
+		name     string
+		opts     *internal.ListOptions
+		expected []string
+	}{
+		{
+			"same names in clusters",
+			&internal.ListOptions{Names: []string{"pod-a"}, Namespaces: []string{"default"}},
+			[]string{"cluster-1/default/pod-a", "cluster-2/default/pod-a"},
+		},
+		{
+			"clusters",
+			&internal.ListOptions{ClusterNames: []string{"cluster-2", "cluster-3"}},
+			[]string{"cluster-2/default/pod-a"},
+		},
+		{
+			"label selector",
+			&internal.ListOptions{ListOptions: metainternal.ListOptions{LabelSelector: labels.SelectorFromSet(labels.Set{"app": "b"})}},
+			[]string{"cluster-1/default/pod-b"},
+		},
+		{
+			"owner uid",
+			&internal.ListOptions{OwnerUID: "rs-uid"},
+			[]string{"cluster-4/default/pod-owned"},
+		},
+		{
+			"fuzzy name",
+			&internal.ListOptions{ExtraLabelSelector: labels.NewSelector().Add(*fuzzyName)},
+			[]string{"cluster-4/default/pod-owned"},
+		},
+		{
+			"order by name desc",
+			&internal.ListOptions{ClusterNames: []string{"cluster-1"}, OrderBy: []internal.OrderBy{{Field: "name", Desc: true}}},
+			[]string{"cluster-1/default/pod-b", "cluster-1/default/pod-a", "cluster-1/kube-system/pod-a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys := listTestElements(t, wc, test.opts)
+			if len(keys) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, keys)
+			}
+			for i := range keys {
+				if keys[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, keys)
+				}
+			}
+		})
+	}
+}
+
+func TestListStoreElementsUnsupportedOwnerOptions(t *testing.T) {
+	wc := newTestWatchCache(t, map[string][]runtime.Object{
+		"cluster-1": {newTestPod("default", "pod-a", nil, "node-1")},
+	})
+	elems, _, err := wc.WaitUntilFreshAndList(&internal.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts *internal.ListOptions
+	}{
+		{"owner name", &internal.ListOptions{OwnerName: "rs-1"}},
+		{"owner group resource", &internal.ListOptions{OwnerName: "rs-1", OwnerGroupResource: schema.GroupResource{Group: "apps", Resource: "replicasets"}}},
+		{"owner seniority", &internal.ListOptions{OwnerUID: "deploy-uid", OwnerSeniority: 1}},
+		{"root owner", &internal.ListOptions{RootOwnerKind: "Deployment", RootOwnerName: "deploy-1"}},
+	}
+	for _, test := range tests {
+		if _, err := filterStoreElements(elems, test.opts); !apierrors.IsBadRequest(err) {
+			t.Errorf("%s: expected the bad request error, got %v", test.name, err)
+		}
+	}
+}
+
+func TestFieldSelectorMatcher(t *testing.T) {
+	selector, err := fields.Parse("spec.nodeName=node-1,metadata.labels.app!=b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, err := newFieldSelectorMatcher(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		pod      *corev1.Pod
+		expected bool
+	}{
+		{newTestPod("default", "pod-a", map[string]string{"app": "a"}, "node-1"), true},
+		{newTestPod("default", "pod-b", map[string]string{"app": "b"}, "node-1"), false},
+		{newTestPod("default", "pod-c", nil, "node-1"), true},
+		{newTestPod("default", "pod-d", nil, "node-2"), false},
+	}
+	for _, test := range tests {
+		matched, err := match(test.pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if matched != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.pod.Name, test.expected, matched)
+		}
+	}
+
+	selector, err = fields.Parse("spec.containers[0].name=nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newFieldSelectorMatcher(selector); err == nil {
+		t.Error("expected the list field is not supported")
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	objects := make([]runtime.Object, 5)
+	for i := range objects {
+		objects[i] = &corev1.Pod{}
+	}
+
+	tests := []struct {
+		limit    int64
+		cont     string
+		offset   int64
+		length   int
+		hasError bool
+	}{
+		{0, "", 0, 5, false},
+		{2, "", 0, 2, false},
+		{2, "4", 4, 1, false},
+		{2, "10", 5, 0, false},
+		{2, "token", 0, 0, true},
+	}
+	for _, test := range tests {
+		opts := &internal.ListOptions{}
+		opts.Limit, opts.Continue = test.limit, test.cont
+		items, offset, err := paginate(objects, opts)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("limit=%d continue=%q: expected error", test.limit, test.cont)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if offset != test.offset || len(items) != test.length {
+			t.Errorf("limit=%d continue=%q: expected offset %d and %d items, got %d and %d",
+				test.limit, test.cont, test.offset, test.length, offset, len(items))
+		}
+	}
+}
+
+func TestResourceStorageListPagination(t *testing.T) {
+	gvr := corev1.SchemeGroupVersion.WithResource("configmaps")
+	cleanStorage := func() {
+		storages.Lock()
+		delete(storages.resourceStorages, gvr)
+		storages.Unlock()
+	}
+	cleanStorage()
+	defer cleanStorage()
+
+	factory := &StorageFactory{clusters: make(map[string]bool)}
+	if err := factory.PrepareCluster("cluster-1"); err != nil {
+		t.Fatal(err)
+	}
+	config, err := storageconfig.NewStorageConfigFactory().NewConfig(gvr, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := factory.NewResourceStorage(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"cm-a", "cm-b", "cm-c", "cm-d"} {
+		cm := &corev1.ConfigMap{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, ResourceVersion: strconv.Itoa(i + 1)}}
+		if i%2 == 0 {
+			cm.Namespace = "kube-system"
+		}
+		if err := rs.Create(context.TODO(), "cluster-1", cm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	selector, err := fields.Parse("metadata.namespace=default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withContinue, withRemainingCount := true, true
+	tests := []struct {
+		name      string
+		cont      string
+		selector  fields.Selector
+		expected  []string
+		next      string
+		remaining int64
+	}{
+		{name: "first page", expected: []string{"cm-b", "cm-d"}, next: "2", remaining: 2},
+		{name: "last page", cont: "2", expected: []string{"cm-a", "cm-c"}, remaining: 0},
+		{name: "field selector", selector: selector, expected: []string{"cm-b"}, next: "1", remaining: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := &internal.ListOptions{
+				ListOptions:           metainternal.ListOptions{Limit: 2, Continue: test.cont},
+				WithContinue:          &withContinue,
+				WithRemainingCount:    &withRemainingCount,
+				EnhancedFieldSelector: test.selector,
+			}
+			if test.selector != nil {
+				opts.Limit = 1
+			}
+
+			list := &corev1.ConfigMapList{}
+			if err := rs.List(context.TODO(), list, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, cm := range list.Items {
+				names = append(names, cm.Name)
+			}
+			if !reflect.DeepEqual(names, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, names)
+			}
+			if list.Continue != test.next {
+				t.Errorf("expected continue %q, got %q", test.next, list.Continue)
+			}
+			if list.RemainingItemCount == nil || *list.RemainingItemCount != test.remaining {
+				t.Errorf("expected remaining %d, got %v", test.remaining, list.RemainingItemCount)
+			}
+		})
+	}
+}
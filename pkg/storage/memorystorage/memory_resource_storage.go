@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 	"sync"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/conversion"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -82,6 +84,20 @@ func (s *ResourceStorage) Update(ctx context.Context, cluster string, obj runtim
 	return nil
 }
 
+func (s *ResourceStorage) Upsert(ctx context.Context, cluster string, obj runtime.Object) error {
+	resourceVersion, err := s.CrvSynchro.UpdateClusterResourceVersion(obj, cluster)
+	if err != nil {
+		return err
+	}
+
+	err = s.watchCache.Upsert(obj, cluster, resourceVersion, s.storageConfig.Codec, s.storageConfig.MemoryVersion)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to add watch event object (%#v) to store: %v", obj, err))
+	}
+
+	return nil
+}
+
 func (s *ResourceStorage) Delete(ctx context.Context, cluster string, obj runtime.Object) error {
 	resourceVersion, err := s.CrvSynchro.UpdateClusterResourceVersion(obj, cluster)
 	if err != nil {
@@ -102,6 +118,9 @@ func (s *ResourceStorage) Get(ctx context.Context, cluster, namespace, name stri
 	if err != nil {
 		return err
 	}
+	if se == nil {
+		return apierrors.NewNotFound(s.storageConfig.GroupResource, name)
+	}
 
 	object := se.Object
 	err = s.Codec.Encode(object, &buffer)
@@ -133,19 +152,30 @@ func (s *ResourceStorage) newClusterWatchEvent(eventType watch.EventType, obj ru
 
 func (s *ResourceStorage) List(ctx context.Context, listObject runtime.Object, opts *internal.ListOptions) error {
 	var buffer bytes.Buffer
-	objects, readResourceVersion, err := s.watchCache.WaitUntilFreshAndList(opts)
+	elems, readResourceVersion, err := s.watchCache.WaitUntilFreshAndList(opts)
 	if err != nil {
 		return err
 	}
 
-	list, err := meta.ListAccessor(listObject)
+	matchFields, err := newFieldSelectorMatcher(opts.EnhancedFieldSelector)
+	if err != nil {
+		return err
+	}
+
+	filtered, err := filterStoreElements(elems, opts)
 	if err != nil {
 		return err
 	}
-	if len(objects) == 0 {
-		return nil
+	if err := sortListElements(filtered, opts.OrderBy); err != nil {
+		return err
 	}
 
+	list, err := meta.ListAccessor(listObject)
+	if err != nil {
+		return err
+	}
+	list.SetResourceVersion(readResourceVersion.GetClusterResourceVersion())
+
 	listPtr, err := meta.GetItemsPtr(listObject)
 	if err != nil {
 		return err
@@ -157,39 +187,71 @@ func (s *ResourceStorage) List(ctx context.Context, listObject runtime.Object, o
 	}
 
 	expected := reflect.New(v.Type().Elem()).Interface().(runtime.Object)
-	seen := map[string]struct{}{}
-	accessor := meta.NewAccessor()
-	deduplicated := make([]runtime.Object, 0, len(objects))
-	for _, object := range objects {
+	decode := func(elem listElement) (runtime.Object, error) {
 		buffer.Reset()
-		obj := object.Object
-		err = s.Codec.Encode(obj, &buffer)
-		if err != nil {
-			return err
+		if err := s.Codec.Encode(elem.Object, &buffer); err != nil {
+			return nil, err
 		}
-
 		obj, _, err := s.Codec.Decode(buffer.Bytes(), nil, expected.DeepCopyObject())
-		if err != nil {
+		return obj, err
+	}
+
+	var items []runtime.Object
+	var offset, total int64
+	if matchFields == nil {
+		// only the elements of the page are decoded
+		var end int64
+		total = int64(len(filtered))
+		if offset, end, err = pageRange(total, opts); err != nil {
 			return err
 		}
+		items = make([]runtime.Object, 0, end-offset)
+		for _, elem := range filtered[offset:end] {
+			obj, err := decode(elem)
+			if err != nil {
+				return err
+			}
+			items = append(items, obj)
+		}
+	} else {
+		// the field selector is matched on the decoded objects, so all elements are decoded before paginating
+		objects := make([]runtime.Object, 0, len(filtered))
+		for _, elem := range filtered {
+			obj, err := decode(elem)
+			if err != nil {
+				return err
+			}
+
+			matched, err := matchFields(obj)
+			if err != nil {
+				return err
+			}
+			if matched {
+				objects = append(objects, obj)
+			}
+		}
 
-		name, err := accessor.Name(obj)
-		if err != nil {
+		total = int64(len(objects))
+		if items, offset, err = paginate(objects, opts); err != nil {
 			return err
 		}
+	}
 
-		if _, ok := seen[name]; !ok {
-			seen[name] = struct{}{}
-			deduplicated = append(deduplicated, obj)
+	if opts.WithContinue != nil && *opts.WithContinue {
+		if next := offset + int64(len(items)); opts.Limit > 0 && next < total {
+			list.SetContinue(strconv.FormatInt(next, 10))
 		}
 	}
 
-	slice := reflect.MakeSlice(v.Type(), len(deduplicated), len(deduplicated))
-	for i, obj := range deduplicated {
-		slice.Index(i).Set(reflect.ValueOf(obj).Elem())
+	if opts.WithRemainingCount != nil && *opts.WithRemainingCount {
+		remain := total - offset - int64(len(items))
+		list.SetRemainingItemCount(&remain)
 	}
 
-	list.SetResourceVersion(readResourceVersion.GetClusterResourceVersion())
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, obj := range items {
+		slice.Index(i).Set(reflect.ValueOf(obj).Elem())
+	}
 	v.Set(slice)
 	return nil
 }
@@ -2,8 +2,10 @@ package memorystorage
 
 import (
 	"context"
+	"fmt"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
 
 	internal "github.com/clusterpedia-io/api/clusterpedia"
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
@@ -12,6 +14,9 @@ import (
 
 type StorageFactory struct {
 	clusters map[string]bool
+
+	// snapshotter is nil if the snapshots are disabled
+	snapshotter *snapshotter
 }
 
 func (s *StorageFactory) GetSupportedRequestVerbs() []string {
@@ -45,6 +50,15 @@ func (s *StorageFactory) NewResourceStorage(config *storage.ResourceStorageConfi
 		}
 
 		storages.resourceStorages[gvr] = resourceStorage
+
+		if s.snapshotter != nil {
+			if snapshot := s.snapshotter.takePending(gvr); snapshot != nil {
+				if err := resourceStorage.restore(snapshot); err != nil {
+					// the resources not restored are relisted by the synchro
+					klog.ErrorS(err, "Failed to restore memory storage from the snapshot", "resource", gvr)
+				}
+			}
+		}
 	}
 
 	for cluster := range s.clusters {
@@ -73,11 +87,32 @@ func (s *StorageFactory) PrepareCluster(cluster string) error {
 }
 
 func (s *StorageFactory) NewCollectionResourceStorage(cr *internal.CollectionResource) (storage.CollectionResourceStorage, error) {
-	return nil, nil
+	// The custom collection resource must specify the resource types,
+	// otherwise it will match all resources just like the *any* collection resource.
+	if !storage.IsBuiltInCollectionResource(cr.Name) && len(cr.ResourceTypes) == 0 {
+		return nil, fmt.Errorf("not support collection resource: %s, resource types are required", cr.Name)
+	}
+	return NewCollectionResourceStorage(cr), nil
 }
 
 func (s *StorageFactory) GetResourceVersions(ctx context.Context, cluster string) (map[schema.GroupVersionResource]map[string]interface{}, error) {
-	return nil, nil
+	resourceversions := make(map[schema.GroupVersionResource]map[string]interface{})
+	if s.snapshotter != nil {
+		resourceversions = s.snapshotter.pendingResourceVersions(cluster)
+	}
+
+	storages.RLock()
+	defer storages.RUnlock()
+	for gvr, rs := range storages.resourceStorages {
+		versions, err := rs.resourceVersions(cluster)
+		if err != nil {
+			return nil, err
+		}
+		if versions != nil {
+			resourceversions[gvr] = versions
+		}
+	}
+	return resourceversions, nil
 }
 
 func (s *StorageFactory) CleanCluster(ctx context.Context, cluster string) error {
@@ -88,6 +123,9 @@ func (s *StorageFactory) CleanCluster(ctx context.Context, cluster string) error
 		rs.watchCache.CleanCluster(cluster)
 		delete(s.clusters, cluster)
 	}
+	if s.snapshotter != nil {
+		s.snapshotter.cleanPendingCluster(cluster, nil)
+	}
 
 	return nil
 }
@@ -100,10 +138,13 @@ func (s *StorageFactory) CleanClusterResource(ctx context.Context, cluster strin
 		rs.watchCache.CleanCluster(cluster)
 		delete(s.clusters, cluster)
 	}
+	if s.snapshotter != nil {
+		s.snapshotter.cleanPendingCluster(cluster, &gvr)
+	}
 
 	return nil
 }
 
 func (s *StorageFactory) GetCollectionResources(ctx context.Context) ([]*internal.CollectionResource, error) {
-	return nil, nil
+	return storage.BuiltInCollectionResources(), nil
 }
@@ -1,6 +1,9 @@
 package memorystorage
 
 import (
+	"github.com/jinzhu/configor"
+	"k8s.io/apimachinery/pkg/util/wait"
+
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
 )
 
@@ -12,9 +15,25 @@ func init() {
 	storage.RegisterStorageFactoryFunc(StorageName, NewStorageFactory)
 }
 
-func NewStorageFactory(_ string) (storage.StorageFactory, error) {
+func NewStorageFactory(configPath string) (storage.StorageFactory, error) {
+	cfg := &Config{}
+	if configPath != "" {
+		if err := configor.Load(cfg, configPath); err != nil {
+			return nil, err
+		}
+	}
+
 	storageFactory := &StorageFactory{
 		clusters: make(map[string]bool),
 	}
+	if snapshot := cfg.getSnapshotConfig(); snapshot != nil {
+		snapshotter, err := newSnapshotter(snapshot.Directory)
+		if err != nil {
+			return nil, err
+		}
+		storageFactory.snapshotter = snapshotter
+
+		go wait.Until(snapshotter.save, snapshot.Interval, wait.NeverStop)
+	}
 	return storageFactory, nil
 }
@@ -0,0 +1,353 @@
+package memorystorage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/scheme"
+	cache "github.com/clusterpedia-io/clusterpedia/pkg/storage/memorystorage/watchcache"
+)
+
+// resourceSnapshot is the snapshot of the resource storage saved in the snapshot directory.
+type resourceSnapshot struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+
+	// ResourceVersion is the encoded ClusterResourceVersion up to which the objects are propagated.
+	ResourceVersion string                      `json:"resourceVersion"`
+	Clusters        map[string]*clusterSnapshot `json:"clusters"`
+}
+
+type clusterSnapshot struct {
+	// ResourceVersions are the resource versions of the objects in the member cluster,
+	// the keys are the `namespace/name` of the objects just like the keys of the synchro.
+	ResourceVersions map[string]string `json:"resourceVersions"`
+
+	// Objects are encoded in the storage version.
+	Objects []json.RawMessage `json:"objects"`
+}
+
+func (rs *resourceSnapshot) groupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: rs.Group, Version: rs.Version, Resource: rs.Resource}
+}
+
+func snapshotFileName(gvr schema.GroupVersionResource) string {
+	name := gvr.Resource + "." + gvr.Version
+	if gvr.Group != "" {
+		name += "." + gvr.Group
+	}
+	return name + ".json"
+}
+
+// snapshotter saves the snapshots of the resource storages periodically,
+// and keeps the snapshots loaded on start until the resource storages are created and restored from them.
+type snapshotter struct {
+	sync.Mutex
+
+	directory string
+
+	// pending are the loaded snapshots which are not restored to the resource storages yet,
+	// dirty records the pending snapshots changed by cleaning clusters.
+	pending map[schema.GroupVersionResource]*resourceSnapshot
+	dirty   map[schema.GroupVersionResource]bool
+
+	// written records the resource version of the last snapshot written for each resource storage.
+	written map[schema.GroupVersionResource]string
+}
+
+func newSnapshotter(directory string) (*snapshotter, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &snapshotter{
+		directory: directory,
+		pending:   make(map[schema.GroupVersionResource]*resourceSnapshot),
+		dirty:     make(map[schema.GroupVersionResource]bool),
+		written:   make(map[schema.GroupVersionResource]string),
+	}
+
+	files, err := filepath.Glob(filepath.Join(directory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			klog.ErrorS(err, "Failed to read the snapshot of memory storage", "file", file)
+			continue
+		}
+
+		snapshot := &resourceSnapshot{}
+		if err := json.Unmarshal(data, snapshot); err != nil {
+			// the resources are relisted from the clusters if the snapshot is broken
+			klog.ErrorS(err, "Failed to decode the snapshot of memory storage", "file", file)
+			continue
+		}
+		gvr := snapshot.groupVersionResource()
+		s.pending[gvr] = snapshot
+		s.written[gvr] = snapshot.ResourceVersion
+	}
+	return s, nil
+}
+
+// takePending returns and removes the pending snapshot of the resource.
+func (s *snapshotter) takePending(gvr schema.GroupVersionResource) *resourceSnapshot {
+	s.Lock()
+	defer s.Unlock()
+
+	snapshot := s.pending[gvr]
+	delete(s.pending, gvr)
+	delete(s.dirty, gvr)
+	return snapshot
+}
+
+// pendingResourceVersions returns the resource versions of the cluster in the pending snapshots.
+func (s *snapshotter) pendingResourceVersions(cluster string) map[schema.GroupVersionResource]map[string]interface{} {
+	s.Lock()
+	defer s.Unlock()
+
+	resourceversions := make(map[schema.GroupVersionResource]map[string]interface{})
+	for gvr, snapshot := range s.pending {
+		cs, ok := snapshot.Clusters[cluster]
+		if !ok {
+			continue
+		}
+
+		versions := make(map[string]interface{}, len(cs.ResourceVersions))
+		for key, rv := range cs.ResourceVersions {
+			versions[key] = rv
+		}
+		resourceversions[gvr] = versions
+	}
+	return resourceversions
+}
+
+// cleanPendingCluster removes the cluster from the pending snapshots, or only from the snapshot of gvr if it is not nil.
+func (s *snapshotter) cleanPendingCluster(cluster string, gvr *schema.GroupVersionResource) {
+	s.Lock()
+	defer s.Unlock()
+
+	for pendingGVR, snapshot := range s.pending {
+		if gvr != nil && *gvr != pendingGVR {
+			continue
+		}
+		if _, ok := snapshot.Clusters[cluster]; ok {
+			delete(snapshot.Clusters, cluster)
+			s.dirty[pendingGVR] = true
+		}
+	}
+}
+
+// save writes the snapshots of the resource storages changed since the last snapshots,
+// and the pending snapshots changed by cleaning clusters.
+func (s *snapshotter) save() {
+	storages.RLock()
+	resourceStorages := make(map[schema.GroupVersionResource]*ResourceStorage, len(storages.resourceStorages))
+	for gvr, rs := range storages.resourceStorages {
+		resourceStorages[gvr] = rs
+	}
+	storages.RUnlock()
+
+	for gvr, rs := range resourceStorages {
+		snapshot, err := rs.snapshot(gvr)
+		if err != nil {
+			klog.ErrorS(err, "Failed to snapshot memory storage", "resource", gvr)
+			continue
+		}
+
+		s.Lock()
+		written := s.written[gvr]
+		s.Unlock()
+		if written == snapshot.ResourceVersion {
+			continue
+		}
+
+		if err := s.write(gvr, snapshot); err != nil {
+			klog.ErrorS(err, "Failed to save the snapshot of memory storage", "resource", gvr)
+			continue
+		}
+		s.Lock()
+		s.written[gvr] = snapshot.ResourceVersion
+		s.Unlock()
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for gvr := range s.dirty {
+		snapshot, ok := s.pending[gvr]
+		if !ok {
+			delete(s.dirty, gvr)
+			continue
+		}
+
+		var err error
+		if len(snapshot.Clusters) == 0 {
+			err = os.Remove(filepath.Join(s.directory, snapshotFileName(gvr)))
+			if os.IsNotExist(err) {
+				err = nil
+			}
+			delete(s.pending, gvr)
+		} else {
+			err = s.write(gvr, snapshot)
+		}
+		if err != nil {
+			klog.ErrorS(err, "Failed to save the snapshot of memory storage", "resource", gvr)
+			continue
+		}
+		delete(s.dirty, gvr)
+	}
+}
+
+// write replaces the snapshot file by renaming, so that a broken file is never left when the process exits.
+func (s *snapshotter) write(gvr schema.GroupVersionResource, snapshot *resourceSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(s.directory, snapshotFileName(gvr))
+	if err := os.WriteFile(file+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// snapshot encodes the objects of the resource storage in the storage version.
+func (s *ResourceStorage) snapshot(gvr schema.GroupVersionResource) (*resourceSnapshot, error) {
+	elems, crv, err := s.watchCache.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &resourceSnapshot{
+		Group:           gvr.Group,
+		Version:         gvr.Version,
+		Resource:        gvr.Resource,
+		ResourceVersion: crv.GetClusterResourceVersion(),
+		Clusters:        make(map[string]*clusterSnapshot, len(elems)),
+	}
+
+	var buffer bytes.Buffer
+	for cluster, clusterElems := range elems {
+		cs := &clusterSnapshot{
+			ResourceVersions: make(map[string]string, len(clusterElems)),
+			Objects:          make([]json.RawMessage, 0, len(clusterElems)),
+		}
+		for _, elem := range clusterElems {
+			key, rv, err := clusterObjectResourceVersion(cluster, elem.Object)
+			if err != nil {
+				return nil, err
+			}
+			cs.ResourceVersions[key] = rv
+
+			buffer.Reset()
+			if err := s.Codec.Encode(elem.Object, &buffer); err != nil {
+				return nil, err
+			}
+			cs.Objects = append(cs.Objects, append(json.RawMessage(nil), bytes.TrimSpace(buffer.Bytes())...))
+		}
+		snapshot.Clusters[cluster] = cs
+	}
+	return snapshot, nil
+}
+
+// restore adds the objects in the snapshot to the new resource storage,
+// the resource versions of the clusters are restored so that the watch cache continues from them.
+func (s *ResourceStorage) restore(snapshot *resourceSnapshot) error {
+	crv, err := cache.NewClusterResourceVersionFromString(snapshot.ResourceVersion)
+	if err != nil {
+		return err
+	}
+
+	gvr := snapshot.groupVersionResource()
+	for cluster, cs := range snapshot.Clusters {
+		objs := make([]runtime.Object, 0, len(cs.Objects))
+		for _, data := range cs.Objects {
+			obj, err := decodeSnapshotObject(gvr, data)
+			if err != nil {
+				return fmt.Errorf("failed to decode the object of cluster %s: %w", cluster, err)
+			}
+			objs = append(objs, obj)
+		}
+
+		if err := s.watchCache.Restore(cluster, objs, crv, s.storageConfig.Codec, s.storageConfig.MemoryVersion); err != nil {
+			return err
+		}
+	}
+	s.CrvSynchro = cache.NewClusterResourceVersionSynchroFrom(crv)
+	return nil
+}
+
+// resourceVersions returns the resource versions of the objects in the cluster.
+func (s *ResourceStorage) resourceVersions(cluster string) (map[string]interface{}, error) {
+	elems, _, err := s.watchCache.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterElems, ok := elems[cluster]
+	if !ok {
+		return nil, nil
+	}
+
+	versions := make(map[string]interface{}, len(clusterElems))
+	for _, elem := range clusterElems {
+		key, rv, err := clusterObjectResourceVersion(cluster, elem.Object)
+		if err != nil {
+			return nil, err
+		}
+		versions[key] = rv
+	}
+	return versions, nil
+}
+
+// clusterObjectResourceVersion returns the key and the resource version in the member cluster of the stored object,
+// the resource version of the stored object is the encoded ClusterResourceVersion when the object is written.
+func clusterObjectResourceVersion(cluster string, obj runtime.Object) (string, string, error) {
+	metaobj, err := meta.Accessor(obj)
+	if err != nil {
+		return "", "", err
+	}
+
+	key := metaobj.GetName()
+	if namespace := metaobj.GetNamespace(); namespace != "" {
+		key = namespace + "/" + key
+	}
+
+	rv := metaobj.GetResourceVersion()
+	if crv, err := cache.NewClusterResourceVersionFromString(rv); err == nil {
+		rv = crv.GetResourceVersion(cluster)
+	}
+	return key, rv, nil
+}
+
+// decodeSnapshotObject decodes the object in the storage version without conversion,
+// just like the objects written by the synchro.
+func decodeSnapshotObject(gvr schema.GroupVersionResource, data []byte) (runtime.Object, error) {
+	if scheme.LegacyResourceScheme.IsGroupRegistered(gvr.Group) {
+		obj, gvk, err := scheme.LegacyResourceCodecs.UniversalDeserializer().Decode(data, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(*gvk)
+		return obj, nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
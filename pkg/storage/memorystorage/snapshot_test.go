@@ -0,0 +1,98 @@
+package memorystorage
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	internal "github.com/clusterpedia-io/api/clusterpedia"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/storageconfig"
+)
+
+func newTestSnapshotStorage(t *testing.T, directory string) (*StorageFactory, func() storage.ResourceStorage) {
+	snapshotter, err := newSnapshotter(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := &StorageFactory{clusters: make(map[string]bool), snapshotter: snapshotter}
+	for _, cluster := range []string{"cluster-1", "cluster-2"} {
+		if err := factory.PrepareCluster(cluster); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return factory, func() storage.ResourceStorage {
+		config, err := storageconfig.NewStorageConfigFactory().NewLegacyResourceConfig(schema.GroupResource{Resource: "pods"}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs, err := factory.NewResourceStorage(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rs
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	gvr := corev1.SchemeGroupVersion.WithResource("pods")
+	storages.Lock()
+	delete(storages.resourceStorages, gvr)
+	storages.Unlock()
+	defer func() {
+		storages.Lock()
+		delete(storages.resourceStorages, gvr)
+		storages.Unlock()
+	}()
+
+	directory := t.TempDir()
+	factory, newStorage := newTestSnapshotStorage(t, directory)
+	rs := newStorage()
+	for _, cluster := range []string{"cluster-1", "cluster-2"} {
+		for _, pod := range []*corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-a", ResourceVersion: "10"}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-b", ResourceVersion: "11"}},
+		} {
+			pod.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+			if err := rs.Create(context.TODO(), cluster, pod); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	factory.snapshotter.save()
+
+	// restart with the snapshot
+	storages.Lock()
+	delete(storages.resourceStorages, gvr)
+	storages.Unlock()
+	factory, newStorage = newTestSnapshotStorage(t, directory)
+
+	rvs, err := factory.GetResourceVersions(context.TODO(), "cluster-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv := rvs[gvr]["default/pod-b"]; rv != "11" {
+		t.Errorf("expected the resource version of pod-b from the snapshot is 11, got %v", rv)
+	}
+
+	rs = newStorage()
+	list := &corev1.PodList{}
+	if err := rs.List(context.TODO(), list, &internal.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 4 {
+		t.Fatalf("expected 4 restored pods, got %d", len(list.Items))
+	}
+
+	rvs, err = factory.GetResourceVersions(context.TODO(), "cluster-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rv := rvs[gvr]["default/pod-a"]; rv != "10" {
+		t.Errorf("expected the resource version of pod-a from the restored storage is 10, got %v", rv)
+	}
+}
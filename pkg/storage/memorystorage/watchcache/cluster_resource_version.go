@@ -63,6 +63,11 @@ func GetClusterResourceVersionFromEvent(event *watch.Event) (*ClusterResourceVer
 	return NewClusterResourceVersionFromString(accessor.GetResourceVersion())
 }
 
+// GetResourceVersion returns the resourceVersion of the cluster
+func (crv *ClusterResourceVersion) GetResourceVersion(cluster string) string {
+	return crv.rvmap[cluster]
+}
+
 func (crv *ClusterResourceVersion) IsEqual(another *ClusterResourceVersion) bool {
 	if len(crv.rvmap) != len(another.rvmap) {
 		return false
@@ -91,6 +96,18 @@ func NewClusterResourceVersionSynchro(cluster string) *ClusterResourceVersionSyn
 	}
 }
 
+// NewClusterResourceVersionSynchroFrom returns a ClusterResourceVersionSynchro starting from crv,
+// such as the ClusterResourceVersion restored from the snapshot.
+func NewClusterResourceVersionSynchroFrom(crv *ClusterResourceVersion) *ClusterResourceVersionSynchro {
+	rvmap := make(map[string]string, len(crv.rvmap))
+	for cluster, rv := range crv.rvmap {
+		rvmap[cluster] = rv
+	}
+	return &ClusterResourceVersionSynchro{
+		crv: &ClusterResourceVersion{rvmap: rvmap},
+	}
+}
+
 // UpdateClusterResourceVersion update the resourceVersion in ClusterResourceVersionSynchro to the latest
 func (crvs *ClusterResourceVersionSynchro) UpdateClusterResourceVersion(obj runtime.Object, cluster string) (*ClusterResourceVersion, error) {
 	crvs.Lock()
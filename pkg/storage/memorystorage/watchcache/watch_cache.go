@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -12,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/watch"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/generic/registry"
@@ -23,12 +25,21 @@ import (
 	utilwatch "github.com/clusterpedia-io/clusterpedia/pkg/utils/watch"
 )
 
+const (
+	// NamespaceIndex and NameIndex are the indexes added to the store of each cluster,
+	// they are used to prefilter the objects when listing.
+	NamespaceIndex = cache.NamespaceIndex
+	NameIndex      = "name"
+)
+
 // StoreElement keeping the structs of resource in k8s(key, object, labels, fields).
+// The key is unique in the store of the cluster, so the cluster and key identify the object.
 type StoreElement struct {
-	Key    string
-	Object runtime.Object
-	Labels labels.Set
-	Fields fields.Set
+	Cluster string
+	Key     string
+	Object  runtime.Object
+	Labels  labels.Set
+	Fields  fields.Set
 }
 
 // WatchCache implements a Store interface.
@@ -121,16 +132,27 @@ func storeElementKey(obj interface{}) (string, error) {
 }
 
 func storeElementIndexers(indexers *cache.Indexers) cache.Indexers {
+	ret := cache.Indexers{
+		NamespaceIndex: storeElementIndexFunc(cache.MetaNamespaceIndexFunc),
+		NameIndex:      storeElementIndexFunc(metaNameIndexFunc),
+	}
 	if indexers == nil {
-		return cache.Indexers{}
+		return ret
 	}
-	ret := cache.Indexers{}
 	for indexName, indexFunc := range *indexers {
 		ret[indexName] = storeElementIndexFunc(indexFunc)
 	}
 	return ret
 }
 
+func metaNameIndexFunc(obj interface{}) ([]string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return []string{""}, fmt.Errorf("object has no meta: %v", err)
+	}
+	return []string{accessor.GetName()}, nil
+}
+
 func storeElementIndexFunc(objIndexFunc cache.IndexFunc) cache.IndexFunc {
 	return func(obj interface{}) (strings []string, e error) {
 		seo, err := storeElementObject(obj)
@@ -168,12 +190,12 @@ func (w *WatchCache) GetStores() map[string]cache.Indexer {
 	return w.stores
 }
 
-func (w *WatchCache) processEvent(event watch.Event, resourceVersion *ClusterResourceVersion, updateFunc func(*StoreElement) error) error {
+func (w *WatchCache) processEvent(event watch.Event, clusterName string, resourceVersion *ClusterResourceVersion, updateFunc func(*StoreElement) error) error {
 	key, err := w.KeyFunc(event.Object)
 	if err != nil {
 		return fmt.Errorf("couldn't compute key: %v", err)
 	}
-	elem := &StoreElement{Key: key, Object: event.Object}
+	elem := &StoreElement{Cluster: clusterName, Key: key, Object: event.Object}
 	if w.getAttrsFunc != nil {
 		elem.Labels, elem.Fields, err = w.getAttrsFunc(event.Object)
 		if err != nil {
@@ -213,7 +235,7 @@ func (w *WatchCache) Add(obj runtime.Object, clusterName string, resourceVersion
 	}
 
 	event := watch.Event{Type: watch.Added, Object: object}
-	err = w.processEvent(event, resourceVersion, f)
+	err = w.processEvent(event, clusterName, resourceVersion, f)
 	if err != nil {
 		return err
 	}
@@ -234,7 +256,7 @@ func (w *WatchCache) Update(obj runtime.Object, clusterName string, resourceVers
 	}
 
 	event := watch.Event{Type: watch.Modified, Object: object}
-	err = w.processEvent(event, resourceVersion, f)
+	err = w.processEvent(event, clusterName, resourceVersion, f)
 	if err != nil {
 		return err
 	}
@@ -243,6 +265,27 @@ func (w *WatchCache) Update(obj runtime.Object, clusterName string, resourceVers
 	return nil
 }
 
+// Upsert adds the object if it doesn't exist in the store of the cluster, otherwise updates it.
+func (w *WatchCache) Upsert(obj runtime.Object, clusterName string, resourceVersion *ClusterResourceVersion,
+	codec runtime.Codec, memoryVersion schema.GroupVersion) error {
+	key, err := w.KeyFunc(obj)
+	if err != nil {
+		return fmt.Errorf("couldn't compute key: %v", err)
+	}
+
+	w.RLock()
+	_, exists, err := w.stores[clusterName].GetByKey(key)
+	w.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return w.Update(obj, clusterName, resourceVersion, codec, memoryVersion)
+	}
+	return w.Add(obj, clusterName, resourceVersion, codec, memoryVersion)
+}
+
 // Delete takes runtime.Object as an argument.
 func (w *WatchCache) Delete(obj runtime.Object, clusterName string, resourceVersion *ClusterResourceVersion,
 	codec runtime.Codec, memoryVersion schema.GroupVersion) error {
@@ -255,7 +298,7 @@ func (w *WatchCache) Delete(obj runtime.Object, clusterName string, resourceVers
 	}
 
 	event := watch.Event{Type: watch.Deleted, Object: object}
-	err = w.processEvent(event, resourceVersion, f)
+	err = w.processEvent(event, clusterName, resourceVersion, f)
 	if err != nil {
 		return err
 	}
@@ -274,36 +317,48 @@ func (w *WatchCache) updateCache(event *watch.Event) {
 	w.endIndex++
 }
 
-// WaitUntilFreshAndList returns list of pointers to <storeElement> objects.
+// WaitUntilFreshAndList returns list of pointers to <storeElement> objects in the requested clusters,
+// the objects are prefiltered by the names and namespaces with the indexes of the stores.
 func (w *WatchCache) WaitUntilFreshAndList(opts *internal.ListOptions) ([]*StoreElement, *ClusterResourceVersion, error) {
 	w.RLock()
 	defer w.RUnlock()
 
-	/*	// This isn't the place where we do "final filtering" - only some "prefiltering" is happening here. So the only
-		// requirement here is to NOT miss anything that should be returned. We can return as many non-matching items as we
-		// want - they will be filtered out later. The fact that we return less things is only further performance improvement.
-		// TODO: if multiple indexes match, return the one with the fewest items, so as to do as much filtering as possible.
-		for _, matchValue := range matchValues {
-			if result, err := w.store.ByIndex(matchValue.IndexName, matchValue.Value); err == nil {
-				return result, w.resourceVersion, nil
-			}
-		}*/
+	clusters := opts.ClusterNames
+	if len(clusters) == 0 {
+		clusters = make([]string, 0, len(w.stores))
+		for cluster := range w.stores {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters)
+
 	var result []*StoreElement
-	accessor := meta.NewAccessor()
-	for _, store := range w.stores {
-		for _, obj := range store.List() {
-			se := obj.(*StoreElement)
-			ns, err := accessor.Namespace(se.Object)
+	for _, cluster := range clusters {
+		store, ok := w.stores[cluster]
+		if !ok {
+			continue
+		}
+
+		objs, err := listByIndexes(store, opts)
+		if err != nil {
+			return nil, w.resourceVersion, err
+		}
+		for _, obj := range objs {
+			se, ok := obj.(*StoreElement)
+			if !ok {
+				return nil, w.resourceVersion, fmt.Errorf("not a storeElement: %v", obj)
+			}
+
+			// the objects listed by one index still need to be filtered by the other
+			accessor, err := meta.Accessor(se.Object)
 			if err != nil {
-				return result, w.resourceVersion, err
+				return nil, w.resourceVersion, err
 			}
-			if opts.Namespaces != nil {
-				if slices.Contains(opts.Namespaces, ns) {
-					result = append(result, se)
-					continue
-				} else {
-					continue
-				}
+			if len(opts.Namespaces) != 0 && !slices.Contains(opts.Namespaces, accessor.GetNamespace()) {
+				continue
+			}
+			if len(opts.Names) != 0 && !slices.Contains(opts.Names, accessor.GetName()) {
+				continue
 			}
 			result = append(result, se)
 		}
@@ -311,6 +366,28 @@ func (w *WatchCache) WaitUntilFreshAndList(opts *internal.ListOptions) ([]*Store
 	return result, w.resourceVersion, nil
 }
 
+// listByIndexes lists the objects of the store by the name index or the namespace index,
+// usually the names are more selective than the namespaces.
+func listByIndexes(store cache.Indexer, opts *internal.ListOptions) ([]interface{}, error) {
+	indexName, values := NameIndex, opts.Names
+	if len(values) == 0 {
+		indexName, values = NamespaceIndex, opts.Namespaces
+	}
+	if len(values) == 0 {
+		return store.List(), nil
+	}
+
+	var objs []interface{}
+	for _, value := range sets.NewString(values...).List() {
+		indexed, err := store.ByIndex(indexName, value)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, indexed...)
+	}
+	return objs, nil
+}
+
 // WaitUntilFreshAndGet returns list of pointers to <storeElement> objects.
 func (w *WatchCache) WaitUntilFreshAndGet(cluster, namespace, name string) (*StoreElement, error) {
 	w.RLock()
@@ -404,6 +481,69 @@ func (w *WatchCache) GetAllEventsSinceThreadUnsafe(resourceVersion *ClusterResou
 	return result, nil
 }
 
+// Snapshot returns the objects in the store of each cluster and the resourceVersion up to which the objects are propagated,
+// the resourceVersion only contains the clusters in the stores.
+func (w *WatchCache) Snapshot() (map[string][]*StoreElement, *ClusterResourceVersion, error) {
+	w.RLock()
+	defer w.RUnlock()
+
+	elems := make(map[string][]*StoreElement, len(w.stores))
+	crv := &ClusterResourceVersion{rvmap: make(map[string]string, len(w.stores))}
+	for cluster, store := range w.stores {
+		objs := store.List()
+		clusterElems := make([]*StoreElement, 0, len(objs))
+		for _, obj := range objs {
+			se, ok := obj.(*StoreElement)
+			if !ok {
+				return nil, nil, fmt.Errorf("not a storeElement: %v", obj)
+			}
+			clusterElems = append(clusterElems, se)
+		}
+		elems[cluster] = clusterElems
+
+		if w.resourceVersion != nil {
+			if rv, ok := w.resourceVersion.rvmap[cluster]; ok {
+				crv.rvmap[cluster] = rv
+			}
+		}
+	}
+	return elems, crv, nil
+}
+
+// Restore adds the objects restored from the snapshot to the store of the cluster without dispatching the events,
+// and the resourceVersion of the watch cache is set to the resourceVersion of the snapshot.
+func (w *WatchCache) Restore(clusterName string, objs []runtime.Object, resourceVersion *ClusterResourceVersion,
+	codec runtime.Codec, memoryVersion schema.GroupVersion) error {
+	elems := make([]*StoreElement, 0, len(objs))
+	for _, obj := range objs {
+		object, err := encodeEvent(obj, codec, memoryVersion)
+		if err != nil {
+			return err
+		}
+		key, err := w.KeyFunc(object)
+		if err != nil {
+			return fmt.Errorf("couldn't compute key: %v", err)
+		}
+		elems = append(elems, &StoreElement{Cluster: clusterName, Key: key, Object: object})
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	store, ok := w.stores[clusterName]
+	if !ok {
+		store = cache.NewIndexer(storeElementKey, storeElementIndexers(nil))
+		w.stores[clusterName] = store
+	}
+	for _, elem := range elems {
+		if err := store.Add(elem); err != nil {
+			return err
+		}
+	}
+	w.resourceVersion = resourceVersion
+	return nil
+}
+
 func (w *WatchCache) AddIndexer(clusterName string, indexers *cache.Indexers) {
 	w.Lock()
 	defer w.Unlock()
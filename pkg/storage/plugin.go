@@ -6,6 +6,8 @@ import (
 	"plugin"
 )
 
+// LoadPlugins opens the Go plugins in the dir, the plugins must be built with the same toolchain
+// and dependency versions as the server, the `grpc` storage runs the storage plugins out of process instead.
 func LoadPlugins(dir string) error {
 	if dir == "" {
 		return nil
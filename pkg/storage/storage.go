@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -37,6 +38,50 @@ type ResourceStorage interface {
 	Create(ctx context.Context, cluster string, obj runtime.Object) error
 	Update(ctx context.Context, cluster string, obj runtime.Object) error
 	Delete(ctx context.Context, cluster string, obj runtime.Object) error
+
+	// Upsert creates the resource or updates the existing one in a single atomic operation,
+	// the storage may skip the update if the stored resource has a newer resource version.
+	Upsert(ctx context.Context, cluster string, obj runtime.Object) error
+}
+
+// ResourceCounter is implemented by the resource storages which support counting
+// the resources by the `GroupBy` of the list options.
+type ResourceCounter interface {
+	Count(ctx context.Context, opts *internal.ListOptions) (*internal.ResourceCounts, error)
+}
+
+// ResourceHistory is implemented by the resource storages which record the history of the resources,
+// the `AsOf` of the list options is only supported by these storages.
+type ResourceHistory interface {
+	GetAsOf(ctx context.Context, cluster, namespace, name string, asOf time.Time, obj runtime.Object) error
+	ListRevisions(ctx context.Context, cluster, namespace, name string) (*internal.ResourceRevisions, error)
+}
+
+// ResourceRelatedResolver is implemented by the resource storages which can resolve the related resources,
+// the `RelatedResources` of the list options is only supported by these storages.
+//
+// The related resources of each object are attached to it by the `shadow.clusterpedia.io/related-resources` annotation,
+// the obj can be a single object or a list.
+type ResourceRelatedResolver interface {
+	ResolveRelatedResources(ctx context.Context, obj runtime.Object, opts *internal.ListOptions) error
+}
+
+// ResourceBatchWriter is implemented by the resource storages which support writing
+// the changes of multiple resources in one batch.
+//
+// The changes in a batch belong to different resources, the created and updated resources
+// are written as upserts, so the caller doesn't need to distinguish between them.
+type ResourceBatchWriter interface {
+	BatchWrite(ctx context.Context, cluster string, changes []ResourceChange) error
+}
+
+// ResourceChange is a change of the resource written by the `ResourceBatchWriter`.
+type ResourceChange struct {
+	// Deleted means the resource is deleted from the cluster,
+	// only the namespace and name of the `Object` are used.
+	Deleted bool
+
+	Object runtime.Object
 }
 
 type CollectionResourceStorage interface {
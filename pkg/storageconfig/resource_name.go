@@ -0,0 +1,32 @@
+package storageconfig
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var versionRegexp = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+// ParseResourceName parses the resource in the format of `<resource>[.<version>][.<group>]`,
+// such as `pods`, `deployments.apps` or `foos.v1.example.com`.
+func ParseResourceName(name string) schema.GroupVersionResource {
+	parts := strings.SplitN(name, ".", 2)
+	gvr := schema.GroupVersionResource{Resource: parts[0]}
+	if len(parts) == 1 {
+		return gvr
+	}
+
+	parts = strings.SplitN(parts[1], ".", 2)
+	if !versionRegexp.MatchString(parts[0]) {
+		gvr.Group = strings.Join(parts, ".")
+		return gvr
+	}
+
+	gvr.Version = parts[0]
+	if len(parts) == 2 {
+		gvr.Group = parts[1]
+	}
+	return gvr
+}
@@ -13,12 +13,14 @@ import (
 	"k8s.io/klog/v2"
 
 	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/metrics"
 )
 
 func (synchro *ClusterSynchro) monitor() {
 	klog.V(2).InfoS("Cluster Synchro Monitor Running...", "cluster", synchro.name)
 
 	wait.JitterUntil(synchro.checkClusterHealthy, 5*time.Second, 0.5, true, synchro.closer)
+	metrics.DeleteCluster(synchro.name)
 
 	healthyCondition := metav1.Condition{
 		Type:               clusterv1alpha2.ClusterHealthyCondition,
@@ -39,6 +41,7 @@ func (synchro *ClusterSynchro) checkClusterHealthy() {
 
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 	defer cancel()
+	start := time.Now()
 	if ready, err := synchro.healthChecker.Ready(ctx); !ready {
 		// if the last status was not ConditionTrue, stop resource synchros
 		if lastReadyCondition.Status != metav1.ConditionTrue {
@@ -51,10 +54,13 @@ func (synchro *ClusterSynchro) checkClusterHealthy() {
 			Reason:  clusterv1alpha2.ClusterUnhealthyReason,
 			Message: "cluster health responded without ok",
 		}
+		result := metrics.HealthCheckResultUnhealthy
 		if err != nil {
 			condition.Reason = clusterv1alpha2.ClusterNotReachableReason
 			condition.Message = err.Error()
+			result = metrics.HealthCheckResultUnreachable
 		}
+		metrics.ObserveHealthCheck(synchro.name, start, result)
 
 		if lastReadyCondition.Status != condition.Status || lastReadyCondition.Reason != condition.Reason || lastReadyCondition.Message != condition.Message {
 			condition.LastTransitionTime = metav1.Now().Rfc3339Copy()
@@ -63,6 +69,8 @@ func (synchro *ClusterSynchro) checkClusterHealthy() {
 		return
 	}
 
+	metrics.ObserveHealthCheck(synchro.name, start, metrics.HealthCheckResultHealthy)
+
 	synchro.startRunner()
 	message := "cluster health responded with ok"
 	if lastReadyCondition.Status == metav1.ConditionTrue && lastReadyCondition.Message == message {
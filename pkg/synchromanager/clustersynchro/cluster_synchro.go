@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
@@ -281,8 +283,33 @@ func (s *ClusterSynchro) refreshSyncResources() {
 
 		for storageGVR, config := range storageResourceSyncConfigs {
 			// TODO: if config is changed, don't update resource synchro
-			if _, ok := s.storageResourceSynchros.Load(storageGVR); ok {
-				continue
+			if value, ok := s.storageResourceSynchros.Load(storageGVR); ok {
+				synchro := value.(*ResourceSynchro)
+				filtersChanged := !reflect.DeepEqual(synchro.filters, config.filters)
+				transformsChanged := !reflect.DeepEqual(synchro.transformer, config.transformer)
+				if !filtersChanged && !transformsChanged {
+					continue
+				}
+
+				// The filters or transforms are changed, recreate the resource synchro,
+				// the resources that no longer match the filters will be deleted when it's relisted.
+				klog.InfoS("Sync filters or transforms are changed, recreate the resource synchro", "cluster", s.name, "storage resource", storageGVR)
+				select {
+				case <-synchro.Close():
+				case <-s.closer:
+					return
+				}
+				s.storageResourceSynchros.Delete(storageGVR)
+
+				if transformsChanged {
+					// Reset the resource versions so that all stored resources are updated
+					// with the new transforms when they are relisted.
+					synchro.rvsLock.Lock()
+					for key := range synchro.rvs {
+						synchro.rvs[key] = "0"
+					}
+					synchro.rvsLock.Unlock()
+				}
 			}
 
 			resourceStorage, err := s.storage.NewResourceStorage(config.storageConfig)
@@ -302,7 +329,9 @@ func (s *ClusterSynchro) refreshSyncResources() {
 				s.name,
 				config.syncResource,
 				config.kind,
-				s.listerWatcherFactory.ForResource(metav1.NamespaceAll, config.syncResource),
+				config.filters,
+				config.transformer,
+				s.newListerWatcher(config.syncResource, config.filters),
 				rvs,
 				config.convertor,
 				resourceStorage,
@@ -374,6 +403,27 @@ func (s *ClusterSynchro) refreshSyncResources() {
 	}
 }
 
+func (s *ClusterSynchro) newListerWatcher(gvr schema.GroupVersionResource, filters *clusterv1alpha2.ClusterResourceSyncFilters) cache.ListerWatcher {
+	if filters == nil {
+		return s.listerWatcherFactory.ForResource(metav1.NamespaceAll, gvr)
+	}
+
+	// Only one namespace can be specified in the list and watch requests,
+	// multiple namespaces are filtered after watching all namespaces.
+	namespace := metav1.NamespaceAll
+	if len(filters.Namespaces) == 1 {
+		namespace = filters.Namespaces[0]
+	}
+	lw := s.listerWatcherFactory.ForResourceWithOptions(namespace, gvr, func(options *metav1.ListOptions) {
+		options.LabelSelector = filters.LabelSelector
+		options.FieldSelector = filters.FieldSelector
+	})
+	if len(filters.Namespaces) > 1 {
+		lw = informer.NewNamespacesFilterListerWatcher(lw, filters.Namespaces)
+	}
+	return lw
+}
+
 func (s *ClusterSynchro) runner() {
 	for {
 		select {
@@ -480,6 +530,7 @@ func (s *ClusterSynchro) genClusterStatus() *clusterv1alpha2.ClusterStatus {
 					if cond.Version != synchro.syncResource.Version {
 						cond.SyncVersion = synchro.syncResource.Version
 					}
+					cond.Filters = synchro.filters
 
 					status := synchro.Status()
 					cond.Status = status.Status
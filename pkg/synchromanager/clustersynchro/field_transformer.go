@@ -0,0 +1,172 @@
+package clustersynchro
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+)
+
+// protectedFields are required by the storage layer and can not be transformed.
+var protectedFields = sets.NewString(
+	"apiVersion", "kind", "metadata",
+	"metadata.name", "metadata.namespace", "metadata.uid",
+	"metadata.resourceVersion", "metadata.creationTimestamp", "metadata.ownerReferences",
+)
+
+type fieldTransformer struct {
+	transforms []clusterv1alpha2.FieldTransform
+	paths      [][]pathSegment
+}
+
+// pathSegment is the key of the map, or all items of the list if `items` is true.
+type pathSegment struct {
+	key   string
+	items bool
+}
+
+// newFieldTransformer returns nil if there are no transforms.
+func newFieldTransformer(transforms []clusterv1alpha2.FieldTransform) (*fieldTransformer, error) {
+	if len(transforms) == 0 {
+		return nil, nil
+	}
+
+	transformer := &fieldTransformer{
+		transforms: make([]clusterv1alpha2.FieldTransform, 0, len(transforms)),
+		paths:      make([][]pathSegment, 0, len(transforms)),
+	}
+	for _, transform := range transforms {
+		switch transform.Operation {
+		case "":
+			transform.Operation = clusterv1alpha2.FieldTransformRemove
+		case clusterv1alpha2.FieldTransformRemove, clusterv1alpha2.FieldTransformRedact:
+		default:
+			return nil, fmt.Errorf("unsupported operation %q of the field %q", transform.Operation, transform.Path)
+		}
+
+		path, err := parseFieldPath(transform.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field path %q: %w", transform.Path, err)
+		}
+		transformer.transforms = append(transformer.transforms, transform)
+		transformer.paths = append(transformer.paths, path)
+	}
+	return transformer, nil
+}
+
+func parseFieldPath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	var fields []string
+	for i := 0; i < len(path); {
+		if path[i] == '[' {
+			end := strings.IndexByte(path[i:], ']')
+			if end == -1 {
+				return nil, errors.New("missing ']'")
+			}
+
+			if key := path[i+1 : i+end]; key == "" {
+				segments = append(segments, pathSegment{items: true})
+			} else {
+				segments = append(segments, pathSegment{key: key})
+				fields = append(fields, key)
+			}
+			i += end + 1
+		} else {
+			end := strings.IndexAny(path[i:], ".[]")
+			if end == -1 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, errors.New("empty field name")
+			}
+
+			segments = append(segments, pathSegment{key: path[i : i+end]})
+			fields = append(fields, path[i:i+end])
+			i += end
+		}
+
+		if i < len(path) && path[i] == '.' {
+			if i++; i == len(path) {
+				return nil, errors.New("empty field name")
+			}
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, errors.New("empty path")
+	}
+	if segments[0].items {
+		return nil, errors.New("the path must start with a field")
+	}
+	if segments[len(segments)-1].items {
+		return nil, errors.New("the path can not end with '[]'")
+	}
+	if protectedFields.Has(strings.Join(fields, ".")) {
+		return nil, errors.New("the field is protected")
+	}
+	return segments, nil
+}
+
+func (transformer *fieldTransformer) Transform(obj *unstructured.Unstructured) {
+	if transformer == nil {
+		return
+	}
+
+	for i, path := range transformer.paths {
+		transformField(obj.Object, path, transformer.transforms[i].Operation)
+	}
+}
+
+func transformField(value interface{}, path []pathSegment, operation clusterv1alpha2.FieldTransformOperation) {
+	segment, last := path[0], len(path) == 1
+	if segment.items {
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			transformField(item, path[1:], operation)
+		}
+		return
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	field, ok := fields[segment.key]
+	if !ok {
+		return
+	}
+	if !last {
+		transformField(field, path[1:], operation)
+		return
+	}
+
+	switch operation {
+	case clusterv1alpha2.FieldTransformRemove:
+		delete(fields, segment.key)
+	case clusterv1alpha2.FieldTransformRedact:
+		fields[segment.key] = redactValue(field)
+	}
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return ""
+	case map[string]interface{}:
+		for key, field := range v {
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,129 @@
+package clustersynchro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	testcases := []struct {
+		path    string
+		want    []pathSegment
+		wantErr bool
+	}{
+		{
+			path: "data",
+			want: []pathSegment{{key: "data"}},
+		},
+		{
+			path: "status.images",
+			want: []pathSegment{{key: "status"}, {key: "images"}},
+		},
+		{
+			path: "spec.containers[].env[].value",
+			want: []pathSegment{{key: "spec"}, {key: "containers"}, {items: true}, {key: "env"}, {items: true}, {key: "value"}},
+		},
+		{
+			path: "metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]",
+			want: []pathSegment{{key: "metadata"}, {key: "annotations"}, {key: "kubectl.kubernetes.io/last-applied-configuration"}},
+		},
+		{path: "", wantErr: true},
+		{path: "spec..replicas", wantErr: true},
+		{path: "spec.", wantErr: true},
+		{path: "spec.containers[", wantErr: true},
+		{path: "spec.containers[]", wantErr: true},
+		{path: "[]", wantErr: true},
+		{path: "metadata.name", wantErr: true},
+		{path: "metadata[resourceVersion]", wantErr: true},
+		{path: "kind", wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := parseFieldPath(tc.path)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, path)
+		})
+	}
+}
+
+func TestFieldTransformer(t *testing.T) {
+	transformer, err := newFieldTransformer([]clusterv1alpha2.FieldTransform{
+		{Path: "data", Operation: clusterv1alpha2.FieldTransformRedact},
+		{Path: "status.images"},
+		{Path: "metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]"},
+		{Path: "spec.containers[].env[].value", Operation: clusterv1alpha2.FieldTransformRedact},
+		{Path: "spec.notexists.field"},
+	})
+	assert.NoError(t, err)
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "test",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"app": "test",
+			},
+		},
+		"data": map[string]interface{}{
+			"username": "YWRtaW4=",
+			"password": "cGFzc3dvcmQ=",
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "app",
+					"env": []interface{}{
+						map[string]interface{}{"name": "TOKEN", "value": "token"},
+						map[string]interface{}{"name": "FROM", "valueFrom": map[string]interface{}{}},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"images": []interface{}{"nginx"},
+			"phase":  "Running",
+		},
+	}}
+	transformer.Transform(obj)
+
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":        "test",
+			"annotations": map[string]interface{}{"app": "test"},
+		},
+		"data": map[string]interface{}{
+			"username": "",
+			"password": "",
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name": "app",
+					"env": []interface{}{
+						map[string]interface{}{"name": "TOKEN", "value": ""},
+						map[string]interface{}{"name": "FROM", "valueFrom": map[string]interface{}{}},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+		},
+	}
+	assert.Equal(t, expected, obj.Object)
+
+	_, err = newFieldTransformer([]clusterv1alpha2.FieldTransform{{Path: "data", Operation: "Replace"}})
+	assert.Error(t, err)
+
+	var nilTransformer *fieldTransformer
+	assert.NotPanics(t, func() { nilTransformer.Transform(obj) })
+}
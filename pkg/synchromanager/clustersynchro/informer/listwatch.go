@@ -5,9 +5,11 @@ import (
 	"math/rand"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
@@ -70,7 +72,65 @@ func (f *listerWatcherFactory) ForResourceWithOptions(namespace string, gvr sche
 			if tweakListOptions != nil {
 				tweakListOptions(&options)
 			}
+
+			timeoutSeconds := int64(f.minWatchTimeout.Seconds() * (rand.Float64() + 1.0))
+			options.TimeoutSeconds = &timeoutSeconds
 			return client.Resource(gvr).Namespace(namespace).Watch(context.TODO(), options)
 		},
 	}
 }
+
+// NewNamespacesFilterListerWatcher filters the objects that are not in the namespaces,
+// it is used when multiple namespaces are listed and watched by one `lw`.
+func NewNamespacesFilterListerWatcher(lw cache.ListerWatcher, namespaces []string) cache.ListerWatcher {
+	return &namespacesFilterListerWatcher{lw: lw, namespaces: sets.NewString(namespaces...)}
+}
+
+type namespacesFilterListerWatcher struct {
+	lw         cache.ListerWatcher
+	namespaces sets.String
+}
+
+func (f *namespacesFilterListerWatcher) matches(obj runtime.Object) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	return f.namespaces.Has(accessor.GetNamespace())
+}
+
+func (f *namespacesFilterListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	list, err := f.lw.List(options)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		if f.matches(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	if err := meta.SetList(list, filtered); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (f *namespacesFilterListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w, err := f.lw.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		switch event.Type {
+		case watch.Bookmark, watch.Error:
+			return event, true
+		}
+		return event, f.matches(event.Object)
+	}), nil
+}
@@ -7,6 +7,8 @@ import (
 	"sync"
 	"sync/atomic"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -31,6 +33,8 @@ type ResourceNegotiator struct {
 type syncConfig struct {
 	kind          string
 	syncResource  schema.GroupVersionResource
+	filters       *clusterv1alpha2.ClusterResourceSyncFilters
+	transformer   *fieldTransformer
 	convertor     runtime.ObjectConvertor
 	storageConfig *storage.ResourceStorageConfig
 }
@@ -41,10 +45,13 @@ func (negotiator *ResourceNegotiator) SetSyncAllCustomResources(sync bool) {
 
 func (negotiator *ResourceNegotiator) NegotiateSyncResources(syncResources []clusterv1alpha2.ClusterGroupResources) (*GroupResourceStatus, map[schema.GroupVersionResource]syncConfig) {
 	var syncAllResources bool
+	var syncAllResourcesFilters clusterv1alpha2.ResourceSyncFilters
+	var syncAllResourcesTransforms []clusterv1alpha2.FieldTransform
 	var watchKubeVersion, watchAggregatorResourceTypes bool
 	for i, syncResource := range syncResources {
 		if syncResource.Group == "*" {
-			syncAllResources = true
+			syncAllResources, syncAllResourcesFilters = true, syncResource.ResourceSyncFilters
+			syncAllResourcesTransforms = syncResource.Transforms
 			watchKubeVersion, watchAggregatorResourceTypes = true, true
 			break
 		}
@@ -62,6 +69,8 @@ func (negotiator *ResourceNegotiator) NegotiateSyncResources(syncResources []clu
 					klog.InfoS("Skip resource sync", "cluster", negotiator.name, "group", syncResource.Group, "reason", "not match group")
 				} else {
 					syncResourcesByGroup.Versions = syncResource.Versions
+					syncResourcesByGroup.ResourceSyncFilters = syncResource.ResourceSyncFilters
+					syncResourcesByGroup.Transforms = syncResource.Transforms
 					syncResources[i] = *syncResourcesByGroup
 					if groupType == discovery.KubeResource {
 						watchKubeVersion = true
@@ -74,6 +83,10 @@ func (negotiator *ResourceNegotiator) NegotiateSyncResources(syncResources []clu
 
 	if syncAllResources {
 		syncResources = negotiator.dynamicDiscovery.GetAllResourcesAsSyncResources()
+		for i := range syncResources {
+			syncResources[i].ResourceSyncFilters = syncAllResourcesFilters
+			syncResources[i].Transforms = syncAllResourcesTransforms
+		}
 	} else if negotiator.syncAllCustomResources && clusterpediafeature.FeatureGate.Enabled(features.AllowSyncAllCustomResources) {
 		syncResources = negotiator.dynamicDiscovery.AttachAllCustomResourcesToSyncResources(syncResources)
 	}
@@ -107,6 +120,9 @@ func (negotiator *ResourceNegotiator) NegotiateSyncResources(syncResources []clu
 			// set syncGR.Resource to plural
 			syncGR.Resource = apiResource.Name
 
+			filters, filtersErr := negotiateSyncFilters(groupResources.ResourceSyncFilters, apiResource.Namespaced)
+			transformer, transformsErr := newFieldTransformer(groupResources.Transforms)
+
 			groupResourceStatus.addResource(syncGR, apiResource.Kind, apiResource.Namespaced)
 			for _, version := range syncVersions {
 				syncGVR := syncGR.WithVersion(version)
@@ -114,6 +130,19 @@ func (negotiator *ResourceNegotiator) NegotiateSyncResources(syncResources []clu
 					Version: syncGVR.Version,
 					Status:  clusterv1alpha2.ResourceSyncStatusPending,
 					Reason:  "SynchroCreating",
+					Filters: filters,
+				}
+				if filtersErr != nil {
+					syncCondition.Reason = "SynchroCreateFailed"
+					syncCondition.Message = fmt.Sprintf("invalid sync filters: %s", filtersErr)
+					groupResourceStatus.addSyncCondition(syncGVR, syncCondition)
+					continue
+				}
+				if transformsErr != nil {
+					syncCondition.Reason = "SynchroCreateFailed"
+					syncCondition.Message = fmt.Sprintf("invalid field transforms: %s", transformsErr)
+					groupResourceStatus.addSyncCondition(syncGVR, syncCondition)
+					continue
 				}
 
 				storageConfig, err := negotiator.resourceStorageConfig.NewConfig(syncGVR, apiResource.Namespaced)
@@ -147,6 +176,8 @@ func (negotiator *ResourceNegotiator) NegotiateSyncResources(syncResources []clu
 				storageResourceSyncConfigs[storageGVR] = syncConfig{
 					kind:          apiResource.Kind,
 					syncResource:  syncGVR,
+					filters:       filters,
+					transformer:   transformer,
 					storageConfig: storageConfig,
 					convertor:     convertor,
 				}
@@ -203,6 +234,56 @@ func negotiateSyncVersions(kind schema.GroupKind, wantVersions []string, support
 	return syncVersions, false, nil
 }
 
+// negotiateSyncFilters returns the effective filters of the resource synchro,
+// nil means that all resources are synchronized.
+//
+// The excluded namespaces are converted to the field selector when the namespaces are not specified.
+func negotiateSyncFilters(filters clusterv1alpha2.ResourceSyncFilters, namespaced bool) (*clusterv1alpha2.ClusterResourceSyncFilters, error) {
+	effective := &clusterv1alpha2.ClusterResourceSyncFilters{}
+
+	var fieldSelectors []fields.Selector
+	if filters.FieldSelector != "" {
+		selector, err := fields.ParseSelector(filters.FieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field selector: %w", err)
+		}
+		fieldSelectors = append(fieldSelectors, selector)
+	}
+
+	if namespaced {
+		excludes := sets.NewString(filters.ExcludeNamespaces...)
+		if len(filters.Namespaces) != 0 {
+			namespaces := sets.NewString(filters.Namespaces...).Difference(excludes)
+			if namespaces.Len() == 0 {
+				return nil, errors.New("all namespaces are excluded")
+			}
+			effective.Namespaces = namespaces.List()
+		} else {
+			for _, namespace := range excludes.List() {
+				fieldSelectors = append(fieldSelectors, fields.OneTermNotEqualSelector("metadata.namespace", namespace))
+			}
+		}
+	}
+	if len(fieldSelectors) != 0 {
+		effective.FieldSelector = fields.AndSelectors(fieldSelectors...).String()
+	}
+
+	if filters.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(filters.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector: %w", err)
+		}
+		if !selector.Empty() {
+			effective.LabelSelector = selector.String()
+		}
+	}
+
+	if len(effective.Namespaces) == 0 && effective.LabelSelector == "" && effective.FieldSelector == "" {
+		return nil, nil
+	}
+	return effective, nil
+}
+
 // GroupResourceStatus manages the status of synchronized resources
 // TODO: change to a more appropriate name
 type GroupResourceStatus struct {
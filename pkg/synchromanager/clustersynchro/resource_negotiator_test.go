@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
@@ -449,3 +450,95 @@ func TestGroupResourceStatus_Merge(t *testing.T) {
 	expectedAddition := NewGVRSet(podGR.WithVersion("v1"), deploymentGR.WithVersion("v1beta2"))
 	assert.Equal(t, expectedAddition, addition)
 }
+
+func TestNegotiateSyncFilters(t *testing.T) {
+	testcases := []struct {
+		name       string
+		filters    clusterv1alpha2.ResourceSyncFilters
+		namespaced bool
+
+		want    *clusterv1alpha2.ClusterResourceSyncFilters
+		wantErr bool
+	}{
+		{
+			name:       "empty filters",
+			namespaced: true,
+			want:       nil,
+		},
+		{
+			name: "namespaces",
+			filters: clusterv1alpha2.ResourceSyncFilters{
+				Namespaces:        []string{"ns-2", "ns-1", "ns-3"},
+				ExcludeNamespaces: []string{"ns-3"},
+			},
+			namespaced: true,
+			want:       &clusterv1alpha2.ClusterResourceSyncFilters{Namespaces: []string{"ns-1", "ns-2"}},
+		},
+		{
+			name: "all namespaces are excluded",
+			filters: clusterv1alpha2.ResourceSyncFilters{
+				Namespaces:        []string{"ns-1"},
+				ExcludeNamespaces: []string{"ns-1"},
+			},
+			namespaced: true,
+			wantErr:    true,
+		},
+		{
+			name: "exclude namespaces",
+			filters: clusterv1alpha2.ResourceSyncFilters{
+				ExcludeNamespaces: []string{"kube-system", "default"},
+				FieldSelector:     "status.phase=Running",
+			},
+			namespaced: true,
+			want: &clusterv1alpha2.ClusterResourceSyncFilters{
+				FieldSelector: "status.phase=Running,metadata.namespace!=default,metadata.namespace!=kube-system",
+			},
+		},
+		{
+			name: "cluster scoped resource",
+			filters: clusterv1alpha2.ResourceSyncFilters{
+				Namespaces:        []string{"ns-1"},
+				ExcludeNamespaces: []string{"kube-system"},
+			},
+			namespaced: false,
+			want:       nil,
+		},
+		{
+			name: "label selector",
+			filters: clusterv1alpha2.ResourceSyncFilters{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "nginx"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"prod"}},
+					},
+				},
+			},
+			namespaced: true,
+			want:       &clusterv1alpha2.ClusterResourceSyncFilters{LabelSelector: "app=nginx,env in (prod)"},
+		},
+		{
+			name:       "invalid label selector",
+			filters:    clusterv1alpha2.ResourceSyncFilters{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "-"}}},
+			namespaced: true,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid field selector",
+			filters:    clusterv1alpha2.ResourceSyncFilters{FieldSelector: "metadata.name"},
+			namespaced: true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			filters, err := negotiateSyncFilters(tc.filters, tc.namespaced)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, filters)
+		})
+	}
+}
@@ -13,7 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
-	genericstorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -22,6 +22,7 @@ import (
 	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/clustersynchro/informer"
 	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/clustersynchro/queue"
 	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/features"
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/metrics"
 	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
 	clusterpediafeature "github.com/clusterpedia-io/clusterpedia/pkg/utils/feature"
 )
@@ -32,10 +33,15 @@ type ResourceSynchro struct {
 	example         runtime.Object
 	syncResource    schema.GroupVersionResource
 	storageResource schema.GroupVersionResource
+	filters         *clusterv1alpha2.ClusterResourceSyncFilters
+	transformer     *fieldTransformer
 
 	queue         queue.EventQueue
 	listerWatcher cache.ListerWatcher
 
+	// metricLabels are the label values of the metrics of the resource synchro
+	metricLabels []string
+
 	cache   *informer.ResourceVersionStorage
 	rvs     map[string]interface{}
 	rvsLock sync.Mutex
@@ -44,6 +50,9 @@ type ResourceSynchro struct {
 	storage       storage.ResourceStorage
 	convertor     runtime.ObjectConvertor
 
+	// batchWriter is set when the storage supports the batched writes and `BatchWriteResources` is enabled
+	batchWriter storage.ResourceBatchWriter
+
 	status atomic.Value // clusterv1alpha2.ClusterResourceSyncCondition
 
 	startlock sync.Mutex
@@ -62,17 +71,19 @@ type ResourceSynchro struct {
 	closed    chan struct{}
 }
 
-func newResourceSynchro(cluster string, syncResource schema.GroupVersionResource, kind string, lw cache.ListerWatcher, rvs map[string]interface{},
-	convertor runtime.ObjectConvertor, storage storage.ResourceStorage,
+func newResourceSynchro(cluster string, syncResource schema.GroupVersionResource, kind string,
+	filters *clusterv1alpha2.ClusterResourceSyncFilters, transformer *fieldTransformer, lw cache.ListerWatcher,
+	rvs map[string]interface{}, convertor runtime.ObjectConvertor, storage storage.ResourceStorage,
 ) *ResourceSynchro {
 	storageConfig := storage.GetStorageConfig()
 	synchro := &ResourceSynchro{
 		cluster:         cluster,
 		syncResource:    syncResource,
 		storageResource: storageConfig.StorageGroupResource.WithVersion(storageConfig.StorageVersion.Version),
+		filters:         filters,
+		transformer:     transformer,
 
-		listerWatcher: lw,
-		rvs:           rvs,
+		rvs: rvs,
 
 		// all resources saved to the queue are `runtime.Object`
 		queue: queue.NewPressureQueue(cache.MetaNamespaceKeyFunc),
@@ -90,12 +101,16 @@ func newResourceSynchro(cluster string, syncResource schema.GroupVersionResource
 		closed: make(chan struct{}),
 	}
 	close(synchro.runnableForStorage)
+	synchro.batchWriter = newBatchWriter(storage)
 	synchro.ctx, synchro.cancel = context.WithCancel(context.Background())
 
 	example := &unstructured.Unstructured{}
 	example.SetGroupVersionKind(syncResource.GroupVersion().WithKind(kind))
 	synchro.example = example
 
+	synchro.metricLabels = metrics.ResourceLabelValues(cluster, syncResource)
+	synchro.listerWatcher = &relistCountingListerWatcher{ListerWatcher: lw, metricLabels: synchro.metricLabels}
+
 	synchro.setStatus(clusterv1alpha2.ResourceSyncStatusPending, "", "")
 	return synchro
 }
@@ -128,6 +143,8 @@ func (synchro *ResourceSynchro) Close() <-chan struct{} {
 		close(synchro.closer)
 		synchro.queue.Close()
 		synchro.cancel()
+
+		metrics.DeleteResource(synchro.cluster, synchro.syncResource)
 	})
 	return synchro.closed
 }
@@ -278,6 +295,7 @@ func (synchro *ResourceSynchro) OnAdd(obj interface{}) {
 	synchro.pruneObject(obj.(*unstructured.Unstructured))
 
 	_ = synchro.queue.Add(obj)
+	synchro.updateQueueDepth()
 }
 
 func (synchro *ResourceSynchro) OnUpdate(_, obj interface{}) {
@@ -293,6 +311,7 @@ func (synchro *ResourceSynchro) OnUpdate(_, obj interface{}) {
 	// https://github.com/clusterpedia-io/clusterpedia/issues/4
 	synchro.pruneObject(obj.(*unstructured.Unstructured))
 	_ = synchro.queue.Update(obj)
+	synchro.updateQueueDepth()
 }
 
 func (synchro *ResourceSynchro) OnDelete(obj interface{}) {
@@ -313,11 +332,21 @@ func (synchro *ResourceSynchro) OnDelete(obj interface{}) {
 	// we convert the object to `PartialObjectMetadata`
 	obj = &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
 	_ = synchro.queue.Delete(obj)
+	synchro.updateQueueDepth()
 }
 
 func (synchro *ResourceSynchro) OnSync(obj interface{}) {}
 
+func (synchro *ResourceSynchro) updateQueueDepth() {
+	metrics.ResourceQueueDepth.WithLabelValues(synchro.metricLabels...).Set(float64(synchro.queue.Len()))
+}
+
 func (synchro *ResourceSynchro) processResources() {
+	if synchro.batchWriter != nil {
+		synchro.processResourcesInBatches()
+		return
+	}
+
 	for {
 		select {
 		case <-synchro.closer:
@@ -334,66 +363,222 @@ func (synchro *ResourceSynchro) processResources() {
 			klog.Error(err)
 			continue
 		}
+		synchro.updateQueueDepth()
 
 		synchro.handleResourceEvent(event)
 	}
 }
 
+// newBatchWriter returns the batch writer of the resource storage,
+// nil means that the events are written one by one.
+func newBatchWriter(s storage.ResourceStorage) storage.ResourceBatchWriter {
+	if !clusterpediafeature.FeatureGate.Enabled(features.BatchWriteResources) {
+		return nil
+	}
+	writer, _ := s.(storage.ResourceBatchWriter)
+	return writer
+}
+
+const (
+	// batchWriteSize is the max number of the events written to the storage in a batch.
+	batchWriteSize = 500
+
+	// batchWriteInterval is the max time that an event waits for the batch to be filled.
+	batchWriteInterval = 200 * time.Millisecond
+)
+
+// processResourcesInBatches collects the events popped from the queue,
+// and writes them when the batch is full or `batchWriteInterval` has passed since the first event of the batch.
+//
+// The popped keys are not popped again until the events are done,
+// so a batch contains at most one event for each key, and the events of the same key are written in order.
+func (synchro *ResourceSynchro) processResourcesInBatches() {
+	events := make(chan *queue.Event)
+	go func() {
+		defer close(events)
+		for {
+			event, err := synchro.queue.Pop()
+			if err != nil {
+				if err == queue.ErrQueueClosed {
+					return
+				}
+
+				klog.Error(err)
+				continue
+			}
+			synchro.updateQueueDepth()
+			events <- event
+		}
+	}()
+
+	var batch []*queue.Event
+	var flush <-chan time.Time
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// the popped events must be done, otherwise their keys can't be popped again
+				if len(batch) != 0 {
+					synchro.handleResourceEvents(batch)
+				}
+				return
+			}
+
+			batch = append(batch, event)
+			if len(batch) < batchWriteSize {
+				if flush == nil {
+					flush = time.After(batchWriteInterval)
+				}
+				continue
+			}
+		case <-flush:
+		}
+
+		synchro.handleResourceEvents(batch)
+		batch, flush = nil, nil
+	}
+}
+
+// resourceChange is the event converted to the storage version, which is waiting to be written to the storage.
+type resourceChange struct {
+	key    string
+	action queue.ActionType
+	object runtime.Object
+
+	handler  func(ctx context.Context, obj runtime.Object) error
+	callback func(obj runtime.Object)
+}
+
 func (synchro *ResourceSynchro) handleResourceEvent(event *queue.Event) {
 	defer func() { _ = synchro.queue.Done(event) }()
 
+	if change := synchro.prepareResourceChange(event); change != nil {
+		synchro.storeResourceChange(change)
+	}
+}
+
+// handleResourceEvents writes the events in a batch,
+// if the batch fails, the events are written one by one so that a bad resource does not block the others.
+func (synchro *ResourceSynchro) handleResourceEvents(events []*queue.Event) {
+	defer func() {
+		for _, event := range events {
+			_ = synchro.queue.Done(event)
+		}
+	}()
+
+	changes := make([]*resourceChange, 0, len(events))
+	storageChanges := make([]storage.ResourceChange, 0, len(events))
+	for _, event := range events {
+		if change := synchro.prepareResourceChange(event); change != nil {
+			changes = append(changes, change)
+			storageChanges = append(storageChanges, storage.ResourceChange{Deleted: change.action == queue.Deleted, Object: change.object})
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	err := synchro.storeWithRetry(batchAction, func(ctx context.Context) error {
+		return synchro.batchWriter.BatchWrite(ctx, synchro.cluster, storageChanges)
+	})
+	if err == nil {
+		for _, change := range changes {
+			change.callback(change.object)
+		}
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	klog.ErrorS(err, "Failed to storage resources in batch, storage them one by one", "cluster", synchro.cluster,
+		"resource", synchro.storageResource, "count", len(changes))
+	for _, change := range changes {
+		synchro.storeResourceChange(change)
+	}
+}
+
+func (synchro *ResourceSynchro) prepareResourceChange(event *queue.Event) *resourceChange {
 	obj, ok := event.Object.(runtime.Object)
 	if !ok {
-		return
+		return nil
 	}
 	key, _ := cache.MetaNamespaceKeyFunc(obj)
+	metrics.ResourceEventsTotal.WithLabelValues(synchro.actionLabels(string(event.Action))...).Inc()
 
-	var callback func(obj runtime.Object)
-	var handler func(ctx context.Context, obj runtime.Object) error
+	change := &resourceChange{key: key, action: event.Action}
 	if event.Action != queue.Deleted {
+		if uobj, ok := obj.(*unstructured.Unstructured); ok {
+			synchro.transformer.Transform(uobj)
+		}
+
 		var err error
 		if obj, err = synchro.convertToStorageVersion(obj); err != nil {
 			klog.ErrorS(err, "Failed to convert resource", "cluster", synchro.cluster,
 				"action", event.Action, "resource", synchro.storageResource, "key", key)
-			return
+			return nil
 		}
 		utils.InjectClusterName(obj, synchro.cluster)
 
-		switch event.Action {
-		case queue.Added:
-			handler = synchro.createOrUpdateResource
-		case queue.Updated:
-			handler = synchro.updateOrCreateResource
-		}
-		callback = func(obj runtime.Object) {
+		change.handler = synchro.upsertResource
+		change.callback = func(obj runtime.Object) {
 			metaobj, _ := meta.Accessor(obj)
 			synchro.rvsLock.Lock()
 			synchro.rvs[key] = metaobj.GetResourceVersion()
 			synchro.rvsLock.Unlock()
 		}
 	} else {
-		handler, callback = synchro.deleteResource, func(_ runtime.Object) {
+		change.handler, change.callback = synchro.deleteResource, func(_ runtime.Object) {
 			synchro.rvsLock.Lock()
 			delete(synchro.rvs, key)
 			synchro.rvsLock.Unlock()
 		}
 	}
+	change.object = obj
+	return change
+}
+
+func (synchro *ResourceSynchro) storeResourceChange(change *resourceChange) {
+	err := synchro.storeWithRetry(string(change.action), func(ctx context.Context) error {
+		return change.handler(ctx, change.object)
+	})
+	if err == nil {
+		change.callback(change.object)
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	klog.ErrorS(err, "Failed to storage resource", "cluster", synchro.cluster,
+		"action", change.action, "resource", synchro.storageResource, "key", change.key)
+}
+
+// batchAction is the action label of the metrics for the batched writes.
+const batchAction = "Batch"
+
+func (synchro *ResourceSynchro) actionLabels(action string) []string {
+	return append(synchro.metricLabels[:len(synchro.metricLabels):len(synchro.metricLabels)], action)
+}
+
+// storeWithRetry calls `store` until it succeeds, or returns an error that is not a recoverable exception.
+func (synchro *ResourceSynchro) storeWithRetry(action string, store func(ctx context.Context) error) error {
+	actionLabels := synchro.actionLabels(action)
 
 	// TODO(Iceber): put the event back into the queue to retry?
 	for i := 0; ; i++ {
 		ctx, cancel := context.WithTimeout(synchro.ctx, 30*time.Second)
-		err := handler(ctx, obj)
+		start := time.Now()
+		err := store(ctx)
 		cancel()
+		metrics.StorageDuration.WithLabelValues(actionLabels...).Observe(time.Since(start).Seconds())
 		if err == nil {
-			callback(obj)
-
 			if synchro.isRunnableForStorage.Load() {
-				return
+				return nil
 			}
 
 			// Start the informer after processing the data in the queue to ensure that storage is up and running for a period of time.
 			if synchro.queue.Len() != 0 {
-				return
+				return nil
 			}
 
 			synchro.isRunnableForStorage.Store(true)
@@ -412,16 +597,15 @@ func (synchro *ResourceSynchro) handleResourceEvent(event *queue.Event) {
 				default:
 				}
 			}()
-			return
+			return nil
 		}
 
 		if errors.Is(err, context.Canceled) {
-			return
+			return err
 		}
+		metrics.StorageErrorsTotal.WithLabelValues(actionLabels...).Inc()
 		if !storage.IsRecoverableException(err) {
-			klog.ErrorS(err, "Failed to storage resource", "cluster", synchro.cluster,
-				"action", event.Action, "resource", synchro.storageResource, "key", key)
-			return
+			return err
 		}
 
 		// Store component exceptions, control informer start/stop, and retry sync at regular intervals
@@ -449,7 +633,10 @@ func (synchro *ResourceSynchro) handleResourceEvent(event *queue.Event) {
 				}()
 			}
 
-			synchro.queue.DiscardAndRetain(retainInQueue)
+			if discarded := synchro.queue.Len() - retainInQueue; synchro.queue.DiscardAndRetain(retainInQueue) {
+				metrics.DiscardedEventsTotal.WithLabelValues(synchro.metricLabels...).Add(float64(discarded))
+				synchro.updateQueueDepth()
+			}
 
 			// If the data in the queue is discarded,
 			// the data in the cache will be inconsistent with the data in the `rvs`,
@@ -460,11 +647,30 @@ func (synchro *ResourceSynchro) handleResourceEvent(event *queue.Event) {
 		}
 
 		//	klog.ErrorS(err, "will retry sync storage resource", "num", i, "cluster", synchro.cluster,
-		//		"action", event.Action, "resource", synchro.storageResource, "key", key)
+		//		"action", action, "resource", synchro.storageResource)
 		time.Sleep(2 * time.Second)
+		metrics.StorageRetriesTotal.WithLabelValues(synchro.metricLabels...).Inc()
 	}
 }
 
+// relistCountingListerWatcher counts the full lists made by the informer,
+// the following pages of a paginated list are not counted.
+type relistCountingListerWatcher struct {
+	cache.ListerWatcher
+	metricLabels []string
+}
+
+func (lw *relistCountingListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	if options.Continue == "" {
+		metrics.InformerRelistsTotal.WithLabelValues(lw.metricLabels...).Inc()
+	}
+	return lw.ListerWatcher.List(options)
+}
+
+func (lw *relistCountingListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return lw.ListerWatcher.Watch(options)
+}
+
 func (synchro *ResourceSynchro) convertToStorageVersion(obj runtime.Object) (runtime.Object, error) {
 	// if synchro.convertor == nil, it means no conversion is needed.
 	if synchro.convertor == nil {
@@ -489,20 +695,10 @@ func (synchro *ResourceSynchro) convertToStorageVersion(obj runtime.Object) (run
 	return obj, nil
 }
 
-func (synchro *ResourceSynchro) createOrUpdateResource(ctx context.Context, obj runtime.Object) error {
-	err := synchro.storage.Create(ctx, synchro.cluster, obj)
-	if genericstorage.IsExist(err) {
-		return synchro.storage.Update(ctx, synchro.cluster, obj)
-	}
-	return err
-}
-
-func (synchro *ResourceSynchro) updateOrCreateResource(ctx context.Context, obj runtime.Object) error {
-	err := synchro.storage.Update(ctx, synchro.cluster, obj)
-	if genericstorage.IsNotFound(err) {
-		return synchro.storage.Create(ctx, synchro.cluster, obj)
-	}
-	return err
+// upsertResource writes both the added and the updated resources,
+// the stored resource may be created or deleted by another writer before the event is handled.
+func (synchro *ResourceSynchro) upsertResource(ctx context.Context, obj runtime.Object) error {
+	return synchro.storage.Upsert(ctx, synchro.cluster, obj)
 }
 
 func (synchro *ResourceSynchro) deleteResource(ctx context.Context, obj runtime.Object) error {
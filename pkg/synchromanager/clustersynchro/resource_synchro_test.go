@@ -0,0 +1,196 @@
+package clustersynchro
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/atomic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/clustersynchro/queue"
+	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/metrics"
+)
+
+func TestRelistCountingListerWatcher(t *testing.T) {
+	metrics.Register()
+
+	labels := metrics.ResourceLabelValues("cluster-1", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"})
+	lw := &relistCountingListerWatcher{
+		ListerWatcher: &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return &unstructured.UnstructuredList{}, nil
+			},
+		},
+		metricLabels: labels,
+	}
+
+	for _, options := range []metav1.ListOptions{
+		{},
+		{Limit: 500},
+		{Limit: 500, Continue: "next-page"},
+		{ResourceVersion: "0"},
+	} {
+		if _, err := lw.List(options); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+	}
+
+	relists, err := testutil.GetCounterMetricValue(metrics.InformerRelistsTotal.WithLabelValues(labels...))
+	if err != nil {
+		t.Fatalf("get relists failed: %v", err)
+	}
+	if relists != 3 {
+		t.Errorf("expected 3 relists, but got %v", relists)
+	}
+}
+
+// fakeBatchStorage records the batches and the single writes of the resources
+type fakeBatchStorage struct {
+	storage.ResourceStorage
+
+	batchErr error
+	batches  [][]string
+	writes   []string
+}
+
+func changeName(deleted bool, obj runtime.Object) string {
+	key, _ := cache.MetaNamespaceKeyFunc(obj)
+	if deleted {
+		return "delete " + key
+	}
+	return "upsert " + key
+}
+
+func (s *fakeBatchStorage) BatchWrite(ctx context.Context, cluster string, changes []storage.ResourceChange) error {
+	var batch []string
+	for _, change := range changes {
+		batch = append(batch, changeName(change.Deleted, change.Object))
+	}
+	s.batches = append(s.batches, batch)
+	return s.batchErr
+}
+
+func (s *fakeBatchStorage) Upsert(ctx context.Context, cluster string, obj runtime.Object) error {
+	s.writes = append(s.writes, changeName(false, obj))
+	return nil
+}
+
+func (s *fakeBatchStorage) Delete(ctx context.Context, cluster string, obj runtime.Object) error {
+	s.writes = append(s.writes, changeName(true, obj))
+	return nil
+}
+
+func newTestUnstructured(name, rv string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetResourceVersion(rv)
+	return obj
+}
+
+func TestHandleResourceEvents(t *testing.T) {
+	tests := []struct {
+		name           string
+		batchErr       error
+		expectedWrites []string
+	}{
+		{name: "batch"},
+		{
+			name:           "fall back to single writes",
+			batchErr:       errors.New("bad resource"),
+			expectedWrites: []string{"upsert default/deploy-1", "delete default/deploy-2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeBatchStorage{batchErr: test.batchErr}
+			synchro := &ResourceSynchro{
+				cluster:              "cluster-1",
+				metricLabels:         metrics.ResourceLabelValues("cluster-1", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}),
+				queue:                queue.NewPressureQueue(cache.MetaNamespaceKeyFunc),
+				rvs:                  map[string]interface{}{"default/deploy-2": "1"},
+				storage:              fake,
+				batchWriter:          fake,
+				isRunnableForStorage: atomic.NewBool(true),
+			}
+			synchro.ctx, synchro.cancel = context.WithCancel(context.Background())
+			defer synchro.cancel()
+
+			_ = synchro.queue.Add(newTestUnstructured("deploy-1", "2"))
+			_ = synchro.queue.Delete(&metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deploy-2"}})
+
+			var events []*queue.Event
+			for i := 0; i < 2; i++ {
+				event, err := synchro.queue.Pop()
+				if err != nil {
+					t.Fatalf("pop failed: %v", err)
+				}
+				events = append(events, event)
+			}
+
+			// the key being written is not popped again until the batch is done
+			_ = synchro.queue.Update(newTestUnstructured("deploy-1", "3"))
+			if synchro.queue.Len() != 0 {
+				t.Errorf("expected the processing key is not queued, but got %d events", synchro.queue.Len())
+			}
+
+			synchro.handleResourceEvents(events)
+
+			expectedBatches := [][]string{{"upsert default/deploy-1", "delete default/deploy-2"}}
+			if !reflect.DeepEqual(fake.batches, expectedBatches) {
+				t.Errorf("expected batches %v, but got %v", expectedBatches, fake.batches)
+			}
+			if !reflect.DeepEqual(fake.writes, test.expectedWrites) {
+				t.Errorf("expected writes %v, but got %v", test.expectedWrites, fake.writes)
+			}
+			expectedRVs := map[string]interface{}{"default/deploy-1": "2"}
+			if !reflect.DeepEqual(synchro.rvs, expectedRVs) {
+				t.Errorf("expected rvs %v, but got %v", expectedRVs, synchro.rvs)
+			}
+			if synchro.queue.Len() != 1 {
+				t.Errorf("expected the newer event is queued after the batch is done, but got %d events", synchro.queue.Len())
+			}
+		})
+	}
+}
+
+func TestProcessResourcesInBatchesFlushOnClose(t *testing.T) {
+	fake := &fakeBatchStorage{}
+	synchro := &ResourceSynchro{
+		cluster:              "cluster-1",
+		metricLabels:         metrics.ResourceLabelValues("cluster-1", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}),
+		queue:                queue.NewPressureQueue(cache.MetaNamespaceKeyFunc),
+		rvs:                  map[string]interface{}{},
+		storage:              fake,
+		batchWriter:          fake,
+		isRunnableForStorage: atomic.NewBool(true),
+	}
+	synchro.ctx, synchro.cancel = context.WithCancel(context.Background())
+	defer synchro.cancel()
+
+	_ = synchro.queue.Add(newTestUnstructured("deploy-1", "1"))
+	_ = synchro.queue.Add(newTestUnstructured("deploy-2", "2"))
+	synchro.queue.Close()
+
+	// the pending batch is written when the queue is closed, rather than waiting for the flush interval
+	synchro.processResourcesInBatches()
+
+	expectedBatches := [][]string{{"upsert default/deploy-1", "upsert default/deploy-2"}}
+	if !reflect.DeepEqual(fake.batches, expectedBatches) {
+		t.Errorf("expected batches %v, but got %v", expectedBatches, fake.batches)
+	}
+	expectedRVs := map[string]interface{}{"default/deploy-1": "1", "default/deploy-2": "2"}
+	if !reflect.DeepEqual(synchro.rvs, expectedRVs) {
+		t.Errorf("expected rvs %v, but got %v", expectedRVs, synchro.rvs)
+	}
+}
@@ -16,15 +16,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/rest"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	clusterv1alpha2 "github.com/clusterpedia-io/api/cluster/v1alpha2"
+	"github.com/clusterpedia-io/clusterpedia/pkg/agent"
 	"github.com/clusterpedia-io/clusterpedia/pkg/controller"
 	crdclientset "github.com/clusterpedia-io/clusterpedia/pkg/generated/clientset/versioned"
 	"github.com/clusterpedia-io/clusterpedia/pkg/generated/informers/externalversions"
@@ -32,6 +34,7 @@ import (
 	"github.com/clusterpedia-io/clusterpedia/pkg/storage"
 	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/clustersynchro"
 	"github.com/clusterpedia-io/clusterpedia/pkg/synchromanager/features"
+	"github.com/clusterpedia-io/clusterpedia/pkg/utils"
 	clusterpediafeature "github.com/clusterpedia-io/clusterpedia/pkg/utils/feature"
 )
 
@@ -39,6 +42,9 @@ const ClusterSynchroControllerFinalizer = "clusterpedia.io/cluster-synchro-contr
 
 const defaultRetryNum = 5
 
+// defaultAgentTokenSecretKey is the key of the agent token in the secret if the key is not specified.
+const defaultAgentTokenSecretKey = "token"
+
 type Manager struct {
 	runLock sync.Mutex
 	stopCh  <-chan struct{}
@@ -52,9 +58,18 @@ type Manager struct {
 	clusterSyncResourcesLister clusterlister.ClusterSyncResourcesLister
 	clusterInformer            cache.SharedIndexInformer
 
+	// the informer of the agent token secrets is only created if the agents are enabled
+	kubeInformerFactory  informers.SharedInformerFactory
+	secretLister         corelisters.SecretLister
+	secretInformer       cache.SharedIndexInformer
+	agentSecretNamespace string
+
 	synchrolock      sync.RWMutex
 	synchros         map[string]*clustersynchro.ClusterSynchro
 	synchroWaitGroup wait.Group
+
+	// agents receives the resources of the clusters in the agent mode
+	agents *agent.Server
 }
 
 func NewManager(client crdclientset.Interface, storage storage.StorageFactory) *Manager {
@@ -70,12 +85,14 @@ func NewManager(client crdclientset.Interface, storage storage.StorageFactory) *
 		clusterlister:              clusterinformer.Lister(),
 		clusterInformer:            clusterinformer.Informer(),
 		clusterSyncResourcesLister: clusterSyncResourcesInformer.Lister(),
+
 		queue: workqueue.NewRateLimitingQueue(
 			NewItemExponentialFailureAndJitterSlowRateLimter(2*time.Second, 15*time.Second, 1*time.Minute, 1.0, defaultRetryNum),
 		),
 
 		synchros: make(map[string]*clustersynchro.ClusterSynchro),
 	}
+	manager.agents = agent.NewServer(storage, manager)
 
 	clusterinformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -96,6 +113,33 @@ func NewManager(client crdclientset.Interface, storage storage.StorageFactory) *
 	return manager
 }
 
+// EnableAgents watches the agent token secrets in the namespace, the secrets referenced
+// by the clusters in the agent mode must be in this namespace.
+// It must be called before `Run` if the ingestion endpoint of the agents is served.
+func (manager *Manager) EnableAgents(kubeclient kubernetes.Interface, secretNamespace string) {
+	factory := informers.NewSharedInformerFactoryWithOptions(kubeclient, 0, informers.WithNamespace(secretNamespace))
+	secretInformer := factory.Core().V1().Secrets()
+
+	manager.kubeInformerFactory = factory
+	manager.secretLister = secretInformer.Lister()
+	manager.secretInformer = secretInformer.Informer()
+	manager.agentSecretNamespace = secretNamespace
+
+	secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: manager.handleAgentTokenSecret,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			manager.handleAgentTokenSecret(newObj)
+		},
+		DeleteFunc: manager.handleAgentTokenSecret,
+	})
+}
+
+// AgentServer returns the ingestion endpoint of the agents, which should be served
+// by the leader together with the manager.
+func (manager *Manager) AgentServer() *agent.Server {
+	return manager.agents
+}
+
 func (manager *Manager) Run(workers int, stopCh <-chan struct{}) {
 	manager.runLock.Lock()
 	defer manager.runLock.Unlock()
@@ -107,7 +151,12 @@ func (manager *Manager) Run(workers int, stopCh <-chan struct{}) {
 	// informerFactory should not be controlled by stopCh
 	stopInformer := make(chan struct{})
 	manager.informerFactory.Start(stopInformer)
-	if !cache.WaitForCacheSync(stopCh, manager.clusterInformer.HasSynced) {
+	informersSynced := []cache.InformerSynced{manager.clusterInformer.HasSynced}
+	if manager.kubeInformerFactory != nil {
+		manager.kubeInformerFactory.Start(stopInformer)
+		informersSynced = append(informersSynced, manager.secretInformer.HasSynced)
+	}
+	if !cache.WaitForCacheSync(stopCh, informersSynced...) {
 		klog.Fatal("clustersynchro manager: wait for informer factory failed")
 	}
 
@@ -181,6 +230,31 @@ func (manager *Manager) handleClusterSyncResources(obj interface{}) {
 	}
 }
 
+func (manager *Manager) handleAgentTokenSecret(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.ErrorS(err, "handle secret failed")
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.ErrorS(err, "handle secret failed")
+		return
+	}
+
+	clusters, err := manager.clusterlister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "list clusters failed while handling secret", "secret", key)
+		return
+	}
+	for _, cluster := range clusters {
+		ref := cluster.Spec.AgentTokenSecretRef
+		if cluster.Spec.Mode == clusterv1alpha2.ClusterSyncModeAgent && ref != nil && ref.Namespace == namespace && ref.Name == name {
+			manager.enqueue(cluster)
+		}
+	}
+}
+
 func (manager *Manager) worker() {
 	for manager.processNextCluster() {
 		select {
@@ -262,11 +336,18 @@ func (manager *Manager) reconcileCluster(cluster *clusterv1alpha2.PediaCluster)
 		}
 	}
 
+	if cluster.Spec.Mode == clusterv1alpha2.ClusterSyncModeAgent {
+		return manager.reconcileAgentCluster(cluster)
+	}
+
+	// the cluster may be switched from the agent mode
+	manager.agents.RemoveCluster(cluster.Name)
+
 	manager.synchrolock.RLock()
 	synchro := manager.synchros[cluster.Name]
 	manager.synchrolock.RUnlock()
 
-	config, err := buildClusterConfig(cluster)
+	config, err := utils.BuildClusterConfig(cluster)
 	if err != nil {
 		klog.ErrorS(err, "Failed to build cluster config", "cluster", cluster.Name)
 		manager.UpdateClusterAPIServerAndValidatedCondition(cluster.Name, cluster.Spec.APIServer, synchro, clusterv1alpha2.InvalidConfigReason,
@@ -274,39 +355,13 @@ func (manager *Manager) reconcileCluster(cluster *clusterv1alpha2.PediaCluster)
 		return controller.NoRequeueResult
 	}
 
-	var warnMsg string
-	syncResources := cluster.Spec.SyncResources
-	if refName := cluster.Spec.SyncResourcesRefName; refName != "" {
-		if ref, err := manager.clusterSyncResourcesLister.Get(refName); err != nil {
-			if !apierrors.IsNotFound(err) {
-				klog.ErrorS(err, "Failed to get SyncResourcesRef of cluster", "cluster", cluster.Name, "SyncResourcesRef", refName)
-				manager.UpdateClusterAPIServerAndValidatedCondition(cluster.Name, config.Host, synchro, clusterv1alpha2.InvalidSyncResourcesReason,
-					fmt.Sprintf("Failed to get cluster sync resources of cluster: %v", err), metav1.ConditionFalse)
-				return controller.RequeueResult(defaultRetryNum)
-			}
-
-			// TODO: Use more obvious method to let users know.
-			klog.Warningf("cluster(%s)'s SyncResourcesRef is not found", cluster.Name)
-			warnMsg = "Warning: sync resource ref is not found"
-		} else {
-			syncResources = append(syncResources, ref.Spec.SyncResources...)
-		}
-	}
-
-	// if `AllowSyncAllResources` is not enabled, then check whether the all-resource wildcard is used
-	if !clusterpediafeature.FeatureGate.Enabled(features.AllowSyncAllResources) {
-		for _, groupResources := range syncResources {
-			if groupResources.Group == "*" {
-				// When using the all-resource wildcard without feature gate enabled,
-				// it just updates the condition information and
-				// does not stop it if cluster synchro is already running.
-				//
-				// If have better suggestions can be discussed in the https://github.com/clusterpedia-io/clusterpedia/issues.
-				manager.UpdateClusterAPIServerAndValidatedCondition(cluster.Name, config.Host, synchro, clusterv1alpha2.InvalidSyncResourcesReason,
-					"ClusterSynchro Manager's feature gate `AllowSyncAllResources` is not enabled, cannot use all-resources wildcard", metav1.ConditionFalse)
-				return controller.NoRequeueResult
-			}
+	syncResources, warnMsg, requeue, err := manager.getSyncResources(cluster)
+	if err != nil {
+		manager.UpdateClusterAPIServerAndValidatedCondition(cluster.Name, config.Host, synchro, clusterv1alpha2.InvalidSyncResourcesReason, err.Error(), metav1.ConditionFalse)
+		if requeue {
+			return controller.RequeueResult(defaultRetryNum)
 		}
+		return controller.NoRequeueResult
 	}
 
 	manager.UpdateClusterAPIServerAndValidatedCondition(cluster.Name, config.Host, synchro, clusterv1alpha2.ValidatedReason, warnMsg, metav1.ConditionTrue)
@@ -374,6 +429,122 @@ func (manager *Manager) reconcileCluster(cluster *clusterv1alpha2.PediaCluster)
 	return controller.NoRequeueResult
 }
 
+// getSyncResources returns the sync resources of the cluster, including the resources of the `SyncResourcesRefName`.
+// If the sync resources are invalid, the returned error is the message of the validated condition.
+func (manager *Manager) getSyncResources(cluster *clusterv1alpha2.PediaCluster) (syncResources []clusterv1alpha2.ClusterGroupResources, warnMsg string, requeue bool, err error) {
+	syncResources = cluster.Spec.SyncResources
+	if refName := cluster.Spec.SyncResourcesRefName; refName != "" {
+		if ref, err := manager.clusterSyncResourcesLister.Get(refName); err != nil {
+			if !apierrors.IsNotFound(err) {
+				klog.ErrorS(err, "Failed to get SyncResourcesRef of cluster", "cluster", cluster.Name, "SyncResourcesRef", refName)
+				return nil, "", true, fmt.Errorf("Failed to get cluster sync resources of cluster: %v", err)
+			}
+
+			// TODO: Use more obvious method to let users know.
+			klog.Warningf("cluster(%s)'s SyncResourcesRef is not found", cluster.Name)
+			warnMsg = "Warning: sync resource ref is not found"
+		} else {
+			syncResources = append(syncResources, ref.Spec.SyncResources...)
+		}
+	}
+
+	// if `AllowSyncAllResources` is not enabled, then check whether the all-resource wildcard is used
+	if !clusterpediafeature.FeatureGate.Enabled(features.AllowSyncAllResources) {
+		for _, groupResources := range syncResources {
+			if groupResources.Group == "*" {
+				// When using the all-resource wildcard without feature gate enabled,
+				// it just updates the condition information and
+				// does not stop it if cluster synchro is already running.
+				//
+				// If have better suggestions can be discussed in the https://github.com/clusterpedia-io/clusterpedia/issues.
+				return nil, "", false, errors.New("ClusterSynchro Manager's feature gate `AllowSyncAllResources` is not enabled, cannot use all-resources wildcard")
+			}
+		}
+	}
+	return syncResources, warnMsg, false, nil
+}
+
+// reconcileAgentCluster registers the cluster in the agent mode to the agent server,
+// the resources and the status of the cluster are pushed by the agent running in the cluster.
+func (manager *Manager) reconcileAgentCluster(cluster *clusterv1alpha2.PediaCluster) controller.Result {
+	// the cluster may be switched from the pull mode
+	manager.synchrolock.Lock()
+	synchro := manager.synchros[cluster.Name]
+	delete(manager.synchros, cluster.Name)
+	manager.synchrolock.Unlock()
+	if synchro != nil {
+		klog.InfoS("cluster is switched to the agent mode, shutdown cluster synchro", "cluster", cluster.Name)
+		synchro.Shutdown(false)
+	}
+
+	token, requeue, err := manager.getAgentToken(cluster)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get the token of the agent", "cluster", cluster.Name)
+		manager.agents.RemoveCluster(cluster.Name)
+		manager.updateAPIServerAndValidatedCondition(cluster.Name, "", false, clusterv1alpha2.InvalidConfigReason,
+			"invalid cluster config: "+err.Error(), metav1.ConditionFalse)
+		if requeue {
+			return controller.RequeueResult(defaultRetryNum)
+		}
+		return controller.NoRequeueResult
+	}
+
+	syncResources, warnMsg, requeue, err := manager.getSyncResources(cluster)
+	if err != nil {
+		manager.updateAPIServerAndValidatedCondition(cluster.Name, "", manager.agents.Connected(cluster.Name),
+			clusterv1alpha2.InvalidSyncResourcesReason, err.Error(), metav1.ConditionFalse)
+		if requeue {
+			return controller.RequeueResult(defaultRetryNum)
+		}
+		return controller.NoRequeueResult
+	}
+
+	if err := manager.storage.PrepareCluster(cluster.Name); err != nil {
+		klog.ErrorS(err, "Failed to prepare cluster", "cluster", cluster.Name)
+		return controller.NoRequeueResult
+	}
+
+	manager.agents.SetCluster(cluster.Name, token, agent.Config{
+		SyncResources:          syncResources,
+		SyncAllCustomResources: cluster.Spec.SyncAllCustomResources,
+	})
+	manager.updateAPIServerAndValidatedCondition(cluster.Name, "", manager.agents.Connected(cluster.Name),
+		clusterv1alpha2.ValidatedReason, warnMsg, metav1.ConditionTrue)
+	return controller.NoRequeueResult
+}
+
+// getAgentToken returns the token of the agent from the secret referenced by the cluster,
+// the cluster is enqueued again when the secret is changed.
+func (manager *Manager) getAgentToken(cluster *clusterv1alpha2.PediaCluster) (token []byte, requeue bool, err error) {
+	if manager.secretLister == nil {
+		return nil, false, errors.New("the ingestion endpoint of the agents is disabled")
+	}
+
+	ref := cluster.Spec.AgentTokenSecretRef
+	if ref == nil || ref.Namespace == "" || ref.Name == "" {
+		return nil, false, errors.New("the agentTokenSecretRef is required in the agent mode")
+	}
+	if ref.Namespace != manager.agentSecretNamespace {
+		return nil, false, fmt.Errorf("the secret of the agent token must be in the namespace %s", manager.agentSecretNamespace)
+	}
+	key := ref.Key
+	if key == "" {
+		key = defaultAgentTokenSecretKey
+	}
+
+	secret, err := manager.secretLister.Secrets(ref.Namespace).Get(ref.Name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, fmt.Errorf("the secret %s/%s of the agent token is not found", ref.Namespace, ref.Name)
+		}
+		return nil, true, fmt.Errorf("failed to get the secret %s/%s of the agent token: %w", ref.Namespace, ref.Name, err)
+	}
+	if token = secret.Data[key]; len(token) == 0 {
+		return nil, false, fmt.Errorf("the key %q of the secret %s/%s of the agent token is empty", key, ref.Namespace, ref.Name)
+	}
+	return token, false, nil
+}
+
 func (manager *Manager) removeCluster(name string) error {
 	manager.synchrolock.Lock()
 	synchro := manager.synchros[name]
@@ -385,12 +556,19 @@ func (manager *Manager) removeCluster(name string) error {
 		// and ensure that no more data is being synchronized to the resource storage
 		synchro.Shutdown(false)
 	}
+	manager.agents.RemoveCluster(name)
 
 	// clean cluster from storage
 	return manager.storage.CleanCluster(context.TODO(), name)
 }
 
 func (manager *Manager) UpdateClusterAPIServerAndValidatedCondition(name string, apiServerEndpoint string, synchro *clustersynchro.ClusterSynchro, reason, message string, status metav1.ConditionStatus) {
+	manager.updateAPIServerAndValidatedCondition(name, apiServerEndpoint, synchro != nil, reason, message, status)
+}
+
+// updateAPIServerAndValidatedCondition updates the validated condition,
+// the SynchroRunning and ClusterHealthy conditions are also updated if the cluster is not being synchronized.
+func (manager *Manager) updateAPIServerAndValidatedCondition(name string, apiServerEndpoint string, synchronizing bool, reason, message string, status metav1.ConditionStatus) {
 	validatedCondition := metav1.Condition{
 		Type:    clusterv1alpha2.ValidatedCondition,
 		Reason:  reason,
@@ -404,11 +582,11 @@ func (manager *Manager) UpdateClusterAPIServerAndValidatedCondition(name string,
 		}
 
 		meta.SetStatusCondition(&clusterStatus.Conditions, validatedCondition)
-		if synchro != nil {
+		if synchronizing {
 			return
 		}
 
-		// if the cluster synchro is nil, update SynchroRunning and ClusterHealthy conditions.
+		// if the cluster is not being synchronized, update SynchroRunning and ClusterHealthy conditions.
 		runningCondition := meta.FindStatusCondition(clusterStatus.Conditions, clusterv1alpha2.SynchroRunningCondition)
 		if validatedCondition.Status != metav1.ConditionTrue {
 			condition := metav1.Condition{
@@ -508,45 +686,6 @@ func (manager *Manager) updateClusterStatus(ctx context.Context, name string, up
 	})
 }
 
-func buildClusterConfig(cluster *clusterv1alpha2.PediaCluster) (*rest.Config, error) {
-	if len(cluster.Spec.Kubeconfig) != 0 {
-		clientconfig, err := clientcmd.NewClientConfigFromBytes(cluster.Spec.Kubeconfig)
-		if err != nil {
-			return nil, err
-		}
-		return clientconfig.ClientConfig()
-	}
-
-	if cluster.Spec.APIServer == "" {
-		return nil, errors.New("Cluster APIServer Endpoint is required")
-	}
-
-	if len(cluster.Spec.TokenData) == 0 &&
-		(len(cluster.Spec.CertData) == 0 || len(cluster.Spec.KeyData) == 0) {
-		return nil, errors.New("Cluster APIServer's Token or Cert is required")
-	}
-
-	config := &rest.Config{
-		Host: cluster.Spec.APIServer,
-	}
-
-	if len(cluster.Spec.CAData) != 0 {
-		config.TLSClientConfig.CAData = cluster.Spec.CAData
-	} else {
-		config.TLSClientConfig.Insecure = true
-	}
-
-	if len(cluster.Spec.CertData) != 0 && len(cluster.Spec.KeyData) != 0 {
-		config.TLSClientConfig.CertData = cluster.Spec.CertData
-		config.TLSClientConfig.KeyData = cluster.Spec.KeyData
-	}
-
-	if len(cluster.Spec.TokenData) != 0 {
-		config.BearerToken = string(cluster.Spec.TokenData)
-	}
-	return config, nil
-}
-
 type ItemExponentialFailureAndJitterSlowRateLimter struct {
 	failuresLock sync.Mutex
 	failures     map[interface{}]int
@@ -45,6 +45,13 @@ const (
 	// owner: @iceber
 	// alpha: v0.6.0
 	HealthCheckerWithStandaloneTCP featuregate.Feature = "HealthCheckerWithStandaloneTCP"
+
+	// BatchWriteResources is a feature gate for the ResourceSynchro to write the resources to the storage in batches,
+	// it only takes effect when the storage layer supports the batched writes.
+	//
+	// owner: @agent
+	// alpha: v0.7.0
+	BatchWriteResources featuregate.Feature = "BatchWriteResources"
 )
 
 func init() {
@@ -59,4 +66,5 @@ var defaultClusterSynchroManagerFeatureGates = map[featuregate.Feature]featurega
 	AllowSyncAllCustomResources:    {Default: false, PreRelease: featuregate.Alpha},
 	AllowSyncAllResources:          {Default: false, PreRelease: featuregate.Alpha},
 	HealthCheckerWithStandaloneTCP: {Default: false, PreRelease: featuregate.Alpha},
+	BatchWriteResources:            {Default: false, PreRelease: featuregate.Alpha},
 }
@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	namespace = "clusterpedia"
+	subsystem = "clustersynchro"
+)
+
+var (
+	resourceLabels       = []string{"cluster", "group", "version", "resource"}
+	resourceActionLabels = []string{"cluster", "group", "version", "resource", "action"}
+)
+
+var (
+	// ResourceQueueDepth is the number of the events waiting in the pressure queue of the resource synchro.
+	ResourceQueueDepth = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "resource_queue_depth",
+			Help:           "Number of the events waiting in the queue of the resource synchro.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		resourceLabels,
+	)
+
+	// ResourceEventsTotal is the number of the events processed by the resource synchro.
+	ResourceEventsTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "resource_events_total",
+			Help:           "Number of the events processed by the resource synchro, partitioned by action.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		resourceActionLabels,
+	)
+
+	// StorageDuration is the latency of the resource synchro writing the resources to the storage.
+	StorageDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "storage_duration_seconds",
+			Help:           "Latency of writing the resources to the storage, partitioned by action.",
+			Buckets:        []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		resourceActionLabels,
+	)
+
+	// StorageErrorsTotal is the number of the failures of writing the resources to the storage.
+	StorageErrorsTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "storage_errors_total",
+			Help:           "Number of the failures of writing the resources to the storage, partitioned by action.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		resourceActionLabels,
+	)
+
+	// StorageRetriesTotal is the number of the retries after the recoverable storage exceptions.
+	StorageRetriesTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "storage_retries_total",
+			Help:           "Number of the retries of writing the resources after the recoverable storage exceptions.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		resourceLabels,
+	)
+
+	// DiscardedEventsTotal is the number of the events discarded from the queue when the storage is unavailable.
+	DiscardedEventsTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "discarded_events_total",
+			Help:           "Number of the events discarded from the queue when the storage is unavailable.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		resourceLabels,
+	)
+
+	// InformerRelistsTotal is the number of the full lists of the resources made by the informer.
+	InformerRelistsTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "informer_relists_total",
+			Help:           "Number of the full lists of the resources made by the informer.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		resourceLabels,
+	)
+
+	// ClusterHealthCheckDuration is the latency of checking the health of the cluster.
+	ClusterHealthCheckDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "cluster_health_check_duration_seconds",
+			Help:           "Latency of checking the health of the cluster.",
+			Buckets:        []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"cluster"},
+	)
+
+	// ClusterHealthChecksTotal is the number of the health checks of the cluster, partitioned by result.
+	ClusterHealthChecksTotal = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "cluster_health_checks_total",
+			Help:           "Number of the health checks of the cluster, partitioned by result.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"cluster", "result"},
+	)
+
+	// ClusterHealthy is 1 if the last health check of the cluster succeeded, and 0 otherwise.
+	ClusterHealthy = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "cluster_healthy",
+			Help:           "Whether the last health check of the cluster succeeded.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"cluster"},
+	)
+)
+
+// The results of the cluster health checks.
+const (
+	HealthCheckResultHealthy     = "healthy"
+	HealthCheckResultUnhealthy   = "unhealthy"
+	HealthCheckResultUnreachable = "unreachable"
+)
+
+var registerMetrics sync.Once
+
+// Register registers the metrics of the cluster synchros to the legacy registry.
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(ResourceQueueDepth)
+		legacyregistry.MustRegister(ResourceEventsTotal)
+		legacyregistry.MustRegister(StorageDuration)
+		legacyregistry.MustRegister(StorageErrorsTotal)
+		legacyregistry.MustRegister(StorageRetriesTotal)
+		legacyregistry.MustRegister(DiscardedEventsTotal)
+		legacyregistry.MustRegister(InformerRelistsTotal)
+		legacyregistry.MustRegister(ClusterHealthCheckDuration)
+		legacyregistry.MustRegister(ClusterHealthChecksTotal)
+		legacyregistry.MustRegister(ClusterHealthy)
+	})
+}
+
+// ResourceLabelValues returns the label values of the resource synchro in the order of `resourceLabels`.
+func ResourceLabelValues(cluster string, gvr schema.GroupVersionResource) []string {
+	return []string{cluster, gvr.Group, gvr.Version, gvr.Resource}
+}
+
+// ObserveHealthCheck records the latency and the result of the health check of the cluster.
+func ObserveHealthCheck(cluster string, start time.Time, result string) {
+	ClusterHealthCheckDuration.WithLabelValues(cluster).Observe(time.Since(start).Seconds())
+	ClusterHealthChecksTotal.WithLabelValues(cluster, result).Inc()
+
+	healthy := 0.0
+	if result == HealthCheckResultHealthy {
+		healthy = 1
+	}
+	ClusterHealthy.WithLabelValues(cluster).Set(healthy)
+}
+
+// DeleteCluster deletes the gauges of the cluster, so that the removed clusters are no longer reported.
+func DeleteCluster(cluster string) {
+	ClusterHealthy.Delete(map[string]string{"cluster": cluster})
+}
+
+// DeleteResource deletes the gauges of the resource synchro.
+func DeleteResource(cluster string, gvr schema.GroupVersionResource) {
+	ResourceQueueDepth.Delete(map[string]string{
+		"cluster":  cluster,
+		"group":    gvr.Group,
+		"version":  gvr.Version,
+		"resource": gvr.Resource,
+	})
+}
@@ -35,6 +35,18 @@ const (
 	NotReadyReason = "NotReady"
 )
 
+type ClusterSyncMode string
+
+const (
+	// ClusterSyncModePull means the clustersynchro manager connects to the apiserver of the cluster
+	// and pulls the resources, it is the default mode.
+	ClusterSyncModePull ClusterSyncMode = "Pull"
+
+	// ClusterSyncModeAgent means the agent running in the cluster pushes the resources to clusterpedia,
+	// it's used for the clusters whose apiserver cannot be reached by clusterpedia.
+	ClusterSyncModeAgent ClusterSyncMode = "Agent"
+)
+
 const (
 	ResourceSyncStatusPending = "Pending"
 	ResourceSyncStatusSyncing = "Syncing"
@@ -66,6 +78,13 @@ type PediaCluster struct {
 }
 
 type ClusterSpec struct {
+	// Mode is the way the resources of the cluster are synchronized, defaults to `Pull`.
+	// In the `Agent` mode, the connection fields of the cluster are not used,
+	// and the agent connects to clusterpedia with the token referenced by `agentTokenSecretRef`.
+	// +optional
+	// +kubebuilder:validation:Enum=Pull;Agent
+	Mode ClusterSyncMode `json:"mode,omitempty"`
+
 	// +optional
 	Kubeconfig []byte `json:"kubeconfig,omitempty"`
 
@@ -92,6 +111,26 @@ type ClusterSpec struct {
 
 	// +optional
 	SyncResourcesRefName string `json:"syncResourcesRefName,omitempty"`
+
+	// AgentTokenSecretRef references the token with which the agent connects to clusterpedia,
+	// it's required in the `Agent` mode.
+	// +optional
+	AgentTokenSecretRef *SecretKeyReference `json:"agentTokenSecretRef,omitempty"`
+}
+
+// SecretKeyReference references a key of the secret.
+type SecretKeyReference struct {
+	// +required
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// +required
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key is the key of the secret data, defaults to `token`.
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 type ClusterGroupResources struct {
@@ -104,6 +143,58 @@ type ClusterGroupResources struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems=1
 	Resources []string `json:"resources"`
+
+	// +optional
+	ResourceSyncFilters `json:",inline"`
+
+	// Transforms are applied to the resources in order before they are stored.
+	// +optional
+	Transforms []FieldTransform `json:"transforms,omitempty"`
+}
+
+// ResourceSyncFilters filters the resources to be synchronized,
+// the namespace filters are ignored by the cluster scoped resources.
+type ResourceSyncFilters struct {
+	// Namespaces limits the synchronized resources to these namespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ExcludeNamespaces skips the resources in these namespaces.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// FieldSelector is passed to the list and watch requests of the member cluster,
+	// so only the fields supported by the resource can be used.
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+type FieldTransformOperation string
+
+const (
+	// FieldTransformRemove removes the field from the resource.
+	FieldTransformRemove FieldTransformOperation = "Remove"
+
+	// FieldTransformRedact keeps the field and clears all the string values in it,
+	// for example, the keys of the secret's data are retained but the values are cleared.
+	FieldTransformRedact FieldTransformOperation = "Redact"
+)
+
+type FieldTransform struct {
+	// Path is the dot-separated path of the field, such as `data` or `status.images`.
+	// `[]` matches all items of the list, such as `spec.containers[].env[].value`,
+	// and `[key]` matches the key containing dots, such as `metadata.annotations[kubernetes.io/description]`.
+	// +required
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// +optional
+	// +kubebuilder:validation:Enum=Remove;Redact
+	// +kubebuilder:default=Remove
+	Operation FieldTransformOperation `json:"operation,omitempty"`
 }
 
 type ClusterStatus struct {
@@ -175,6 +266,9 @@ type ClusterResourceSyncCondition struct {
 	// optional
 	Message string `json:"message,omitempty"`
 
+	// optional
+	Filters *ClusterResourceSyncFilters `json:"filters,omitempty"`
+
 	// +required
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Type=string
@@ -182,6 +276,18 @@ type ClusterResourceSyncCondition struct {
 	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
 }
 
+// ClusterResourceSyncFilters is the effective filters of the resource synchro.
+type ClusterResourceSyncFilters struct {
+	// optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
 func (cond ClusterResourceSyncCondition) SyncGVR(resource schema.GroupResource) schema.GroupVersionResource {
 	if cond.Version == "" || cond.SyncVersion == "" {
 		return schema.GroupVersionResource{}
@@ -246,3 +352,47 @@ type ClusterSyncResourcesList struct {
 
 	Items []ClusterSyncResources `json:"items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope="Cluster"
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=".metadata.creationTimestamp"
+type CustomCollectionResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec CustomCollectionResourceSpec `json:"spec"`
+}
+
+type CustomCollectionResourceSpec struct {
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	ResourceTypes []CollectionResourceType `json:"resourceTypes"`
+}
+
+// CollectionResourceType matches the resources in the collection,
+// the resources of all versions are matched when Version is empty,
+// and all resources of the group are matched when Resource is empty.
+type CollectionResourceType struct {
+	// +required
+	Group string `json:"group"`
+
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// +optional
+	Resource string `json:"resource,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CustomCollectionResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []CustomCollectionResource `json:"items"`
+}
@@ -23,6 +23,12 @@ func (in *ClusterGroupResources) DeepCopyInto(out *ClusterGroupResources) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	in.ResourceSyncFilters.DeepCopyInto(&out.ResourceSyncFilters)
+	if in.Transforms != nil {
+		in, out := &in.Transforms, &out.Transforms
+		*out = make([]FieldTransform, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -85,6 +91,11 @@ func (in *ClusterResourceStatus) DeepCopy() *ClusterResourceStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterResourceSyncCondition) DeepCopyInto(out *ClusterResourceSyncCondition) {
 	*out = *in
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(ClusterResourceSyncFilters)
+		(*in).DeepCopyInto(*out)
+	}
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
@@ -99,6 +110,27 @@ func (in *ClusterResourceSyncCondition) DeepCopy() *ClusterResourceSyncCondition
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterResourceSyncFilters) DeepCopyInto(out *ClusterResourceSyncFilters) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterResourceSyncFilters.
+func (in *ClusterResourceSyncFilters) DeepCopy() *ClusterResourceSyncFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterResourceSyncFilters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	*out = *in
@@ -134,6 +166,11 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AgentTokenSecretRef != nil {
+		in, out := &in.AgentTokenSecretRef, &out.AgentTokenSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
 	return
 }
 
@@ -260,6 +297,119 @@ func (in *ClusterSyncResourcesSpec) DeepCopy() *ClusterSyncResourcesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectionResourceType) DeepCopyInto(out *CollectionResourceType) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectionResourceType.
+func (in *CollectionResourceType) DeepCopy() *CollectionResourceType {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectionResourceType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomCollectionResource) DeepCopyInto(out *CustomCollectionResource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomCollectionResource.
+func (in *CustomCollectionResource) DeepCopy() *CustomCollectionResource {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomCollectionResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CustomCollectionResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomCollectionResourceList) DeepCopyInto(out *CustomCollectionResourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CustomCollectionResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomCollectionResourceList.
+func (in *CustomCollectionResourceList) DeepCopy() *CustomCollectionResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomCollectionResourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CustomCollectionResourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomCollectionResourceSpec) DeepCopyInto(out *CustomCollectionResourceSpec) {
+	*out = *in
+	if in.ResourceTypes != nil {
+		in, out := &in.ResourceTypes, &out.ResourceTypes
+		*out = make([]CollectionResourceType, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomCollectionResourceSpec.
+func (in *CustomCollectionResourceSpec) DeepCopy() *CustomCollectionResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomCollectionResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldTransform) DeepCopyInto(out *FieldTransform) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldTransform.
+func (in *FieldTransform) DeepCopy() *FieldTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PediaCluster) DeepCopyInto(out *PediaCluster) {
 	*out = *in
@@ -320,3 +470,50 @@ func (in *PediaClusterList) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSyncFilters) DeepCopyInto(out *ResourceSyncFilters) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncFilters.
+func (in *ResourceSyncFilters) DeepCopy() *ResourceSyncFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSyncFilters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
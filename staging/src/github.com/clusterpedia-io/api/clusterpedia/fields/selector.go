@@ -257,6 +257,25 @@ func (f *Field) Validate() *field.Error {
 	return nil
 }
 
+// ParseFields parses the field path, such as `status.phase` or `metadata.annotations['app.kubernetes.io/name']`.
+func ParseFields(key string) ([]Field, error) {
+	fields, err := parseFields(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("fields is empty")
+	}
+
+	var allErrs field.ErrorList
+	for _, field := range fields {
+		if err := field.Validate(); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return fields, allErrs.ToAggregate()
+}
+
 func parseFields(key string, fields []Field) ([]Field, error) {
 	if len(key) == 0 {
 		return fields, nil
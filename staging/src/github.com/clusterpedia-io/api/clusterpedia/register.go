@@ -24,6 +24,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ListOptions{},
 		&CollectionResource{},
 		&CollectionResourceList{},
+		&ResourceCounts{},
+		&ResourceRevisions{},
 	)
 	return nil
 }
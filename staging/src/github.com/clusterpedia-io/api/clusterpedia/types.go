@@ -22,6 +22,7 @@ const (
 	SearchLabelOwnerName          = "search.clusterpedia.io/owner-name"
 	SearchLabelOwnerGroupResource = "search.clusterpedia.io/owner-gr"
 	SearchLabelOwnerSeniority     = "search.clusterpedia.io/owner-seniority"
+	SearchLabelRootOwner          = "search.clusterpedia.io/root-owner"
 
 	SearchLabelWithContinue       = "search.clusterpedia.io/with-continue"
 	SearchLabelWithRemainingCount = "search.clusterpedia.io/with-remaining-count"
@@ -29,11 +30,25 @@ const (
 	SearchLabelLimit  = "search.clusterpedia.io/limit"
 	SearchLabelOffset = "search.clusterpedia.io/offset"
 
+	SearchLabelGroupBy = "search.clusterpedia.io/group-by"
+
+	SearchLabelQuery = "search.clusterpedia.io/query"
+
 	SearchLabelSince  = "search.clusterpedia.io/since"
 	SearchLabelBefore = "search.clusterpedia.io/before"
 
+	SearchLabelAsOf = "search.clusterpedia.io/as-of"
+
+	SearchLabelIncludeDeleted = "search.clusterpedia.io/include-deleted"
+	SearchLabelOnlyDeleted    = "search.clusterpedia.io/only-deleted"
+
+	SearchLabelRequireSynced = "search.clusterpedia.io/require-synced"
+
+	SearchLabelWithRelated = "search.clusterpedia.io/with-related"
+
 	ShadowAnnotationClusterName          = "shadow.clusterpedia.io/cluster-name"
 	ShadowAnnotationGroupVersionResource = "shadow.clusterpedia.io/gvr"
+	ShadowAnnotationRelatedResources     = "shadow.clusterpedia.io/related-resources"
 )
 
 type OrderBy struct {
@@ -41,6 +56,13 @@ type OrderBy struct {
 	Desc  bool
 }
 
+// TableColumn is the custom column of the Table,
+// the cells are evaluated by the JSONPath on the resources.
+type TableColumn struct {
+	Name     string
+	JSONPath string
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type ListOptions struct {
 	metainternal.ListOptions
@@ -55,12 +77,41 @@ type ListOptions struct {
 	OwnerGroupResource schema.GroupResource
 	OwnerSeniority     int
 
+	// RootOwnerKind and RootOwnerName filter the resources by their ultimate controller owner,
+	// such as the Deployment of the Pods, the RootOwnerKind is optional.
+	RootOwnerKind string
+	RootOwnerName string
+
 	Since  *metav1.Time
 	Before *metav1.Time
 
+	// AsOf queries the versions of the resources that existed at the time,
+	// it requires the storage layer to record the history of the resources.
+	AsOf *metav1.Time
+
+	// IncludeDeleted and OnlyDeleted list the resources that have been deleted
+	// from the clusters but are still retained by the storage layer.
+	IncludeDeleted bool
+	OnlyDeleted    bool
+
+	// RequireSynced fails the request if the resource is not synchronized
+	// normally in any of the requested clusters.
+	RequireSynced bool
+
 	WithContinue       *bool
 	WithRemainingCount *bool
 
+	// GroupBy is used to count the resources by groups,
+	// it is ignored by the list requests.
+	GroupBy []string
+
+	// Query is the full-text search query, it matches the names, labels,
+	// annotations and other fields of the resources.
+	Query string
+
+	// Columns are the custom columns used when the resources are returned in Table format.
+	Columns []TableColumn
+
 	// +k8s:conversion-fn:drop
 	EnhancedFieldSelector fields.Selector
 
@@ -70,7 +121,10 @@ type ListOptions struct {
 	// +k8s:conversion-fn:drop
 	URLQuery url.Values
 
-	// RelatedResources []schema.GroupVersionKind
+	// RelatedResources are returned together with each resource in the
+	// `shadow.clusterpedia.io/related-resources` annotation, such as the resources
+	// owned by it and the events whose involved object is it.
+	RelatedResources []schema.GroupResource
 
 	OnlyMetadata bool
 }
@@ -95,6 +149,20 @@ type CollectionResourceList struct {
 	Items []CollectionResource
 }
 
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ResourceCounts struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	GroupBy []string
+	Items   []ResourceCount
+}
+
+type ResourceCount struct {
+	Values map[string]string
+	Count  int64
+}
+
 type CollectionResourceType struct {
 	Group    string
 	Version  string
@@ -108,3 +176,21 @@ func (t CollectionResourceType) GroupResource() schema.GroupResource {
 		Resource: t.Resource,
 	}
 }
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type ResourceRevisions struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Items []ResourceRevision
+}
+
+type ResourceRevision struct {
+	Revision        int64
+	ResourceVersion string
+
+	ValidFrom metav1.Time
+	ValidTo   *metav1.Time
+
+	Object runtime.Object
+}
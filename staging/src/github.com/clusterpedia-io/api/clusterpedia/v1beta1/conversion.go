@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -59,6 +60,7 @@ func Convert_v1beta1_ListOptions_To_clusterpedia_ListOptions(in *ListOptions, ou
 		out.OwnerGroupResource = schema.ParseGroupResource(in.OwnerGroupResource)
 	}
 	out.OwnerSeniority = in.OwnerSeniority
+	convert_String_To_root_owner(in.RootOwner, &out.RootOwnerKind, &out.RootOwnerName)
 
 	if err := convert_String_To_Pointer_metav1_Time(&in.Since, &out.Since, nil); err != nil {
 		return err
@@ -68,8 +70,31 @@ func Convert_v1beta1_ListOptions_To_clusterpedia_ListOptions(in *ListOptions, ou
 		return err
 	}
 
+	if err := convert_String_To_Pointer_metav1_Time(&in.AsOf, &out.AsOf, nil); err != nil {
+		return err
+	}
+
 	out.WithContinue = in.WithContinue
 	out.WithRemainingCount = in.WithRemainingCount
+	out.IncludeDeleted = in.IncludeDeleted
+	out.OnlyDeleted = in.OnlyDeleted
+	out.RequireSynced = in.RequireSynced
+
+	if err := convert_String_To_Slice_string(&in.GroupBy, &out.GroupBy, s); err != nil {
+		return err
+	}
+
+	out.Query = strings.TrimSpace(in.Query)
+
+	if err := convert_String_To_clusterpedia_Slice_TableColumn(&in.Columns, &out.Columns, s); err != nil {
+		return err
+	}
+
+	var related []string
+	if err := convert_String_To_Slice_string(&in.WithRelated, &related, s); err != nil {
+		return err
+	}
+	convert_Slice_string_To_Slice_schema_GroupResource(related, &out.RelatedResources)
 
 	if out.LabelSelector != nil {
 		var (
@@ -113,6 +138,10 @@ func Convert_v1beta1_ListOptions_To_clusterpedia_ListOptions(in *ListOptions, ou
 						}
 						out.OwnerSeniority = seniority
 					}
+				case clusterpedia.SearchLabelRootOwner:
+					if out.RootOwnerName == "" && len(values) == 1 {
+						convert_String_To_root_owner(values[0], &out.RootOwnerKind, &out.RootOwnerName)
+					}
 				case clusterpedia.SearchLabelSince:
 					if out.Since == nil && len(values) == 1 {
 						if err := convert_String_To_Pointer_metav1_Time(&values[0], &out.Since, nil); err != nil {
@@ -125,6 +154,12 @@ func Convert_v1beta1_ListOptions_To_clusterpedia_ListOptions(in *ListOptions, ou
 							return fmt.Errorf("Invalid Query Before(%s): %w", values[0], err)
 						}
 					}
+				case clusterpedia.SearchLabelAsOf:
+					if out.AsOf == nil && len(values) == 1 {
+						if err := convert_String_To_Pointer_metav1_Time(&values[0], &out.AsOf, nil); err != nil {
+							return fmt.Errorf("Invalid Query AsOf(%s): %w", values[0], err)
+						}
+					}
 				case clusterpedia.SearchLabelOrderBy:
 					if len(out.OrderBy) == 0 && len(values) != 0 {
 						if err := convert_Slice_string_To_clusterpedia_Slice_orderby(&values, &out.OrderBy, "_", s); err != nil {
@@ -150,6 +185,36 @@ func Convert_v1beta1_ListOptions_To_clusterpedia_ListOptions(in *ListOptions, ou
 							return fmt.Errorf("Invalid Query Offset(%s): %w", out.Continue, err)
 						}
 					}
+				case clusterpedia.SearchLabelGroupBy:
+					if len(out.GroupBy) == 0 && len(values) != 0 {
+						out.GroupBy = require.Values().List()
+					}
+				case clusterpedia.SearchLabelQuery:
+					if out.Query == "" && len(values) != 0 {
+						out.Query = strings.Join(require.Values().List(), " ")
+					}
+				case clusterpedia.SearchLabelIncludeDeleted:
+					if !in.IncludeDeleted && len(values) != 0 {
+						if err := runtime.Convert_Slice_string_To_bool(&values, &out.IncludeDeleted, s); err != nil {
+							return err
+						}
+					}
+				case clusterpedia.SearchLabelOnlyDeleted:
+					if !in.OnlyDeleted && len(values) != 0 {
+						if err := runtime.Convert_Slice_string_To_bool(&values, &out.OnlyDeleted, s); err != nil {
+							return err
+						}
+					}
+				case clusterpedia.SearchLabelRequireSynced:
+					if !in.RequireSynced && len(values) != 0 {
+						if err := runtime.Convert_Slice_string_To_bool(&values, &out.RequireSynced, s); err != nil {
+							return err
+						}
+					}
+				case clusterpedia.SearchLabelWithRelated:
+					if len(out.RelatedResources) == 0 && len(values) != 0 {
+						convert_Slice_string_To_Slice_schema_GroupResource(require.Values().List(), &out.RelatedResources)
+					}
 				case clusterpedia.SearchLabelWithContinue:
 					if in.WithContinue == nil && len(values) != 0 {
 						if err := runtime.Convert_Slice_string_To_Pointer_bool(&values, &out.WithContinue, s); err != nil {
@@ -201,10 +266,15 @@ func Convert_clusterpedia_ListOptions_To_v1beta1_ListOptions(in *clusterpedia.Li
 		return err
 	}
 
-	labels := in.LabelSelector.DeepCopySelector()
-	requirements, _ := in.ExtraLabelSelector.Requirements()
-	labels.Add(requirements...)
-	if err := metav1.Convert_labels_Selector_To_string(&labels, &out.ListOptions.LabelSelector, s); err != nil {
+	selector := labels.Everything()
+	if in.LabelSelector != nil {
+		selector = in.LabelSelector.DeepCopySelector()
+	}
+	if in.ExtraLabelSelector != nil {
+		requirements, _ := in.ExtraLabelSelector.Requirements()
+		selector = selector.Add(requirements...)
+	}
+	if err := metav1.Convert_labels_Selector_To_string(&selector, &out.ListOptions.LabelSelector, s); err != nil {
 		return err
 	}
 
@@ -212,6 +282,10 @@ func Convert_clusterpedia_ListOptions_To_v1beta1_ListOptions(in *clusterpedia.Li
 	out.OwnerName = in.OwnerName
 	out.OwnerGroupResource = in.OwnerGroupResource.String()
 	out.OwnerSeniority = in.OwnerSeniority
+	out.RootOwner = in.RootOwnerName
+	if in.RootOwnerKind != "" && in.RootOwnerName != "" {
+		out.RootOwner = in.RootOwnerKind + "." + in.RootOwnerName
+	}
 
 	if err := convert_Slice_string_To_String(&in.Names, &out.Names, s); err != nil {
 		return err
@@ -226,8 +300,32 @@ func Convert_clusterpedia_ListOptions_To_v1beta1_ListOptions(in *clusterpedia.Li
 		return err
 	}
 
+	if err := convert_Slice_string_To_String(&in.GroupBy, &out.GroupBy, s); err != nil {
+		return err
+	}
+	out.Query = in.Query
+	if err := convert_clusterpedia_Slice_TableColumn_To_String(&in.Columns, &out.Columns, s); err != nil {
+		return err
+	}
+
+	related := make([]string, 0, len(in.RelatedResources))
+	for _, gr := range in.RelatedResources {
+		related = append(related, gr.String())
+	}
+	if err := convert_Slice_string_To_String(&related, &out.WithRelated, s); err != nil {
+		return err
+	}
+
+	convert_Pointer_metav1_Time_To_String(&in.Since, &out.Since)
+	convert_Pointer_metav1_Time_To_String(&in.Before, &out.Before)
+	convert_Pointer_metav1_Time_To_String(&in.AsOf, &out.AsOf)
+
 	out.WithContinue = in.WithContinue
 	out.WithRemainingCount = in.WithRemainingCount
+	out.OnlyMetadata = in.OnlyMetadata
+	out.IncludeDeleted = in.IncludeDeleted
+	out.OnlyDeleted = in.OnlyDeleted
+	out.RequireSynced = in.RequireSynced
 	return nil
 }
 
@@ -261,6 +359,17 @@ func convert_Slice_string_To_String(in *[]string, out *string, scope conversion.
 	return nil
 }
 
+// convert_String_To_root_owner parses the root owner in the format of `[<kind>.]<name>`,
+// the names of resources are lowercase, so the prefix starting with an uppercase letter is the kind.
+func convert_String_To_root_owner(in string, kind, name *string) {
+	in = strings.TrimSpace(in)
+	if sli := strings.SplitN(in, ".", 2); len(sli) == 2 && sli[0] != "" && unicode.IsUpper(rune(sli[0][0])) {
+		*kind, *name = sli[0], sli[1]
+		return
+	}
+	*name = in
+}
+
 func convert_String_To_Pointer_metav1_Time(in *string, out **metav1.Time, scope conversion.Scope) error {
 	str := strings.TrimSpace(*in)
 	if len(str) == 0 {
@@ -301,6 +410,16 @@ func convert_String_To_Pointer_metav1_Time(in *string, out **metav1.Time, scope
 	return nil
 }
 
+// convert_Pointer_metav1_Time_To_String keeps the fractional seconds,
+// the times may be more precise than seconds.
+func convert_Pointer_metav1_Time_To_String(in **metav1.Time, out *string) {
+	if *in == nil {
+		*out = ""
+		return
+	}
+	*out = (*in).UTC().Format(time.RFC3339Nano)
+}
+
 func convert_Slice_string_To_clusterpedia_Slice_orderby(in *[]string, out *[]clusterpedia.OrderBy, descSep string, s conversion.Scope) error {
 	if len(*in) == 0 {
 		return nil
@@ -355,6 +474,39 @@ func convert_pedia_Slice_orderby_To_String(in *[]clusterpedia.OrderBy, out *stri
 	return nil
 }
 
+// convert_String_To_clusterpedia_Slice_TableColumn parses the custom columns in the format of `NAME:JSONPATH,...`
+func convert_String_To_clusterpedia_Slice_TableColumn(in *string, out *[]clusterpedia.TableColumn, s conversion.Scope) error {
+	var columns []string
+	if err := convert_String_To_Slice_string(in, &columns, s); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		sli := strings.SplitN(column, ":", 2)
+		if len(sli) != 2 || strings.TrimSpace(sli[0]) == "" || strings.TrimSpace(sli[1]) == "" {
+			return fmt.Errorf("Invalid Query Columns: %q, the column must be in the format of NAME:JSONPATH", column)
+		}
+		*out = append(*out, clusterpedia.TableColumn{Name: strings.TrimSpace(sli[0]), JSONPath: strings.TrimSpace(sli[1])})
+	}
+	return nil
+}
+
+func convert_clusterpedia_Slice_TableColumn_To_String(in *[]clusterpedia.TableColumn, out *string, s conversion.Scope) error {
+	columns := make([]string, 0, len(*in))
+	for _, column := range *in {
+		columns = append(columns, column.Name+":"+column.JSONPath)
+	}
+	return convert_Slice_string_To_String(&columns, out, s)
+}
+
+func convert_Slice_string_To_Slice_schema_GroupResource(in []string, out *[]schema.GroupResource) {
+	for _, resource := range in {
+		if resource = strings.TrimSpace(resource); resource != "" {
+			*out = append(*out, schema.ParseGroupResource(resource))
+		}
+	}
+}
+
 func convert_string_To_fields_Selector(in *string, out *fields.Selector, s conversion.Scope) error {
 	selector, err := fields.Parse(*in)
 	if err != nil {
@@ -24,6 +24,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&CollectionResource{},
 		&CollectionResourceList{},
+		&ResourceCounts{},
+		&ResourceRevisions{},
 		&Resources{},
 		&ListOptions{},
 
@@ -37,12 +37,28 @@ type ListOptions struct {
 	// +optional
 	Before string `json:"before,omitempty"`
 
+	// +optional
+	AsOf string `json:"asOf,omitempty"`
+
+	// +optional
+	IncludeDeleted bool `json:"includeDeleted,omitempty"`
+
+	// +optional
+	OnlyDeleted bool `json:"onlyDeleted,omitempty"`
+
+	// +optional
+	RequireSynced bool `json:"requireSynced,omitempty"`
+
 	// +optional
 	OwnerGroupResource string `json:"ownerGR,omitempty"`
 
 	// +optional
 	OwnerSeniority int `json:"ownerSeniority,omitempty"`
 
+	// RootOwner is the ultimate controller owner in the format of `[<kind>.]<name>`, such as `Deployment.frontend`
+	// +optional
+	RootOwner string `json:"rootOwner,omitempty"`
+
 	// +optional
 	WithContinue *bool `json:"withContinue,omitempty"`
 
@@ -52,6 +68,20 @@ type ListOptions struct {
 	// +optional
 	OnlyMetadata bool `json:"onlyMetadata,omitempty"`
 
+	// +optional
+	GroupBy string `json:"groupBy,omitempty"`
+
+	// +optional
+	Query string `json:"query,omitempty"`
+
+	// Columns are the custom columns of the Table, such as `NAME:.metadata.name,PHASE:.status.phase`
+	// +optional
+	Columns string `json:"columns,omitempty"`
+
+	// WithRelated are the related resources returned together with each resource, such as `events,pods`
+	// +optional
+	WithRelated string `json:"withRelated,omitempty"`
+
 	urlQuery url.Values
 }
 
@@ -105,3 +135,56 @@ type CollectionResourceList struct {
 
 	Items []CollectionResource `json:"items"`
 }
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ResourceCounts struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	GroupBy []string `json:"groupBy"`
+
+	// +optional
+	Items []ResourceCount `json:"items,omitempty"`
+}
+
+type ResourceCount struct {
+	// Values are the values of the group by fields,
+	// the field is omitted if the resource does not have it.
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+
+	Count int64 `json:"count"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ResourceRevisions struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Items []ResourceRevision `json:"items,omitempty"`
+}
+
+type ResourceRevision struct {
+	// Revision is the sequence number of the recorded version,
+	// it increases with the changes of the resource.
+	Revision int64 `json:"revision"`
+
+	ResourceVersion string `json:"resourceVersion"`
+
+	// ValidFrom is the time when the version was recorded.
+	ValidFrom metav1.Time `json:"validFrom"`
+
+	// ValidTo is the time when the version was replaced or deleted,
+	// it is empty for the current version.
+	// +optional
+	ValidTo *metav1.Time `json:"validTo,omitempty"`
+
+	Object runtime.RawExtension `json:"object"`
+}
@@ -10,6 +10,7 @@ import (
 	unsafe "unsafe"
 
 	clusterpedia "github.com/clusterpedia-io/api/clusterpedia"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -51,6 +52,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ResourceCount)(nil), (*clusterpedia.ResourceCount)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ResourceCount_To_clusterpedia_ResourceCount(a.(*ResourceCount), b.(*clusterpedia.ResourceCount), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*clusterpedia.ResourceCount)(nil), (*ResourceCount)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_clusterpedia_ResourceCount_To_v1beta1_ResourceCount(a.(*clusterpedia.ResourceCount), b.(*ResourceCount), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ResourceCounts)(nil), (*clusterpedia.ResourceCounts)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ResourceCounts_To_clusterpedia_ResourceCounts(a.(*ResourceCounts), b.(*clusterpedia.ResourceCounts), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*clusterpedia.ResourceCounts)(nil), (*ResourceCounts)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_clusterpedia_ResourceCounts_To_v1beta1_ResourceCounts(a.(*clusterpedia.ResourceCounts), b.(*ResourceCounts), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ResourceRevision)(nil), (*clusterpedia.ResourceRevision)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ResourceRevision_To_clusterpedia_ResourceRevision(a.(*ResourceRevision), b.(*clusterpedia.ResourceRevision), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*clusterpedia.ResourceRevision)(nil), (*ResourceRevision)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_clusterpedia_ResourceRevision_To_v1beta1_ResourceRevision(a.(*clusterpedia.ResourceRevision), b.(*ResourceRevision), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ResourceRevisions)(nil), (*clusterpedia.ResourceRevisions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ResourceRevisions_To_clusterpedia_ResourceRevisions(a.(*ResourceRevisions), b.(*clusterpedia.ResourceRevisions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*clusterpedia.ResourceRevisions)(nil), (*ResourceRevisions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_clusterpedia_ResourceRevisions_To_v1beta1_ResourceRevisions(a.(*clusterpedia.ResourceRevisions), b.(*ResourceRevisions), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*url.Values)(nil), (*ListOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_url_Values_To_v1beta1_ListOptions(a.(*url.Values), b.(*ListOptions), scope)
 	}); err != nil {
@@ -201,11 +242,20 @@ func autoConvert_v1beta1_ListOptions_To_clusterpedia_ListOptions(in *ListOptions
 	out.OwnerName = in.OwnerName
 	// WARNING: in.Since requires manual conversion: inconvertible types (string vs *k8s.io/apimachinery/pkg/apis/meta/v1.Time)
 	// WARNING: in.Before requires manual conversion: inconvertible types (string vs *k8s.io/apimachinery/pkg/apis/meta/v1.Time)
+	// WARNING: in.AsOf requires manual conversion: inconvertible types (string vs *k8s.io/apimachinery/pkg/apis/meta/v1.Time)
+	out.IncludeDeleted = in.IncludeDeleted
+	out.OnlyDeleted = in.OnlyDeleted
+	out.RequireSynced = in.RequireSynced
 	// WARNING: in.OwnerGroupResource requires manual conversion: inconvertible types (string vs k8s.io/apimachinery/pkg/runtime/schema.GroupResource)
 	out.OwnerSeniority = in.OwnerSeniority
+	// WARNING: in.RootOwner requires manual conversion: does not exist in peer-type
 	out.WithContinue = (*bool)(unsafe.Pointer(in.WithContinue))
 	out.WithRemainingCount = (*bool)(unsafe.Pointer(in.WithRemainingCount))
 	out.OnlyMetadata = in.OnlyMetadata
+	// WARNING: in.GroupBy requires manual conversion: inconvertible types (string vs []string)
+	out.Query = in.Query
+	// WARNING: in.Columns requires manual conversion: inconvertible types (string vs []github.com/clusterpedia-io/api/clusterpedia.TableColumn)
+	// WARNING: in.WithRelated requires manual conversion: does not exist in peer-type
 	// WARNING: in.urlQuery requires manual conversion: does not exist in peer-type
 	return nil
 }
@@ -227,13 +277,25 @@ func autoConvert_clusterpedia_ListOptions_To_v1beta1_ListOptions(in *clusterpedi
 	out.OwnerUID = in.OwnerUID
 	// WARNING: in.OwnerGroupResource requires manual conversion: inconvertible types (k8s.io/apimachinery/pkg/runtime/schema.GroupResource vs string)
 	out.OwnerSeniority = in.OwnerSeniority
+	// WARNING: in.RootOwnerKind requires manual conversion: does not exist in peer-type
+	// WARNING: in.RootOwnerName requires manual conversion: does not exist in peer-type
 	// WARNING: in.Since requires manual conversion: inconvertible types (*k8s.io/apimachinery/pkg/apis/meta/v1.Time vs string)
 	// WARNING: in.Before requires manual conversion: inconvertible types (*k8s.io/apimachinery/pkg/apis/meta/v1.Time vs string)
+	// WARNING: in.AsOf requires manual conversion: inconvertible types (*k8s.io/apimachinery/pkg/apis/meta/v1.Time vs string)
+	out.IncludeDeleted = in.IncludeDeleted
+	out.OnlyDeleted = in.OnlyDeleted
+	out.RequireSynced = in.RequireSynced
 	out.WithContinue = (*bool)(unsafe.Pointer(in.WithContinue))
 	out.WithRemainingCount = (*bool)(unsafe.Pointer(in.WithRemainingCount))
+	if err := runtime.Convert_Slice_string_To_string(&in.GroupBy, &out.GroupBy, s); err != nil {
+		return err
+	}
+	out.Query = in.Query
+	// WARNING: in.Columns requires manual conversion: inconvertible types ([]github.com/clusterpedia-io/api/clusterpedia.TableColumn vs string)
 	// WARNING: in.EnhancedFieldSelector requires manual conversion: does not exist in peer-type
 	// WARNING: in.ExtraLabelSelector requires manual conversion: does not exist in peer-type
 	// WARNING: in.URLQuery requires manual conversion: does not exist in peer-type
+	// WARNING: in.RelatedResources requires manual conversion: does not exist in peer-type
 	out.OnlyMetadata = in.OnlyMetadata
 	return nil
 }
@@ -297,6 +359,34 @@ func autoConvert_url_Values_To_v1beta1_ListOptions(in *url.Values, out *ListOpti
 	} else {
 		out.Before = ""
 	}
+	if values, ok := map[string][]string(*in)["asOf"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.AsOf, s); err != nil {
+			return err
+		}
+	} else {
+		out.AsOf = ""
+	}
+	if values, ok := map[string][]string(*in)["includeDeleted"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_bool(&values, &out.IncludeDeleted, s); err != nil {
+			return err
+		}
+	} else {
+		out.IncludeDeleted = false
+	}
+	if values, ok := map[string][]string(*in)["onlyDeleted"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_bool(&values, &out.OnlyDeleted, s); err != nil {
+			return err
+		}
+	} else {
+		out.OnlyDeleted = false
+	}
+	if values, ok := map[string][]string(*in)["requireSynced"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_bool(&values, &out.RequireSynced, s); err != nil {
+			return err
+		}
+	} else {
+		out.RequireSynced = false
+	}
 	if values, ok := map[string][]string(*in)["ownerGR"]; ok && len(values) > 0 {
 		if err := runtime.Convert_Slice_string_To_string(&values, &out.OwnerGroupResource, s); err != nil {
 			return err
@@ -311,6 +401,13 @@ func autoConvert_url_Values_To_v1beta1_ListOptions(in *url.Values, out *ListOpti
 	} else {
 		out.OwnerSeniority = 0
 	}
+	if values, ok := map[string][]string(*in)["rootOwner"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.RootOwner, s); err != nil {
+			return err
+		}
+	} else {
+		out.RootOwner = ""
+	}
 	if values, ok := map[string][]string(*in)["withContinue"]; ok && len(values) > 0 {
 		if err := runtime.Convert_Slice_string_To_Pointer_bool(&values, &out.WithContinue, s); err != nil {
 			return err
@@ -332,7 +429,155 @@ func autoConvert_url_Values_To_v1beta1_ListOptions(in *url.Values, out *ListOpti
 	} else {
 		out.OnlyMetadata = false
 	}
+	if values, ok := map[string][]string(*in)["groupBy"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.GroupBy, s); err != nil {
+			return err
+		}
+	} else {
+		out.GroupBy = ""
+	}
+	if values, ok := map[string][]string(*in)["query"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.Query, s); err != nil {
+			return err
+		}
+	} else {
+		out.Query = ""
+	}
+	if values, ok := map[string][]string(*in)["columns"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.Columns, s); err != nil {
+			return err
+		}
+	} else {
+		out.Columns = ""
+	}
+	if values, ok := map[string][]string(*in)["withRelated"]; ok && len(values) > 0 {
+		if err := runtime.Convert_Slice_string_To_string(&values, &out.WithRelated, s); err != nil {
+			return err
+		}
+	} else {
+		out.WithRelated = ""
+	}
 	// WARNING: Field urlQuery does not have json tag, skipping.
 
 	return nil
 }
+
+func autoConvert_v1beta1_ResourceCount_To_clusterpedia_ResourceCount(in *ResourceCount, out *clusterpedia.ResourceCount, s conversion.Scope) error {
+	out.Values = *(*map[string]string)(unsafe.Pointer(&in.Values))
+	out.Count = in.Count
+	return nil
+}
+
+// Convert_v1beta1_ResourceCount_To_clusterpedia_ResourceCount is an autogenerated conversion function.
+func Convert_v1beta1_ResourceCount_To_clusterpedia_ResourceCount(in *ResourceCount, out *clusterpedia.ResourceCount, s conversion.Scope) error {
+	return autoConvert_v1beta1_ResourceCount_To_clusterpedia_ResourceCount(in, out, s)
+}
+
+func autoConvert_clusterpedia_ResourceCount_To_v1beta1_ResourceCount(in *clusterpedia.ResourceCount, out *ResourceCount, s conversion.Scope) error {
+	out.Values = *(*map[string]string)(unsafe.Pointer(&in.Values))
+	out.Count = in.Count
+	return nil
+}
+
+// Convert_clusterpedia_ResourceCount_To_v1beta1_ResourceCount is an autogenerated conversion function.
+func Convert_clusterpedia_ResourceCount_To_v1beta1_ResourceCount(in *clusterpedia.ResourceCount, out *ResourceCount, s conversion.Scope) error {
+	return autoConvert_clusterpedia_ResourceCount_To_v1beta1_ResourceCount(in, out, s)
+}
+
+func autoConvert_v1beta1_ResourceCounts_To_clusterpedia_ResourceCounts(in *ResourceCounts, out *clusterpedia.ResourceCounts, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.GroupBy = *(*[]string)(unsafe.Pointer(&in.GroupBy))
+	out.Items = *(*[]clusterpedia.ResourceCount)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1beta1_ResourceCounts_To_clusterpedia_ResourceCounts is an autogenerated conversion function.
+func Convert_v1beta1_ResourceCounts_To_clusterpedia_ResourceCounts(in *ResourceCounts, out *clusterpedia.ResourceCounts, s conversion.Scope) error {
+	return autoConvert_v1beta1_ResourceCounts_To_clusterpedia_ResourceCounts(in, out, s)
+}
+
+func autoConvert_clusterpedia_ResourceCounts_To_v1beta1_ResourceCounts(in *clusterpedia.ResourceCounts, out *ResourceCounts, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	out.GroupBy = *(*[]string)(unsafe.Pointer(&in.GroupBy))
+	out.Items = *(*[]ResourceCount)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_clusterpedia_ResourceCounts_To_v1beta1_ResourceCounts is an autogenerated conversion function.
+func Convert_clusterpedia_ResourceCounts_To_v1beta1_ResourceCounts(in *clusterpedia.ResourceCounts, out *ResourceCounts, s conversion.Scope) error {
+	return autoConvert_clusterpedia_ResourceCounts_To_v1beta1_ResourceCounts(in, out, s)
+}
+
+func autoConvert_v1beta1_ResourceRevision_To_clusterpedia_ResourceRevision(in *ResourceRevision, out *clusterpedia.ResourceRevision, s conversion.Scope) error {
+	out.Revision = in.Revision
+	out.ResourceVersion = in.ResourceVersion
+	out.ValidFrom = in.ValidFrom
+	out.ValidTo = (*v1.Time)(unsafe.Pointer(in.ValidTo))
+	if err := runtime.Convert_runtime_RawExtension_To_runtime_Object(&in.Object, &out.Object, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_ResourceRevision_To_clusterpedia_ResourceRevision is an autogenerated conversion function.
+func Convert_v1beta1_ResourceRevision_To_clusterpedia_ResourceRevision(in *ResourceRevision, out *clusterpedia.ResourceRevision, s conversion.Scope) error {
+	return autoConvert_v1beta1_ResourceRevision_To_clusterpedia_ResourceRevision(in, out, s)
+}
+
+func autoConvert_clusterpedia_ResourceRevision_To_v1beta1_ResourceRevision(in *clusterpedia.ResourceRevision, out *ResourceRevision, s conversion.Scope) error {
+	out.Revision = in.Revision
+	out.ResourceVersion = in.ResourceVersion
+	out.ValidFrom = in.ValidFrom
+	out.ValidTo = (*v1.Time)(unsafe.Pointer(in.ValidTo))
+	if err := runtime.Convert_runtime_Object_To_runtime_RawExtension(&in.Object, &out.Object, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_clusterpedia_ResourceRevision_To_v1beta1_ResourceRevision is an autogenerated conversion function.
+func Convert_clusterpedia_ResourceRevision_To_v1beta1_ResourceRevision(in *clusterpedia.ResourceRevision, out *ResourceRevision, s conversion.Scope) error {
+	return autoConvert_clusterpedia_ResourceRevision_To_v1beta1_ResourceRevision(in, out, s)
+}
+
+func autoConvert_v1beta1_ResourceRevisions_To_clusterpedia_ResourceRevisions(in *ResourceRevisions, out *clusterpedia.ResourceRevisions, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]clusterpedia.ResourceRevision, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ResourceRevision_To_clusterpedia_ResourceRevision(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1beta1_ResourceRevisions_To_clusterpedia_ResourceRevisions is an autogenerated conversion function.
+func Convert_v1beta1_ResourceRevisions_To_clusterpedia_ResourceRevisions(in *ResourceRevisions, out *clusterpedia.ResourceRevisions, s conversion.Scope) error {
+	return autoConvert_v1beta1_ResourceRevisions_To_clusterpedia_ResourceRevisions(in, out, s)
+}
+
+func autoConvert_clusterpedia_ResourceRevisions_To_v1beta1_ResourceRevisions(in *clusterpedia.ResourceRevisions, out *ResourceRevisions, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceRevision, len(*in))
+		for i := range *in {
+			if err := Convert_clusterpedia_ResourceRevision_To_v1beta1_ResourceRevision(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_clusterpedia_ResourceRevisions_To_v1beta1_ResourceRevisions is an autogenerated conversion function.
+func Convert_clusterpedia_ResourceRevisions_To_v1beta1_ResourceRevisions(in *clusterpedia.ResourceRevisions, out *ResourceRevisions, s conversion.Scope) error {
+	return autoConvert_clusterpedia_ResourceRevisions_To_v1beta1_ResourceRevisions(in, out, s)
+}
@@ -9,6 +9,7 @@ import (
 	url "net/url"
 
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -138,6 +139,10 @@ func (in *ListOptions) DeepCopyInto(out *ListOptions) {
 		in, out := &in.Before, &out.Before
 		*out = (*in).DeepCopy()
 	}
+	if in.AsOf != nil {
+		in, out := &in.AsOf, &out.AsOf
+		*out = (*in).DeepCopy()
+	}
 	if in.WithContinue != nil {
 		in, out := &in.WithContinue, &out.WithContinue
 		*out = new(bool)
@@ -148,6 +153,16 @@ func (in *ListOptions) DeepCopyInto(out *ListOptions) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]TableColumn, len(*in))
+		copy(*out, *in)
+	}
 	if in.EnhancedFieldSelector != nil {
 		out.EnhancedFieldSelector = in.EnhancedFieldSelector.DeepCopySelector()
 	}
@@ -169,6 +184,11 @@ func (in *ListOptions) DeepCopyInto(out *ListOptions) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.RelatedResources != nil {
+		in, out := &in.RelatedResources, &out.RelatedResources
+		*out = make([]schema.GroupResource, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -205,3 +225,137 @@ func (in *OrderBy) DeepCopy() *OrderBy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceCount) DeepCopyInto(out *ResourceCount) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceCount.
+func (in *ResourceCount) DeepCopy() *ResourceCount {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceCounts) DeepCopyInto(out *ResourceCounts) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceCount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceCounts.
+func (in *ResourceCounts) DeepCopy() *ResourceCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceCounts) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRevision) DeepCopyInto(out *ResourceRevision) {
+	*out = *in
+	in.ValidFrom.DeepCopyInto(&out.ValidFrom)
+	if in.ValidTo != nil {
+		in, out := &in.ValidTo, &out.ValidTo
+		*out = (*in).DeepCopy()
+	}
+	if in.Object != nil {
+		out.Object = in.Object.DeepCopyObject()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRevision.
+func (in *ResourceRevision) DeepCopy() *ResourceRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRevisions) DeepCopyInto(out *ResourceRevisions) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRevisions.
+func (in *ResourceRevisions) DeepCopy() *ResourceRevisions {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRevisions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceRevisions) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TableColumn) DeepCopyInto(out *TableColumn) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TableColumn.
+func (in *TableColumn) DeepCopy() *TableColumn {
+	if in == nil {
+		return nil
+	}
+	out := new(TableColumn)
+	in.DeepCopyInto(out)
+	return out
+}